@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/necodeus/gator/internal/config"
+	"github.com/necodeus/gator/internal/database"
+)
+
+func handlerAdmin(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return usageErrorf("admin command requires a subcommand: users, grant, revoke, merge-user")
+	}
+
+	switch cmd.Args[0] {
+	case "users":
+		return adminUsers(ctx, s)
+	case "grant":
+		return adminSetAdmin(ctx, s, cmd.Args[1:], true)
+	case "revoke":
+		return adminSetAdmin(ctx, s, cmd.Args[1:], false)
+	case "merge-user":
+		return adminMergeUser(ctx, s, cmd.Args[1:])
+	default:
+		return usageErrorf("unknown admin subcommand: %s", cmd.Args[0])
+	}
+}
+
+// requireAdmin fails unless the current user is an admin. Until some user
+// is an admin, e.g. on a fresh database, anyone may act as one.
+func requireAdmin(ctx context.Context, s *state) error {
+	admins, err := s.db.CountAdmins(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count admins: %v", err)
+	}
+	if admins == 0 {
+		return nil
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+	if !user.IsAdmin {
+		return permissionErrorf("%s is not an admin", user.Name)
+	}
+	return nil
+}
+
+// adminUsers lists every user with what they follow, read and own, and
+// when they were last active.
+func adminUsers(ctx context.Context, s *state) error {
+	if err := requireAdmin(ctx, s); err != nil {
+		return err
+	}
+
+	users, err := s.db.GetUserStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get users: %v", err)
+	}
+	if len(users) == 0 {
+		fmt.Println("No users")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USER\tFEEDS\tFOLLOWS\tPOSTS\tREAD\tLAST ACTIVE")
+	for _, user := range users {
+		name := user.Name
+		if user.IsAdmin {
+			name += " (admin)"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\n", name, user.FeedCount, user.FollowCount,
+			user.PostCount, user.ReadCount, user.LastActiveAt.Local().Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+func adminSetAdmin(ctx context.Context, s *state, args []string, admin bool) error {
+	if len(args) != 1 {
+		return usageErrorf("admin grant and revoke require a username")
+	}
+	if err := requireAdmin(ctx, s); err != nil {
+		return err
+	}
+
+	return s.withTx(ctx, func(tx *state) error {
+		users, err := tx.db.GetUsersByName(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get user: %v", err)
+		}
+		if len(users) == 0 {
+			return notFoundErrorf("user %s does not exist", args[0])
+		}
+		user := users[0]
+
+		if user.IsAdmin && !admin {
+			admins, err := tx.db.CountAdmins(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to count admins: %v", err)
+			}
+			if admins == 1 {
+				return fmt.Errorf("%s is the only admin", user.Name)
+			}
+		}
+
+		if err := tx.db.SetUserAdmin(ctx, database.SetUserAdminParams{
+			ID:      user.ID,
+			IsAdmin: admin,
+		}); err != nil {
+			return fmt.Errorf("failed to update user: %v", err)
+		}
+
+		if admin {
+			infof("%s is now an admin\n", user.Name)
+		} else {
+			infof("%s is no longer an admin\n", user.Name)
+		}
+		return nil
+	})
+}
+
+// adminMergeUser folds one account into another, for someone who ended
+// up registered twice. The feeds the first user added, what they follow,
+// have read and starred, and their tags, aliases, saved searches and
+// folders move to the second user, who keeps their own wherever both
+// have one, e.g. a feed they both follow. The first user is then
+// removed, with whatever didn't move.
+func adminMergeUser(ctx context.Context, s *state, args []string) error {
+	if len(args) != 2 {
+		return usageErrorf("admin merge-user requires the user to merge and the user to merge into")
+	}
+	if err := requireAdmin(ctx, s); err != nil {
+		return err
+	}
+
+	var from, to database.User
+	moved := make(map[string]int64)
+	err := s.withTx(ctx, func(tx *state) error {
+		for i, user := range []*database.User{&from, &to} {
+			users, err := tx.db.GetUsersByName(ctx, args[i])
+			if err != nil {
+				return fmt.Errorf("failed to get user: %v", err)
+			}
+			if len(users) == 0 {
+				return notFoundErrorf("user %s does not exist", args[i])
+			}
+			*user = users[0]
+		}
+		if from.ID == to.ID {
+			return usageErrorf("can't merge %s into itself", from.Name)
+		}
+
+		// the feeds change hands like feed chown's do, so the trail shows it
+		if err := tx.db.RecordUserFeedsOwnerChange(ctx, database.RecordUserFeedsOwnerChangeParams{
+			FromUser:   from.Name,
+			ToUser:     to.Name,
+			ChangedBy:  s.Config.CurrentUserName,
+			FromUserID: from.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to record owner changes: %v", err)
+		}
+
+		move := database.MoveUserFeedsParams{ToUserID: to.ID, FromUserID: from.ID}
+		for _, step := range []struct {
+			what string
+			run  func() (int64, error)
+		}{
+			{"feeds", func() (int64, error) { return tx.db.MoveUserFeeds(ctx, move) }},
+			{"follows", func() (int64, error) { return tx.db.MoveUserFollows(ctx, database.MoveUserFollowsParams(move)) }},
+			{"reads", func() (int64, error) { return tx.db.MoveUserReads(ctx, database.MoveUserReadsParams(move)) }},
+			{"stars", func() (int64, error) { return tx.db.MoveUserStars(ctx, database.MoveUserStarsParams(move)) }},
+			{"notes", func() (int64, error) { return tx.db.MoveUserNotes(ctx, database.MoveUserNotesParams(move)) }},
+			{"tags", func() (int64, error) { return tx.db.MoveUserFeedTags(ctx, database.MoveUserFeedTagsParams(move)) }},
+			{"aliases", func() (int64, error) { return tx.db.MoveUserFeedAliases(ctx, database.MoveUserFeedAliasesParams(move)) }},
+			{"saved searches", func() (int64, error) {
+				return tx.db.MoveUserSavedSearches(ctx, database.MoveUserSavedSearchesParams(move))
+			}},
+			{"folders", func() (int64, error) { return tx.db.MoveUserFolders(ctx, database.MoveUserFoldersParams(move)) }},
+		} {
+			n, err := step.run()
+			if err != nil {
+				return fmt.Errorf("failed to move %s: %v", step.what, err)
+			}
+			moved[step.what] = n
+		}
+
+		// an admin merged away leaves an admin behind
+		if from.IsAdmin && !to.IsAdmin {
+			if err := tx.db.SetUserAdmin(ctx, database.SetUserAdminParams{ID: to.ID, IsAdmin: true}); err != nil {
+				return fmt.Errorf("failed to update user: %v", err)
+			}
+		}
+		if _, err := tx.db.DeleteUser(ctx, from.ID); err != nil {
+			return fmt.Errorf("failed to remove user: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	infof("Merged %s into %s: moved %d feeds, %d follows, %d reads and %d stars\n",
+		from.Name, to.Name, moved["feeds"], moved["follows"], moved["reads"], moved["stars"])
+
+	if strings.EqualFold(s.Config.CurrentUserName, from.Name) {
+		s.Config.CurrentUserName = to.Name
+		if err := config.Write(*s.Config); err != nil {
+			return fmt.Errorf("failed to write config: %v", err)
+		}
+		infof("Logged in as %s\n", to.Name)
+	}
+	return nil
+}
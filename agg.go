@@ -0,0 +1,1053 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type aggOptions struct {
+	// dryRun fetches and parses feeds but writes nothing to the database.
+	dryRun bool
+	// keepRaw stores every fetched body in the feed cache.
+	keepRaw bool
+	// fromCache parses the latest cached body instead of fetching.
+	fromCache bool
+	// progress shows a status line while the pass runs.
+	progress bool
+	// feeds, when set, limits passes to the feeds in it.
+	feeds map[uuid.UUID]bool
+	// quiet, when set, makes the scheduler skip fetching during quiet
+	// hours.
+	quiet *quietHours
+	// backfill stores every item a feed has, lifting max_feed_items and
+	// items_per_fetch.
+	backfill bool
+
+	cache feedCache
+}
+
+func handlerAgg(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("agg", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "fetch and parse feeds without storing anything")
+	keepRaw := fs.Bool("keep-raw", false, "keep fetched feed bodies in the cache")
+	fromCache := fs.Bool("from-cache", false, "re-aggregate from cached feed bodies instead of fetching")
+	every := fs.Duration("every", 0, "keep running, checking which feeds are due this often")
+	var feedRefs, tags stringList
+	fs.Var(&feedRefs, "feed", "only fetch this feed, by name or URL (repeatable)")
+	fs.Var(&tags, "tag", "only fetch feeds with this tag (repeatable)")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+
+	cacheDir, err := s.Config.CacheDirPath()
+	if err != nil {
+		return fmt.Errorf("failed to locate cache: %v", err)
+	}
+
+	opts := aggOptions{
+		dryRun:    *dryRun,
+		keepRaw:   *keepRaw,
+		fromCache: *fromCache,
+		cache:     feedCache{dir: cacheDir},
+	}
+
+	if len(feedRefs) > 0 || len(tags) > 0 {
+		if opts.feeds, err = scopedFeeds(ctx, s, feedRefs, tags); err != nil {
+			return err
+		}
+	}
+
+	if *every <= 0 {
+		opts.progress = true
+		return aggregateFeeds(ctx, s, opts, nil)
+	}
+
+	return runScheduler(ctx, s, opts, *every, nil)
+}
+
+// stringList is a flag that can be given more than once.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// scopedFeeds resolves agg's --feed and --tag flags to the set of feeds
+// they name. Tags are the current user's.
+func scopedFeeds(ctx context.Context, s *state, refs, tags []string) (map[uuid.UUID]bool, error) {
+	scope := make(map[uuid.UUID]bool)
+	for _, ref := range refs {
+		feed, err := findFeed(ctx, s, ref)
+		if err == sql.ErrNoRows {
+			return nil, notFoundErrorf("feed %s does not exist", ref)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get feed: %v", err)
+		}
+		scope[feed.ID] = true
+	}
+
+	if len(tags) == 0 {
+		return scope, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	tagged, err := s.db.GetFeedTagsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %v", err)
+	}
+	for _, tag := range tags {
+		found := false
+		for _, t := range tagged {
+			if strings.EqualFold(t.Tag, tag) {
+				scope[t.FeedID] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, notFoundErrorf("no feeds are tagged %s", tag)
+		}
+	}
+	return scope, nil
+}
+
+// runScheduler aggregates every interval until ctx is cancelled, fetching
+// only the feeds feedDue picks, and nothing during quiet hours. Given
+// reload, a SIGHUP swaps s, opts and interval for what it returns, and a
+// pass runs straight after with them.
+func runScheduler(ctx context.Context, s *state, opts aggOptions, interval time.Duration, reload func(context.Context, *state, aggOptions) (*state, aggOptions, time.Duration, error)) error {
+	infof("Checking feeds every %s\n", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// a nil channel never fires, so without reload SIGHUP does what it
+	// always did
+	var hup chan os.Signal
+	if reload != nil {
+		hup = make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+	}
+
+	quiet := false
+	for {
+		now := time.Now()
+		if opts.quiet.pausesFetching(now) != quiet {
+			quiet = !quiet
+			if quiet {
+				fmt.Println("Quiet hours started, not fetching")
+			} else {
+				fmt.Println("Quiet hours over, fetching again")
+			}
+		}
+
+		// feeds that fell due meanwhile are fetched on the first tick after
+		var err error
+		if !quiet {
+			due := func(feed database.Feed) bool { return feedDue(feed, now, interval) }
+			if err = aggregateFeeds(ctx, s, opts, due); err != nil {
+				fmt.Printf("Error aggregating feeds: %v\n", err)
+			}
+		}
+		// a pass another process holds the lock for still shows this loop
+		// is alive
+		if err == nil || errors.Is(err, errAggLocked) {
+			s.scheduler.passed(time.Now())
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-hup:
+			next, nextOpts, nextInterval, err := reload(ctx, s, opts)
+			if err != nil {
+				fmt.Printf("Error reloading config, keeping the old one: %v\n", err)
+				break
+			}
+			s, opts = next, nextOpts
+			fmt.Println("Reloaded config")
+			if nextInterval != interval {
+				interval = nextInterval
+				ticker.Reset(interval)
+				s.scheduler.setInterval(interval)
+				infof("Checking feeds every %s\n", interval)
+			}
+		}
+	}
+}
+
+// feedDue reports whether feed should be fetched at now, by a scheduler
+// checking every tick. A cron schedule set by the user fires once per slot;
+// otherwise the publisher's hints decide, and feeds without any are due
+// every tick. The feed's tier then stretches or shrinks that wait: high
+// feeds wait half as long, low ones four times as long.
+func feedDue(feed database.Feed, now time.Time, tick time.Duration) bool {
+	if !feed.LastFetchedAt.Valid {
+		return true
+	}
+
+	if feed.Schedule.Valid {
+		schedule, err := parseCron(feed.Schedule.String)
+		if err == nil {
+			next := schedule.Next(feed.LastFetchedAt.Time.In(time.Local))
+			return !next.IsZero() && !next.After(now)
+		}
+		fmt.Printf("Ignoring schedule of %s: %v\n", feed.Name, err)
+	}
+
+	hints := storedFetchHints(feed)
+	if hints.skipped(now) {
+		return false
+	}
+	wait := hints.interval
+	switch feed.Tier {
+	case tierHigh:
+		wait /= 2
+	case tierLow:
+		// last_fetched_at lands a little after the tick that fetched it,
+		// so count ticks rather than exact durations
+		wait = 4*max(wait, tick) - tick/2
+	}
+	return now.Sub(feed.LastFetchedAt.Time) >= wait
+}
+
+// aggregateFeeds runs one pass over the stored feeds, skipping any that
+// due rejects or opts.feeds leaves out, and paused ones. A nil due
+// fetches them all. Only one pass that writes can run at a time, across
+// processes.
+func aggregateFeeds(ctx context.Context, s *state, opts aggOptions, due func(database.Feed) bool) error {
+	ctx, span := tracer.Start(ctx, "agg.pass")
+	defer span.End()
+
+	feedsCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	if !opts.dryRun {
+		release, err := lockAggregation(feedsCtx, s)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	feeds, err := s.db.GetFeeds(feedsCtx)
+	if err != nil {
+		return fmt.Errorf("failed to get feeds: %v", err)
+	}
+
+	var dueFeeds []database.Feed
+	for _, feed := range feeds {
+		if isNewsletterFeed(feed) || (due != nil && !due(feed)) {
+			continue
+		}
+		// paused feeds are only fetched when named with --feed or --tag
+		if opts.feeds != nil && !opts.feeds[feed.ID] || opts.feeds == nil && feed.PausedAt.Valid {
+			continue
+		}
+		dueFeeds = append(dueFeeds, feed)
+	}
+	span.SetAttributes(attribute.Int("gator.feeds", len(dueFeeds)))
+
+	var progress *aggProgress
+	if opts.progress {
+		progress = startAggProgress(len(dueFeeds))
+	}
+	defer progress.stop()
+
+	// up to fetch_workers feeds are fetched at once; the host limiter
+	// still spaces out requests to any one site
+	var (
+		wg             sync.WaitGroup
+		mu             sync.Mutex
+		total, fetched int
+	)
+	jobs := make(chan database.Feed)
+	for range min(s.Config.FetchWorkersOrDefault(), len(dueFeeds)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for feed := range jobs {
+				if !opts.fromCache {
+					if err := s.hosts.Wait(ctx, feed.Url); err != nil {
+						continue
+					}
+				}
+				progress.fetching(feed.Name)
+
+				newPosts, err := aggregateFeed(ctx, s, feed, opts)
+				if !opts.dryRun {
+					markFetched(ctx, s, feed)
+				}
+				progress.finished(newPosts, err)
+				if err != nil {
+					fmt.Printf("Error fetching %s: %v\n", feed.Name, err)
+					if !opts.dryRun {
+						hookFeedError(ctx, s, feed, err)
+					}
+					continue
+				}
+				mu.Lock()
+				total += newPosts
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for _, feed := range dueFeeds {
+		select {
+		case jobs <- feed:
+			fetched++
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	span.SetAttributes(attribute.Int("gator.new_posts", total))
+
+	if opts.dryRun {
+		fmt.Printf("Dry run: %d new posts across %d feeds would be saved, nothing was written\n", total, fetched)
+		return nil
+	}
+
+	pruneFetchLog(ctx, s)
+
+	if s.Config.IMAPAddress() != "" && !opts.fromCache {
+		newPosts, err := pollNewsletters(ctx, s)
+		if err != nil {
+			fmt.Printf("Error reading newsletters: %v\n", err)
+		} else if newPosts > 0 {
+			infof("%d new newsletter posts\n", newPosts)
+		}
+	}
+
+	return nil
+}
+
+// markFetched records that feed was just fetched, successfully or not, so
+// the scheduler waits for its next slot instead of retrying every tick.
+func markFetched(ctx context.Context, s *state, feed database.Feed) {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	err := s.db.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{
+		ID:            feed.ID,
+		LastFetchedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		fmt.Printf("Error updating %s: %v\n", feed.Name, err)
+	}
+}
+
+// aggregateFeed fetches one feed and stores its new items, returning how
+// many posts were (or, in a dry run, would have been) added.
+func aggregateFeed(ctx context.Context, s *state, feed database.Feed, opts aggOptions) (int, error) {
+	ctx, span := tracer.Start(ctx, "feed.aggregate", trace.WithAttributes(
+		attribute.String("gator.feed.name", feed.Name),
+		attribute.String("url.full", feed.Url),
+	))
+	defer span.End()
+
+	start := time.Now()
+	rss, err := loadFeed(ctx, s, feed, opts)
+	elapsed := time.Since(start)
+	if errors.Is(err, errFeedUnchanged) {
+		infof("%s is unchanged\n", feed.Name)
+		span.SetAttributes(attribute.Bool("gator.feed.unchanged", true))
+		if !opts.dryRun {
+			recordFetch(ctx, s, feed, opts, elapsed, rss, 0, nil)
+		}
+		return 0, nil
+	}
+	if err != nil {
+		if !opts.dryRun {
+			recordFetch(ctx, s, feed, opts, elapsed, nil, 0, err)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	// storing one feed's worth of posts counts as a single operation
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	if !opts.backfill {
+		if items := newestItems(rss.Channel.Item, s.itemsPerFetch); len(items) < len(rss.Channel.Item) {
+			infof("Keeping the newest %d of %d items of %s\n", len(items), len(rss.Channel.Item), feed.Name)
+			rss.Channel.Item = items
+		}
+	}
+
+	if opts.dryRun {
+		return previewNewPosts(ctx, s, feed, rss)
+	}
+
+	if rss.PermanentURL != "" && rss.PermanentURL != feed.Url {
+		if err := moveFeed(ctx, s, feed, rss.PermanentURL); err != nil {
+			fmt.Printf("Error updating URL of %s: %v\n", feed.Name, err)
+		}
+	}
+
+	if err := saveFeedMetadata(ctx, s, feed, rss); err != nil {
+		fmt.Printf("Error updating %s: %v\n", feed.Name, err)
+	}
+	if err := saveFetchHints(ctx, s, feed, rss); err != nil {
+		fmt.Printf("Error updating %s: %v\n", feed.Name, err)
+	}
+	if err := saveWebSubLinks(ctx, s, feed, rss); err != nil {
+		fmt.Printf("Error updating %s: %v\n", feed.Name, err)
+	}
+
+	for _, item := range rss.Channel.Item {
+		infof("- %s\n", item.Title)
+	}
+
+	saveCtx, saveSpan := tracer.Start(ctx, "feed.save", trace.WithAttributes(attribute.Int("gator.items", len(rss.Channel.Item))))
+	newPosts := savePosts(saveCtx, s, feed, rss.Channel.Item)
+	saveSpan.SetAttributes(attribute.Int("gator.new_posts", newPosts))
+	saveSpan.End()
+	recordFetch(ctx, s, feed, opts, elapsed, rss, newPosts, nil)
+
+	if rss.ContentHash != "" && rss.ContentHash != feed.ContentHash.String {
+		err := s.db.SetFeedContentHash(ctx, database.SetFeedContentHashParams{
+			ID:          feed.ID,
+			ContentHash: sql.NullString{String: rss.ContentHash, Valid: true},
+		})
+		if err != nil {
+			fmt.Printf("Error updating %s: %v\n", feed.Name, err)
+		}
+	}
+	return newPosts, nil
+}
+
+// newestItems returns the n most recently published items, in their
+// original order; items without a date rank below every dated one, and
+// n <= 0 keeps them all.
+func newestItems(items []RSSItem, n int) []RSSItem {
+	if n <= 0 || len(items) <= n {
+		return items
+	}
+
+	type dated struct {
+		index     int
+		published time.Time
+	}
+	order := make([]dated, len(items))
+	for i, item := range items {
+		order[i].index = i
+		// an unparsable date stays zero, which sorts last
+		order[i].published, _ = parsePubDate(item.PubDate)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return order[a].published.After(order[b].published)
+	})
+
+	keep := make([]bool, len(items))
+	for _, d := range order[:n] {
+		keep[d.index] = true
+	}
+	newest := make([]RSSItem, 0, n)
+	for i, item := range items {
+		if keep[i] {
+			newest = append(newest, item)
+		}
+	}
+	return newest
+}
+
+// recordFetch adds an attempt at fetching feed to the fetch log, for
+// history to show.
+func recordFetch(ctx context.Context, s *state, feed database.Feed, opts aggOptions, elapsed time.Duration, rss *RSSFeed, newPosts int, fetchErr error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	params := database.RecordFetchParams{
+		FeedID:     feed.ID,
+		DurationMs: int32(elapsed.Milliseconds()),
+		NewPosts:   int32(newPosts),
+	}
+	// only fetches that got as far as a response have a status
+	var statusErr *badStatusError
+	switch {
+	case errors.As(fetchErr, &statusErr):
+		params.StatusCode = sql.NullInt32{Int32: int32(statusErr.code), Valid: true}
+	case fetchErr == nil && !opts.fromCache:
+		params.StatusCode = sql.NullInt32{Int32: http.StatusOK, Valid: true}
+	}
+	if rss != nil {
+		params.ItemsSeen = int32(len(rss.Channel.Item))
+		params.Bytes = sql.NullInt64{Int64: rss.Size, Valid: rss.Size > 0}
+	}
+	if fetchErr != nil {
+		params.Error = sql.NullString{String: fetchErr.Error(), Valid: true}
+	}
+
+	if err := s.db.RecordFetch(ctx, params); err != nil {
+		fmt.Printf("Error recording fetch of %s: %v\n", feed.Name, err)
+	}
+}
+
+// pruneFetchLog deletes fetch attempts older than fetch_log_retention.
+func pruneFetchLog(ctx context.Context, s *state) {
+	retention, err := s.Config.FetchLogRetentionDuration()
+	if err != nil {
+		fmt.Printf("Error pruning fetch log: %v\n", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	if _, err := s.db.DeleteFetchLogBefore(ctx, time.Now().UTC().Add(-retention)); err != nil {
+		fmt.Printf("Error pruning fetch log: %v\n", err)
+	}
+}
+
+// errFeedUnchanged is returned by loadFeed when the fetched body is the
+// same as last time, so there is nothing to parse or store. The feed
+// returned with it carries only the body's size and hash.
+var errFeedUnchanged = errors.New("feed unchanged since the last fetch")
+
+// loadFeed fetches and parses feed, or reads it back from the cache when
+// aggregating with --from-cache. Unless backfilling, a body identical to
+// the one last stored from isn't parsed at all, see errFeedUnchanged;
+// many servers send no ETag to answer that for us.
+func loadFeed(ctx context.Context, s *state, feed database.Feed, opts aggOptions) (*RSSFeed, error) {
+	sc, err := scraperFor(feed)
+	if err != nil {
+		return nil, err
+	}
+	maxItems := s.maxItems
+	if opts.backfill {
+		maxItems = 0
+	}
+	parse := func(r io.Reader) (*RSSFeed, error) {
+		if sc != nil {
+			return sc.scrape(r, feed.Url, maxItems)
+		}
+		return parseFeed(r, maxItems)
+	}
+
+	if opts.fromCache {
+		data, err := opts.cache.Latest(feed.Url)
+		if err != nil {
+			return nil, err
+		}
+		return parse(bytes.NewReader(data))
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, s.fetchTimeout)
+	defer cancel()
+
+	reqOpts, err := s.requestOptions(ctx, &feed)
+	if err != nil {
+		return nil, err
+	}
+
+	if sc == nil && redditListingURL(feed.Url) != "" {
+		return fetchRedditFeed(fetchCtx, s, feed.Url, reqOpts, maxItems)
+	}
+
+	body, permanentURL, err := s.fetcher.Fetch(fetchCtx, feed.Url, reqOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	// the whole body is needed to hash it before parsing; max_feed_size_mb
+	// bounds how much that is
+	_, readSpan := tracer.Start(ctx, "feed.read")
+	data, err := io.ReadAll(body)
+	readSpan.SetAttributes(attribute.Int("gator.bytes", len(data)))
+	endSpan(readSpan, err)
+	if err != nil {
+		return nil, fmt.Errorf("reading feed: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	if opts.keepRaw {
+		if path, err := opts.cache.Store(feed.Url, data); err != nil {
+			fmt.Printf("Error caching %s: %v\n", feed.Name, err)
+		} else {
+			infof("Kept raw copy of %s at %s\n", feed.Name, path)
+		}
+	}
+
+	// a feed that moved still has to be moved, however unchanged it is
+	moved := permanentURL != "" && permanentURL != feed.Url
+	if !opts.backfill && !moved && feed.ContentHash.Valid && feed.ContentHash.String == hash {
+		return &RSSFeed{Size: int64(len(data)), ContentHash: hash}, errFeedUnchanged
+	}
+
+	_, parseSpan := tracer.Start(ctx, "feed.parse")
+	rss, err := parse(bytes.NewReader(data))
+	if err == nil {
+		parseSpan.SetAttributes(attribute.Int("gator.items", len(rss.Channel.Item)))
+	}
+	endSpan(parseSpan, err)
+	if err != nil {
+		return nil, err
+	}
+	rss.PermanentURL = permanentURL
+	rss.ContentHash = hash
+	rss.Size = int64(len(data))
+
+	return rss, nil
+}
+
+// previewNewPosts reports which of the fetched items aren't stored yet
+// without writing anything.
+func previewNewPosts(ctx context.Context, s *state, feed database.Feed, rss *RSSFeed) (int, error) {
+	urls := make([]string, 0, len(rss.Channel.Item))
+	for _, item := range rss.Channel.Item {
+		urls = append(urls, item.Link)
+	}
+
+	existing, err := s.db.GetExistingPostUrls(ctx, database.GetExistingPostUrlsParams{
+		FeedID: feed.ID,
+		Urls:   urls,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to check existing posts: %v", err)
+	}
+
+	seen := make(map[string]bool, len(existing)+len(urls))
+	for _, url := range existing {
+		seen[url] = true
+	}
+
+	var fresh []RSSItem
+	for _, item := range rss.Channel.Item {
+		if seen[item.Link] {
+			continue
+		}
+		seen[item.Link] = true
+		fresh = append(fresh, item)
+	}
+
+	fmt.Printf("%s: %d items, %d new\n", feed.Name, len(rss.Channel.Item), len(fresh))
+	if rss.PermanentURL != "" && rss.PermanentURL != feed.Url {
+		fmt.Printf("  would move feed to %s\n", rss.PermanentURL)
+	}
+	for _, item := range fresh {
+		fmt.Printf("  + %s\n", item.Title)
+	}
+
+	return len(fresh), nil
+}
+
+// moveFeed points feed at the location it permanently redirected to, so
+// later fetches skip the redirect.
+func moveFeed(ctx context.Context, s *state, feed database.Feed, newURL string) error {
+	existing, err := s.db.GetFeedByUrl(ctx, newURL)
+	if err == nil {
+		return fmt.Errorf("moved to %s, which is already stored as feed %s", newURL, existing.Name)
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	if err := s.db.UpdateFeedUrl(ctx, database.UpdateFeedUrlParams{
+		ID:  feed.ID,
+		Url: newURL,
+	}); err != nil {
+		return err
+	}
+
+	infof("Feed %s moved permanently: %s -> %s\n", feed.Name, feed.Url, newURL)
+
+	return nil
+}
+
+func saveFeedMetadata(ctx context.Context, s *state, feed database.Feed, rss *RSSFeed) error {
+	author := sql.NullString{String: rss.Channel.Author, Valid: rss.Channel.Author != ""}
+	imageURL := sql.NullString{}
+	if rss.Channel.Image != nil && rss.Channel.Image.Href != "" {
+		imageURL = sql.NullString{String: rss.Channel.Image.Href, Valid: true}
+	}
+
+	if author == feed.Author && imageURL == feed.ImageUrl {
+		return nil
+	}
+
+	return s.db.UpdateFeedMetadata(ctx, database.UpdateFeedMetadataParams{
+		ID:       feed.ID,
+		Author:   author,
+		ImageUrl: imageURL,
+	})
+}
+
+func newPostParams(feed database.Feed, item RSSItem) database.CreatePostParams {
+	data := database.CreatePostParams{
+		ID:          uuid.New(),
+		FeedID:      feed.ID,
+		Title:       item.Title,
+		Url:         item.Link,
+		Description: sql.NullString{String: item.Description, Valid: item.Description != ""},
+	}
+	if guid := strings.TrimSpace(item.GUID); guid != "" {
+		data.Guid = sql.NullString{String: guid, Valid: true}
+	}
+
+	if item.Link != "" {
+		data.CanonicalUrl = sql.NullString{String: canonicalPostURL(item.Link), Valid: true}
+	}
+	if hash := titleHash(item.Title); hash != "" {
+		data.TitleHash = sql.NullString{String: hash, Valid: true}
+	}
+
+	if publishedAt, err := parsePubDate(item.PubDate); err == nil {
+		data.PublishedAt = sql.NullTime{Time: publishedAt, Valid: true}
+	}
+
+	if item.Enclosure != nil && item.Enclosure.URL != "" {
+		data.EnclosureUrl = sql.NullString{String: item.Enclosure.URL, Valid: true}
+		data.EnclosureType = sql.NullString{String: item.Enclosure.Type, Valid: item.Enclosure.Type != ""}
+		if length, err := strconv.ParseInt(item.Enclosure.Length, 10, 64); err == nil && length > 0 {
+			data.EnclosureLength = sql.NullInt64{Int64: length, Valid: true}
+		}
+	}
+
+	if item.Author != "" {
+		data.Author = sql.NullString{String: item.Author, Valid: true}
+	}
+	if item.Image != nil && item.Image.Href != "" {
+		data.ImageUrl = sql.NullString{String: item.Image.Href, Valid: true}
+	}
+	if thumbnailURL := item.ThumbnailURL(); thumbnailURL != "" {
+		data.ThumbnailUrl = sql.NullString{String: thumbnailURL, Valid: true}
+	}
+	if seconds, err := parseDuration(item.Duration); err == nil {
+		data.DurationSeconds = sql.NullInt32{Int32: seconds, Valid: true}
+	}
+	if episode, err := strconv.ParseInt(strings.TrimSpace(item.Episode), 10, 32); err == nil {
+		data.Episode = sql.NullInt32{Int32: int32(episode), Valid: true}
+	}
+	if season, err := strconv.ParseInt(strings.TrimSpace(item.Season), 10, 32); err == nil {
+		data.Season = sql.NullInt32{Int32: int32(season), Valid: true}
+	}
+	if e, ok := item.engagement(); ok {
+		data.Score = e.score
+		data.CommentCount = e.commentCount
+	}
+
+	return data
+}
+
+const postBatchSize = 100
+
+// postRecord is a post in the JSON shape CreatePosts unpacks with
+// json_to_recordset; nil fields become NULL.
+type postRecord struct {
+	ID              uuid.UUID  `json:"id"`
+	FeedID          uuid.UUID  `json:"feed_id"`
+	Title           string     `json:"title"`
+	Url             string     `json:"url"`
+	Description     *string    `json:"description"`
+	PublishedAt     *time.Time `json:"published_at"`
+	EnclosureUrl    *string    `json:"enclosure_url"`
+	EnclosureType   *string    `json:"enclosure_type"`
+	EnclosureLength *int64     `json:"enclosure_length"`
+	Author          *string    `json:"author"`
+	ImageUrl        *string    `json:"image_url"`
+	DurationSeconds *int32     `json:"duration_seconds"`
+	Episode         *int32     `json:"episode"`
+	Season          *int32     `json:"season"`
+	ThumbnailUrl    *string    `json:"thumbnail_url"`
+	CanonicalUrl    *string    `json:"canonical_url"`
+	TitleHash       *string    `json:"title_hash"`
+	Score           *int32     `json:"score"`
+	CommentCount    *int32     `json:"comment_count"`
+	Relevance       float64    `json:"relevance"`
+	Guid            *string    `json:"guid"`
+}
+
+// editRecord is a post as the feed has it now, in the JSON shape
+// UpdateEditedPosts unpacks.
+type editRecord struct {
+	Guid        *string `json:"guid"`
+	Url         string  `json:"url"`
+	Title       string  `json:"title"`
+	Description *string `json:"description"`
+	TitleHash   *string `json:"title_hash"`
+}
+
+// engagementRecord is a post's score and comment count in the JSON shape
+// UpdatePostEngagement unpacks.
+type engagementRecord struct {
+	Url          string `json:"url"`
+	Score        *int32 `json:"score"`
+	CommentCount *int32 `json:"comment_count"`
+}
+
+func nullable[T any](value T, valid bool) *T {
+	if !valid {
+		return nil
+	}
+	return &value
+}
+
+func newPostRecord(p database.CreatePostParams) postRecord {
+	return postRecord{
+		ID:              p.ID,
+		FeedID:          p.FeedID,
+		Title:           p.Title,
+		Url:             p.Url,
+		Description:     nullable(p.Description.String, p.Description.Valid),
+		PublishedAt:     nullable(p.PublishedAt.Time, p.PublishedAt.Valid),
+		EnclosureUrl:    nullable(p.EnclosureUrl.String, p.EnclosureUrl.Valid),
+		EnclosureType:   nullable(p.EnclosureType.String, p.EnclosureType.Valid),
+		EnclosureLength: nullable(p.EnclosureLength.Int64, p.EnclosureLength.Valid),
+		Author:          nullable(p.Author.String, p.Author.Valid),
+		ImageUrl:        nullable(p.ImageUrl.String, p.ImageUrl.Valid),
+		DurationSeconds: nullable(p.DurationSeconds.Int32, p.DurationSeconds.Valid),
+		Episode:         nullable(p.Episode.Int32, p.Episode.Valid),
+		Season:          nullable(p.Season.Int32, p.Season.Valid),
+		ThumbnailUrl:    nullable(p.ThumbnailUrl.String, p.ThumbnailUrl.Valid),
+		CanonicalUrl:    nullable(p.CanonicalUrl.String, p.CanonicalUrl.Valid),
+		TitleHash:       nullable(p.TitleHash.String, p.TitleHash.Valid),
+		Score:           nullable(p.Score.Int32, p.Score.Valid),
+		CommentCount:    nullable(p.CommentCount.Int32, p.CommentCount.Valid),
+		Relevance:       p.Relevance,
+		Guid:            nullable(p.Guid.String, p.Guid.Valid),
+	}
+}
+
+// savePosts stores items in batches of postBatchSize, one round trip per
+// batch, and returns how many of them were new. A batch the database
+// rejects is retried post by post so one bad item doesn't lose the rest.
+func savePosts(ctx context.Context, s *state, feed database.Feed, items []RSSItem) int {
+	model := loadScoringModel(ctx, s)
+	script := loadPostScript(s)
+
+	var created, existing []database.CreatePostParams
+	tags := make(map[uuid.UUID][]string)
+	for start := 0; start < len(items); start += postBatchSize {
+		batch := make([]database.CreatePostParams, 0, postBatchSize)
+		for _, item := range items[start:min(start+postBatchSize, len(items))] {
+			post := newPostParams(feed, item)
+			post.Relevance = model.score(feed, post)
+			keep, postTags, err := script.apply(feed, &post)
+			if err != nil {
+				fmt.Printf("Error running filter_script on %s: %v\n", post.Url, err)
+			}
+			if !keep {
+				debugf("filter_script dropped %s\n", post.Url)
+				continue
+			}
+			if len(postTags) > 0 {
+				tags[post.ID] = postTags
+			}
+			batch = append(batch, post)
+		}
+		if len(batch) == 0 {
+			continue
+		}
+
+		records := make([]postRecord, 0, len(batch))
+		for _, post := range batch {
+			records = append(records, newPostRecord(post))
+		}
+
+		data, err := json.Marshal(records)
+		if err == nil {
+			var ids []uuid.UUID
+			ids, err = s.db.CreatePosts(ctx, data)
+			if err == nil {
+				inserted := make(map[uuid.UUID]bool, len(ids))
+				for _, id := range ids {
+					inserted[id] = true
+				}
+				for _, post := range batch {
+					if inserted[post.ID] {
+						created = append(created, post)
+					} else {
+						existing = append(existing, post)
+					}
+				}
+				continue
+			}
+		}
+
+		fmt.Printf("Error saving posts for %s, retrying one at a time: %v\n", feed.Name, err)
+		for _, post := range batch {
+			isNew, err := savePost(ctx, s, post)
+			if err != nil {
+				fmt.Printf("Error saving post %s: %v\n", post.Url, err)
+				continue
+			}
+			if isNew {
+				created = append(created, post)
+			} else {
+				existing = append(existing, post)
+			}
+		}
+	}
+
+	tagPosts(ctx, s, created, tags)
+	updateEditedPosts(ctx, s, feed, existing)
+	updateEngagement(ctx, s, feed, items)
+	s.notifier.postsArrived(ctx, feed, created)
+	hookNewPosts(ctx, s, feed, created)
+
+	return len(created)
+}
+
+// tagPosts stores the tags filter_script gave posts that were just
+// created.
+func tagPosts(ctx context.Context, s *state, created []database.CreatePostParams, tags map[uuid.UUID][]string) {
+	for _, post := range created {
+		if len(tags[post.ID]) == 0 {
+			continue
+		}
+		err := s.db.AddPostTags(ctx, database.AddPostTagsParams{PostID: post.ID, Tags: tags[post.ID]})
+		if err != nil {
+			fmt.Printf("Error tagging post %s: %v\n", post.Url, err)
+		}
+	}
+}
+
+// engagement is the attention a post got where it was published.
+type engagement struct {
+	score        sql.NullInt32
+	commentCount sql.NullInt32
+}
+
+// engagement returns the item's score and comment count, if its source
+// reports either.
+func (item RSSItem) engagement() (engagement, bool) {
+	var e engagement
+	if item.Score != nil {
+		e.score = sql.NullInt32{Int32: *item.Score, Valid: true}
+	}
+	if item.CommentCount != nil {
+		e.commentCount = sql.NullInt32{Int32: *item.CommentCount, Valid: true}
+	} else if comments, err := strconv.ParseInt(strings.TrimSpace(item.Comments), 10, 32); err == nil && comments >= 0 {
+		e.commentCount = sql.NullInt32{Int32: int32(comments), Valid: true}
+	}
+	return e, e.score.Valid || e.commentCount.Valid
+}
+
+// updateEditedPosts brings posts that were already stored up to date when
+// the feed has since changed their title or description, as happens with
+// corrections, and marks them edited.
+func updateEditedPosts(ctx context.Context, s *state, feed database.Feed, posts []database.CreatePostParams) {
+	if len(posts) == 0 {
+		return
+	}
+
+	records := make([]editRecord, 0, len(posts))
+	for _, post := range posts {
+		records = append(records, editRecord{
+			Guid:        nullable(post.Guid.String, post.Guid.Valid),
+			Url:         post.Url,
+			Title:       post.Title,
+			Description: nullable(post.Description.String, post.Description.Valid),
+			TitleHash:   nullable(post.TitleHash.String, post.TitleHash.Valid),
+		})
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		fmt.Printf("Error updating edited posts for %s: %v\n", feed.Name, err)
+		return
+	}
+	ids, err := s.db.UpdateEditedPosts(ctx, database.UpdateEditedPostsParams{
+		Posts:  data,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		fmt.Printf("Error updating edited posts for %s: %v\n", feed.Name, err)
+		return
+	}
+	if len(ids) > 0 {
+		infof("Updated %d edited posts in %s\n", len(ids), feed.Name)
+	}
+}
+
+// updateEngagement refreshes the scores and comment counts of posts that
+// were already stored, since they keep changing after a post first shows
+// up.
+func updateEngagement(ctx context.Context, s *state, feed database.Feed, items []RSSItem) {
+	var records []engagementRecord
+	for _, item := range items {
+		e, ok := item.engagement()
+		if !ok {
+			continue
+		}
+		records = append(records, engagementRecord{
+			Url:          item.Link,
+			Score:        nullable(e.score.Int32, e.score.Valid),
+			CommentCount: nullable(e.commentCount.Int32, e.commentCount.Valid),
+		})
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(records)
+	if err == nil {
+		err = s.db.UpdatePostEngagement(ctx, database.UpdatePostEngagementParams{
+			Posts:  data,
+			FeedID: feed.ID,
+		})
+	}
+	if err != nil {
+		fmt.Printf("Error updating scores for %s: %v\n", feed.Name, err)
+	}
+}
+
+// savePost stores post and reports whether it was new.
+func savePost(ctx context.Context, s *state, post database.CreatePostParams) (bool, error) {
+	// posts already stored are skipped by ON CONFLICT and come back as no rows
+	if _, err := s.db.CreatePost(ctx, post); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+func TestAggregateFeedDryRun(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Name: "Example Blog", Url: "https://example.com/feed.xml"}
+	db := &fakeStore{postURLs: map[uuid.UUID][]string{
+		feed.ID: {"https://example.com/1"},
+		// the same article in another feed doesn't count as stored
+		uuid.New(): {"https://example.com/2"},
+	}}
+	s := testState(t, db)
+	s.fetcher = &fakeFetcher{bodies: map[string]string{feed.Url: testFeed}}
+
+	fresh, err := aggregateFeed(context.Background(), s, feed, aggOptions{dryRun: true})
+	if err != nil {
+		t.Fatalf("aggregating: %v", err)
+	}
+	if fresh != 1 {
+		t.Errorf("found %d new posts, want 1", fresh)
+	}
+}
+
+func TestLoadFeed(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Name: "Example Blog", Url: "https://example.com/feed.xml"}
+	s := testState(t, &fakeStore{})
+	s.fetcher = &fakeFetcher{bodies: map[string]string{feed.Url: testFeed}}
+
+	rss, err := loadFeed(context.Background(), s, feed, aggOptions{})
+	if err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+	if len(rss.Channel.Item) != 2 || rss.Channel.Item[0].Title != "Second post" {
+		t.Errorf("loaded items %+v, want the two posts in feed order", rss.Channel.Item)
+	}
+	sum := sha256.Sum256([]byte(testFeed))
+	if rss.ContentHash != hex.EncodeToString(sum[:]) || rss.Size != int64(len(testFeed)) {
+		t.Errorf("hash %s and size %d don't match the body", rss.ContentHash, rss.Size)
+	}
+}
+
+func TestLoadFeedUnchanged(t *testing.T) {
+	// a body that doesn't parse shows it is skipped before parsing
+	const body = "not a feed"
+	sum := sha256.Sum256([]byte(body))
+	feed := database.Feed{
+		ID:          uuid.New(),
+		Name:        "Example Blog",
+		Url:         "https://example.com/feed.xml",
+		ContentHash: sql.NullString{String: hex.EncodeToString(sum[:]), Valid: true},
+	}
+	s := testState(t, &fakeStore{})
+	s.fetcher = &fakeFetcher{bodies: map[string]string{feed.Url: body}}
+
+	_, err := loadFeed(context.Background(), s, feed, aggOptions{})
+	if !errors.Is(err, errFeedUnchanged) {
+		t.Errorf("got %v, want errFeedUnchanged", err)
+	}
+
+	// backfilling parses whatever the hash says
+	if _, err := loadFeed(context.Background(), s, feed, aggOptions{backfill: true}); err == nil || errors.Is(err, errFeedUnchanged) {
+		t.Errorf("backfilling got %v, want a parse error", err)
+	}
+}
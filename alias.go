@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// handlerAlias manages the current user's short names for feeds, which
+// work anywhere a feed name or URL does.
+func handlerAlias(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return aliasList(ctx, s, nil)
+	}
+
+	switch cmd.Args[0] {
+	case "add":
+		return aliasAdd(ctx, s, cmd.Args[1:])
+	case "remove":
+		return aliasRemove(ctx, s, cmd.Args[1:])
+	case "list":
+		return aliasList(ctx, s, cmd.Args[1:])
+	default:
+		return usageErrorf("unknown alias subcommand: %s", cmd.Args[0])
+	}
+}
+
+// normalizeAlias lowercases an alias and checks it is a single word that
+// can't be mistaken for a URL.
+func normalizeAlias(alias string) (string, error) {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	if alias == "" {
+		return "", usageErrorf("alias must not be empty")
+	}
+	for _, r := range alias {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return "", usageErrorf("alias %s may only contain letters, digits, '-', '_' and '.'", alias)
+		}
+	}
+	return alias, nil
+}
+
+func aliasAdd(ctx context.Context, s *state, args []string) error {
+	if len(args) < 2 {
+		return usageErrorf("alias add requires an alias and a feed name or URL")
+	}
+
+	alias, err := normalizeAlias(args[0])
+	if err != nil {
+		return err
+	}
+
+	ref := strings.Join(args[1:], " ")
+	feed, err := findFeed(ctx, s, ref)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundErrorf("feed %s does not exist", ref)
+		}
+		return fmt.Errorf("failed to get feed: %v", err)
+	}
+
+	// findFeed may have waited on the user, so the timeout starts here
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	added, err := s.db.AddFeedAlias(ctx, database.AddFeedAliasParams{
+		UserID: user.ID,
+		Alias:  alias,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add alias: %v", err)
+	}
+	if added == 0 {
+		existing, err := s.db.GetFeedByAlias(ctx, database.GetFeedByAliasParams{UserID: user.ID, Alias: alias})
+		if err != nil {
+			return conflictErrorf("alias %s already exists", alias)
+		}
+		return conflictErrorf("alias %s already points to %s, remove it first", alias, existing.Name)
+	}
+
+	infof("%s now refers to %s\n", alias, feed.Name)
+	return nil
+}
+
+func aliasRemove(ctx context.Context, s *state, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("alias remove requires an alias")
+	}
+
+	alias, err := normalizeAlias(args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.db.DeleteFeedAlias(ctx, database.DeleteFeedAliasParams{UserID: user.ID, Alias: alias})
+	if err != nil {
+		return fmt.Errorf("failed to remove alias: %v", err)
+	}
+	if removed == 0 {
+		return notFoundErrorf("alias %s does not exist", alias)
+	}
+
+	infof("Removed alias %s\n", alias)
+	return nil
+}
+
+func aliasList(ctx context.Context, s *state, args []string) error {
+	if len(args) > 0 {
+		return usageErrorf("alias list takes no arguments")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	aliases, err := s.db.GetFeedAliasesForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get aliases: %v", err)
+	}
+	if len(aliases) == 0 {
+		fmt.Println("No aliases, add one with alias add <alias> <feed>")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, a := range aliases {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", a.Alias, a.FeedName, a.FeedUrl)
+	}
+	return w.Flush()
+}
+
+// findFeedByAlias looks ref up among the aliases userID has set. It
+// returns sql.ErrNoRows when ref is not one of them.
+func findFeedByAlias(ctx context.Context, s *state, userID uuid.UUID, ref string) (database.Feed, error) {
+	alias, err := normalizeAlias(ref)
+	if err != nil {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	return s.db.GetFeedByAlias(ctx, database.GetFeedByAliasParams{UserID: userID, Alias: alias})
+}
@@ -0,0 +1,491 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// maxAPIBody caps the request bodies the API reads.
+const maxAPIBody = 1 << 20
+
+// userHandler is an API or web handler for an authenticated user; every
+// query it makes is scoped to that user.
+type userHandler func(w http.ResponseWriter, r *http.Request, ctx context.Context, user database.User)
+
+// registerMultiUserHandlers adds the session login, the JSON API and the
+// web pages. Every user sees their own follows, read state and stars.
+func registerMultiUserHandlers(mux *http.ServeMux, s *state) {
+	mux.HandleFunc("POST /api/login", func(w http.ResponseWriter, r *http.Request) { apiLogin(w, r, s) })
+	mux.HandleFunc("POST /api/logout", func(w http.ResponseWriter, r *http.Request) { logout(w, r, s) })
+
+	api := func(pattern string, h userHandler) {
+		mux.HandleFunc(pattern, withUser(s, h, func(w http.ResponseWriter, r *http.Request) {
+			writeAPIError(w, http.StatusUnauthorized, errBadLogin.Error())
+		}))
+	}
+	api("GET /api/me", apiMe)
+	api("GET /api/posts", func(w http.ResponseWriter, r *http.Request, ctx context.Context, user database.User) {
+		apiPosts(w, r, ctx, s, user)
+	})
+	api("POST /api/posts/{id}/read", func(w http.ResponseWriter, r *http.Request, ctx context.Context, user database.User) {
+		apiMarkRead(w, r, ctx, s, user)
+	})
+	api("PUT /api/posts/{id}/star", func(w http.ResponseWriter, r *http.Request, ctx context.Context, user database.User) {
+		apiStar(w, r, ctx, s, user)
+	})
+	api("DELETE /api/posts/{id}/star", func(w http.ResponseWriter, r *http.Request, ctx context.Context, user database.User) {
+		apiUnstar(w, r, ctx, s, user)
+	})
+	api("GET /api/starred", func(w http.ResponseWriter, r *http.Request, ctx context.Context, user database.User) {
+		apiStarred(w, ctx, s, user)
+	})
+	api("GET /api/follows", func(w http.ResponseWriter, r *http.Request, ctx context.Context, user database.User) {
+		apiFollows(w, ctx, s, user)
+	})
+	api("POST /api/follows", func(w http.ResponseWriter, r *http.Request, ctx context.Context, user database.User) {
+		apiFollow(w, r, ctx, s, user)
+	})
+	api("DELETE /api/follows/{feed_id}", func(w http.ResponseWriter, r *http.Request, ctx context.Context, user database.User) {
+		apiUnfollow(w, r, ctx, s, user)
+	})
+
+	mux.HandleFunc("GET /login", func(w http.ResponseWriter, r *http.Request) {
+		renderLoginPage(w, "")
+	})
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) { webLogin(w, r, s) })
+	mux.HandleFunc("POST /logout", func(w http.ResponseWriter, r *http.Request) {
+		logout(w, r, s)
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+	})
+	mux.HandleFunc("GET /{$}", withUser(s, func(w http.ResponseWriter, r *http.Request, ctx context.Context, user database.User) {
+		webHome(w, ctx, s, user)
+	}, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+	}))
+}
+
+// withUser runs h for authenticated requests and anonymous for the rest.
+func withUser(s *state, h userHandler, anonymous http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), s.dbTimeout)
+		defer cancel()
+
+		user, err := requestUser(ctx, s, r)
+		if err == errBadLogin {
+			anonymous(w, r)
+			return
+		}
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !limitUser(w, r, user) {
+			return
+		}
+
+		h(w, r, ctx, user)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Printf("Error writing response: %v\n", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// readJSON decodes a request body into v; an empty body leaves v alone.
+func readJSON(r *http.Request, v any) error {
+	err := json.NewDecoder(io.LimitReader(r.Body, maxAPIBody)).Decode(v)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// startSession logs a user in and hands the session to the client as a
+// cookie, for browsers, and returns the token, for API clients.
+func startSession(w http.ResponseWriter, r *http.Request, ctx context.Context, s *state, name, password string) (string, time.Time, error) {
+	user, err := authenticate(ctx, s, name, password)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	token, expires, err := newSession(ctx, s, user)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return token, expires, nil
+}
+
+func apiLogin(w http.ResponseWriter, r *http.Request, s *state) {
+	var creds struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	if err := readJSON(r, &creds); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.dbTimeout)
+	defer cancel()
+
+	token, expires, err := startSession(w, r, ctx, s, creds.Name, creds.Password)
+	if err == errBadLogin {
+		writeAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"token": token, "expires_at": expires})
+}
+
+func logout(w http.ResponseWriter, r *http.Request, s *state) {
+	ctx, cancel := context.WithTimeout(r.Context(), s.dbTimeout)
+	defer cancel()
+
+	if token := sessionToken(r); token != "" {
+		if err := s.db.DeleteSession(ctx, hashSessionToken(token)); err != nil {
+			fmt.Printf("Error ending session: %v\n", err)
+		}
+	}
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "", Path: "/", MaxAge: -1})
+
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func apiMe(w http.ResponseWriter, r *http.Request, ctx context.Context, user database.User) {
+	writeJSON(w, http.StatusOK, map[string]any{"id": user.ID, "name": user.Name})
+}
+
+func apiPosts(w http.ResponseWriter, r *http.Request, ctx context.Context, s *state, user database.User) {
+	query := r.URL.Query()
+
+	limit, err := browseLimit(ctx, s, user)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeAPIError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		limit = n
+	}
+	sortBy := query.Get("sort")
+	if sortBy == "" {
+		sortBy = "published"
+	}
+	sortBy, descending, err := postOrder(sortBy)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "sort must be one of "+strings.Join(browseSorts, ", "))
+		return
+	}
+
+	posts, err := s.db.GetPostsForUser(ctx, database.GetPostsForUserParams{
+		UserID:     user.ID,
+		SortBy:     sortBy,
+		Descending: descending,
+		MaxPosts:   int32(limit),
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("failed to get posts: %v", err))
+		return
+	}
+
+	exported := make([]exportedPost, 0, len(posts))
+	for _, row := range posts {
+		exported = append(exported, newExportedPost(row.Post, row.FeedName))
+	}
+	if err := loadPostTags(ctx, s, exported); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, exported)
+}
+
+// pathPost loads the post named in the request path.
+func pathPost(w http.ResponseWriter, r *http.Request, ctx context.Context, s *state) (database.Post, bool) {
+	postID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, "no such post")
+		return database.Post{}, false
+	}
+
+	post, err := s.db.GetPostById(ctx, postID)
+	if err == sql.ErrNoRows {
+		writeAPIError(w, http.StatusNotFound, "no such post")
+		return database.Post{}, false
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("failed to get post: %v", err))
+		return database.Post{}, false
+	}
+	return post, true
+}
+
+func apiMarkRead(w http.ResponseWriter, r *http.Request, ctx context.Context, s *state, user database.User) {
+	post, ok := pathPost(w, r, ctx, s)
+	if !ok {
+		return
+	}
+
+	if _, err := s.db.MarkPostRead(ctx, database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	}); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("failed to mark post as read: %v", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func apiStar(w http.ResponseWriter, r *http.Request, ctx context.Context, s *state, user database.User) {
+	post, ok := pathPost(w, r, ctx, s)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Note string `json:"note"`
+	}
+	if err := readJSON(r, &body); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	if err := starPost(ctx, s, user.ID, post.ID, strings.TrimSpace(body.Note)); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func apiUnstar(w http.ResponseWriter, r *http.Request, ctx context.Context, s *state, user database.User) {
+	post, ok := pathPost(w, r, ctx, s)
+	if !ok {
+		return
+	}
+
+	if _, err := s.db.UnstarPost(ctx, database.UnstarPostParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	}); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("failed to unstar post: %v", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func apiStarred(w http.ResponseWriter, ctx context.Context, s *state, user database.User) {
+	posts, err := s.db.GetStarredPosts(ctx, user.ID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("failed to get starred posts: %v", err))
+		return
+	}
+
+	type starredPost struct {
+		exportedPost
+		Note      string    `json:"note,omitempty"`
+		StarredAt time.Time `json:"starred_at"`
+	}
+	starred := make([]starredPost, 0, len(posts))
+	for _, row := range posts {
+		starred = append(starred, starredPost{
+			exportedPost: newExportedPost(row.Post, row.FeedName),
+			Note:         row.Note.String,
+			StarredAt:    row.StarredAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, starred)
+}
+
+type apiFeedFollow struct {
+	FeedID   uuid.UUID `json:"feed_id"`
+	FeedName string    `json:"feed_name"`
+	FeedURL  string    `json:"feed_url"`
+}
+
+func apiFollows(w http.ResponseWriter, ctx context.Context, s *state, user database.User) {
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("failed to get follows: %v", err))
+		return
+	}
+
+	out := make([]apiFeedFollow, 0, len(follows))
+	for _, follow := range follows {
+		out = append(out, apiFeedFollow{FeedID: follow.FeedID, FeedName: follow.FeedName, FeedURL: follow.FeedUrl})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// apiFollow follows a feed gator already knows, named by URL or name.
+func apiFollow(w http.ResponseWriter, r *http.Request, ctx context.Context, s *state, user database.User) {
+	var body struct {
+		Feed string `json:"feed"`
+	}
+	if err := readJSON(r, &body); err != nil || body.Feed == "" {
+		writeAPIError(w, http.StatusBadRequest, `expected {"feed": "<url or name>"}`)
+		return
+	}
+
+	feed, err := lookupFeed(ctx, s, user.ID, body.Feed)
+	if err == sql.ErrNoRows {
+		writeAPIError(w, http.StatusNotFound, "no such feed")
+		return
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("failed to get feed: %v", err))
+		return
+	}
+
+	if _, err := s.db.CreateFeedFollowIfMissing(ctx, database.CreateFeedFollowIfMissingParams{
+		ID:     uuid.New(),
+		UserID: user.ID,
+		FeedID: feed.ID,
+	}); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("failed to follow feed: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, apiFeedFollow{FeedID: feed.ID, FeedName: feed.Name, FeedURL: feed.Url})
+}
+
+func apiUnfollow(w http.ResponseWriter, r *http.Request, ctx context.Context, s *state, user database.User) {
+	feedID, err := uuid.Parse(r.PathValue("feed_id"))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, "no such feed")
+		return
+	}
+
+	rows, err := s.db.DeleteFeedFollow(ctx, database.DeleteFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feedID,
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("failed to unfollow feed: %v", err))
+		return
+	}
+	if rows == 0 {
+		writeAPIError(w, http.StatusNotFound, "not following that feed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var webTemplates = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>gator: log in</title></head>
+<body>
+<h1>gator</h1>
+{{if .}}<p><strong>{{.}}</strong></p>{{end}}
+<form method="post" action="/login">
+<p><label>User <input name="name" autocomplete="username" required></label></p>
+<p><label>Password <input name="password" type="password" autocomplete="current-password" required></label></p>
+<p><button>Log in</button></p>
+</form>
+</body>
+</html>
+`))
+
+var webHomeTemplate = template.Must(webTemplates.New("home").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>gator: {{.User}}</title></head>
+<body>
+<form method="post" action="/logout"><p>Logged in as {{.User}} <button>Log out</button></p></form>
+<ol>
+{{- range .Posts}}
+<li><a href="{{.Post.Url}}">{{.Post.Title}}</a> <small>{{.FeedName}}</small></li>
+{{- end}}
+</ol>
+</body>
+</html>
+`))
+
+func renderLoginPage(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if message != "" {
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+	if err := webTemplates.ExecuteTemplate(w, "login", message); err != nil {
+		fmt.Printf("Error rendering login page: %v\n", err)
+	}
+}
+
+func webLogin(w http.ResponseWriter, r *http.Request, s *state) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAPIBody)
+	if err := r.ParseForm(); err != nil {
+		renderLoginPage(w, "Invalid form")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.dbTimeout)
+	defer cancel()
+
+	_, _, err := startSession(w, r, ctx, s, r.PostForm.Get("name"), r.PostForm.Get("password"))
+	if err == errBadLogin {
+		renderLoginPage(w, "Wrong user name or password")
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func webHome(w http.ResponseWriter, ctx context.Context, s *state, user database.User) {
+	posts, err := s.db.GetPostsForUser(ctx, database.GetPostsForUserParams{
+		UserID:     user.ID,
+		SortBy:     "published",
+		Descending: true,
+		MaxPosts:   defaultPublishLimit,
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get posts: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := webHomeTemplate.Execute(w, struct {
+		User  string
+		Posts []database.GetPostsForUserRow
+	}{user.Name, posts}); err != nil {
+		fmt.Printf("Error rendering page: %v\n", err)
+	}
+}
@@ -0,0 +1,776 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: api/gator/v1/gator.proto
+
+// Package gator.v1 is the gRPC API of a gator instance, served by
+// `gator serve --grpc <addr>`.
+//
+// Calls act for one user. In multi-user mode they authenticate with an
+// "authorization: Bearer <session token>" metadata entry, the token coming
+// from POST /api/login; otherwise they act for the configured user.
+
+package gatorv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Feed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Followed      bool                   `protobuf:"varint,4,opt,name=followed,proto3" json:"followed,omitempty"`
+	LastFetchedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_fetched_at,json=lastFetchedAt,proto3" json:"last_fetched_at,omitempty"`
+	// followers is how many users follow the feed; only ListFeeds sets it.
+	Followers     int64 `protobuf:"varint,6,opt,name=followers,proto3" json:"followers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Feed) Reset() {
+	*x = Feed{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Feed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Feed) ProtoMessage() {}
+
+func (x *Feed) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Feed.ProtoReflect.Descriptor instead.
+func (*Feed) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Feed) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Feed) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Feed) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Feed) GetFollowed() bool {
+	if x != nil {
+		return x.Followed
+	}
+	return false
+}
+
+func (x *Feed) GetLastFetchedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastFetchedAt
+	}
+	return nil
+}
+
+func (x *Feed) GetFollowers() int64 {
+	if x != nil {
+		return x.Followers
+	}
+	return 0
+}
+
+type Post struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FeedId        string                 `protobuf:"bytes,2,opt,name=feed_id,json=feedId,proto3" json:"feed_id,omitempty"`
+	FeedName      string                 `protobuf:"bytes,3,opt,name=feed_name,json=feedName,proto3" json:"feed_name,omitempty"`
+	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Url           string                 `protobuf:"bytes,5,opt,name=url,proto3" json:"url,omitempty"`
+	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	Author        string                 `protobuf:"bytes,7,opt,name=author,proto3" json:"author,omitempty"`
+	PublishedAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Post) Reset() {
+	*x = Post{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Post) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Post) ProtoMessage() {}
+
+func (x *Post) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Post.ProtoReflect.Descriptor instead.
+func (*Post) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Post) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Post) GetFeedId() string {
+	if x != nil {
+		return x.FeedId
+	}
+	return ""
+}
+
+func (x *Post) GetFeedName() string {
+	if x != nil {
+		return x.FeedName
+	}
+	return ""
+}
+
+func (x *Post) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Post) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Post) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Post) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *Post) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+func (x *Post) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListFeedsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFeedsRequest) Reset() {
+	*x = ListFeedsRequest{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFeedsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFeedsRequest) ProtoMessage() {}
+
+func (x *ListFeedsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFeedsRequest.ProtoReflect.Descriptor instead.
+func (*ListFeedsRequest) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{2}
+}
+
+type ListFeedsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Feeds         []*Feed                `protobuf:"bytes,1,rep,name=feeds,proto3" json:"feeds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFeedsResponse) Reset() {
+	*x = ListFeedsResponse{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFeedsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFeedsResponse) ProtoMessage() {}
+
+func (x *ListFeedsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFeedsResponse.ProtoReflect.Descriptor instead.
+func (*ListFeedsResponse) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListFeedsResponse) GetFeeds() []*Feed {
+	if x != nil {
+		return x.Feeds
+	}
+	return nil
+}
+
+type FollowFeedRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// feed is the URL or name of a stored feed.
+	Feed          string `protobuf:"bytes,1,opt,name=feed,proto3" json:"feed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowFeedRequest) Reset() {
+	*x = FollowFeedRequest{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowFeedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowFeedRequest) ProtoMessage() {}
+
+func (x *FollowFeedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowFeedRequest.ProtoReflect.Descriptor instead.
+func (*FollowFeedRequest) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FollowFeedRequest) GetFeed() string {
+	if x != nil {
+		return x.Feed
+	}
+	return ""
+}
+
+type FollowFeedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Feed          *Feed                  `protobuf:"bytes,1,opt,name=feed,proto3" json:"feed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowFeedResponse) Reset() {
+	*x = FollowFeedResponse{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowFeedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowFeedResponse) ProtoMessage() {}
+
+func (x *FollowFeedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowFeedResponse.ProtoReflect.Descriptor instead.
+func (*FollowFeedResponse) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FollowFeedResponse) GetFeed() *Feed {
+	if x != nil {
+		return x.Feed
+	}
+	return nil
+}
+
+type UnfollowFeedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FeedId        string                 `protobuf:"bytes,1,opt,name=feed_id,json=feedId,proto3" json:"feed_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnfollowFeedRequest) Reset() {
+	*x = UnfollowFeedRequest{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnfollowFeedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnfollowFeedRequest) ProtoMessage() {}
+
+func (x *UnfollowFeedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnfollowFeedRequest.ProtoReflect.Descriptor instead.
+func (*UnfollowFeedRequest) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UnfollowFeedRequest) GetFeedId() string {
+	if x != nil {
+		return x.FeedId
+	}
+	return ""
+}
+
+type UnfollowFeedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnfollowFeedResponse) Reset() {
+	*x = UnfollowFeedResponse{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnfollowFeedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnfollowFeedResponse) ProtoMessage() {}
+
+func (x *UnfollowFeedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnfollowFeedResponse.ProtoReflect.Descriptor instead.
+func (*UnfollowFeedResponse) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{7}
+}
+
+type BrowseRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// limit defaults to the user's browse.limit preference.
+	Limit int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	// sort is one of published (also accepted as date), added, feed, title,
+	// points, comments or score; published by default.
+	Sort          string `protobuf:"bytes,2,opt,name=sort,proto3" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BrowseRequest) Reset() {
+	*x = BrowseRequest{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BrowseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BrowseRequest) ProtoMessage() {}
+
+func (x *BrowseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BrowseRequest.ProtoReflect.Descriptor instead.
+func (*BrowseRequest) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BrowseRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *BrowseRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type BrowseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Posts         []*Post                `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BrowseResponse) Reset() {
+	*x = BrowseResponse{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BrowseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BrowseResponse) ProtoMessage() {}
+
+func (x *BrowseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BrowseResponse.ProtoReflect.Descriptor instead.
+func (*BrowseResponse) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BrowseResponse) GetPosts() []*Post {
+	if x != nil {
+		return x.Posts
+	}
+	return nil
+}
+
+type MarkReadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkReadRequest) Reset() {
+	*x = MarkReadRequest{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkReadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkReadRequest) ProtoMessage() {}
+
+func (x *MarkReadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkReadRequest.ProtoReflect.Descriptor instead.
+func (*MarkReadRequest) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *MarkReadRequest) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+type MarkReadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkReadResponse) Reset() {
+	*x = MarkReadResponse{}
+	mi := &file_api_gator_v1_gator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkReadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkReadResponse) ProtoMessage() {}
+
+func (x *MarkReadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gator_v1_gator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkReadResponse.ProtoReflect.Descriptor instead.
+func (*MarkReadResponse) Descriptor() ([]byte, []int) {
+	return file_api_gator_v1_gator_proto_rawDescGZIP(), []int{11}
+}
+
+var File_api_gator_v1_gator_proto protoreflect.FileDescriptor
+
+const file_api_gator_v1_gator_proto_rawDesc = "" +
+	"\n" +
+	"\x18api/gator/v1/gator.proto\x12\bgator.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xba\x01\n" +
+	"\x04Feed\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12\x1a\n" +
+	"\bfollowed\x18\x04 \x01(\bR\bfollowed\x12B\n" +
+	"\x0flast_fetched_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\rlastFetchedAt\x12\x1c\n" +
+	"\tfollowers\x18\x06 \x01(\x03R\tfollowers\"\xa8\x02\n" +
+	"\x04Post\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\afeed_id\x18\x02 \x01(\tR\x06feedId\x12\x1b\n" +
+	"\tfeed_name\x18\x03 \x01(\tR\bfeedName\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12\x10\n" +
+	"\x03url\x18\x05 \x01(\tR\x03url\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06author\x18\a \x01(\tR\x06author\x12=\n" +
+	"\fpublished_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x12\n" +
+	"\x10ListFeedsRequest\"9\n" +
+	"\x11ListFeedsResponse\x12$\n" +
+	"\x05feeds\x18\x01 \x03(\v2\x0e.gator.v1.FeedR\x05feeds\"'\n" +
+	"\x11FollowFeedRequest\x12\x12\n" +
+	"\x04feed\x18\x01 \x01(\tR\x04feed\"8\n" +
+	"\x12FollowFeedResponse\x12\"\n" +
+	"\x04feed\x18\x01 \x01(\v2\x0e.gator.v1.FeedR\x04feed\".\n" +
+	"\x13UnfollowFeedRequest\x12\x17\n" +
+	"\afeed_id\x18\x01 \x01(\tR\x06feedId\"\x16\n" +
+	"\x14UnfollowFeedResponse\"9\n" +
+	"\rBrowseRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x12\n" +
+	"\x04sort\x18\x02 \x01(\tR\x04sort\"6\n" +
+	"\x0eBrowseResponse\x12$\n" +
+	"\x05posts\x18\x01 \x03(\v2\x0e.gator.v1.PostR\x05posts\"*\n" +
+	"\x0fMarkReadRequest\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\tR\x06postId\"\x12\n" +
+	"\x10MarkReadResponse2\xec\x02\n" +
+	"\fGatorService\x12D\n" +
+	"\tListFeeds\x12\x1a.gator.v1.ListFeedsRequest\x1a\x1b.gator.v1.ListFeedsResponse\x12G\n" +
+	"\n" +
+	"FollowFeed\x12\x1b.gator.v1.FollowFeedRequest\x1a\x1c.gator.v1.FollowFeedResponse\x12M\n" +
+	"\fUnfollowFeed\x12\x1d.gator.v1.UnfollowFeedRequest\x1a\x1e.gator.v1.UnfollowFeedResponse\x12;\n" +
+	"\x06Browse\x12\x17.gator.v1.BrowseRequest\x1a\x18.gator.v1.BrowseResponse\x12A\n" +
+	"\bMarkRead\x12\x19.gator.v1.MarkReadRequest\x1a\x1a.gator.v1.MarkReadResponseB0Z.github.com/necodeus/gator/api/gator/v1;gatorv1b\x06proto3"
+
+var (
+	file_api_gator_v1_gator_proto_rawDescOnce sync.Once
+	file_api_gator_v1_gator_proto_rawDescData []byte
+)
+
+func file_api_gator_v1_gator_proto_rawDescGZIP() []byte {
+	file_api_gator_v1_gator_proto_rawDescOnce.Do(func() {
+		file_api_gator_v1_gator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_gator_v1_gator_proto_rawDesc), len(file_api_gator_v1_gator_proto_rawDesc)))
+	})
+	return file_api_gator_v1_gator_proto_rawDescData
+}
+
+var file_api_gator_v1_gator_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_api_gator_v1_gator_proto_goTypes = []any{
+	(*Feed)(nil),                  // 0: gator.v1.Feed
+	(*Post)(nil),                  // 1: gator.v1.Post
+	(*ListFeedsRequest)(nil),      // 2: gator.v1.ListFeedsRequest
+	(*ListFeedsResponse)(nil),     // 3: gator.v1.ListFeedsResponse
+	(*FollowFeedRequest)(nil),     // 4: gator.v1.FollowFeedRequest
+	(*FollowFeedResponse)(nil),    // 5: gator.v1.FollowFeedResponse
+	(*UnfollowFeedRequest)(nil),   // 6: gator.v1.UnfollowFeedRequest
+	(*UnfollowFeedResponse)(nil),  // 7: gator.v1.UnfollowFeedResponse
+	(*BrowseRequest)(nil),         // 8: gator.v1.BrowseRequest
+	(*BrowseResponse)(nil),        // 9: gator.v1.BrowseResponse
+	(*MarkReadRequest)(nil),       // 10: gator.v1.MarkReadRequest
+	(*MarkReadResponse)(nil),      // 11: gator.v1.MarkReadResponse
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_api_gator_v1_gator_proto_depIdxs = []int32{
+	12, // 0: gator.v1.Feed.last_fetched_at:type_name -> google.protobuf.Timestamp
+	12, // 1: gator.v1.Post.published_at:type_name -> google.protobuf.Timestamp
+	12, // 2: gator.v1.Post.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 3: gator.v1.ListFeedsResponse.feeds:type_name -> gator.v1.Feed
+	0,  // 4: gator.v1.FollowFeedResponse.feed:type_name -> gator.v1.Feed
+	1,  // 5: gator.v1.BrowseResponse.posts:type_name -> gator.v1.Post
+	2,  // 6: gator.v1.GatorService.ListFeeds:input_type -> gator.v1.ListFeedsRequest
+	4,  // 7: gator.v1.GatorService.FollowFeed:input_type -> gator.v1.FollowFeedRequest
+	6,  // 8: gator.v1.GatorService.UnfollowFeed:input_type -> gator.v1.UnfollowFeedRequest
+	8,  // 9: gator.v1.GatorService.Browse:input_type -> gator.v1.BrowseRequest
+	10, // 10: gator.v1.GatorService.MarkRead:input_type -> gator.v1.MarkReadRequest
+	3,  // 11: gator.v1.GatorService.ListFeeds:output_type -> gator.v1.ListFeedsResponse
+	5,  // 12: gator.v1.GatorService.FollowFeed:output_type -> gator.v1.FollowFeedResponse
+	7,  // 13: gator.v1.GatorService.UnfollowFeed:output_type -> gator.v1.UnfollowFeedResponse
+	9,  // 14: gator.v1.GatorService.Browse:output_type -> gator.v1.BrowseResponse
+	11, // 15: gator.v1.GatorService.MarkRead:output_type -> gator.v1.MarkReadResponse
+	11, // [11:16] is the sub-list for method output_type
+	6,  // [6:11] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_api_gator_v1_gator_proto_init() }
+func file_api_gator_v1_gator_proto_init() {
+	if File_api_gator_v1_gator_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_gator_v1_gator_proto_rawDesc), len(file_api_gator_v1_gator_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_gator_v1_gator_proto_goTypes,
+		DependencyIndexes: file_api_gator_v1_gator_proto_depIdxs,
+		MessageInfos:      file_api_gator_v1_gator_proto_msgTypes,
+	}.Build()
+	File_api_gator_v1_gator_proto = out.File
+	file_api_gator_v1_gator_proto_goTypes = nil
+	file_api_gator_v1_gator_proto_depIdxs = nil
+}
@@ -0,0 +1,292 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v5.29.3
+// source: api/gator/v1/gator.proto
+
+// Package gator.v1 is the gRPC API of a gator instance, served by
+// `gator serve --grpc <addr>`.
+//
+// Calls act for one user. In multi-user mode they authenticate with an
+// "authorization: Bearer <session token>" metadata entry, the token coming
+// from POST /api/login; otherwise they act for the configured user.
+
+package gatorv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GatorService_ListFeeds_FullMethodName    = "/gator.v1.GatorService/ListFeeds"
+	GatorService_FollowFeed_FullMethodName   = "/gator.v1.GatorService/FollowFeed"
+	GatorService_UnfollowFeed_FullMethodName = "/gator.v1.GatorService/UnfollowFeed"
+	GatorService_Browse_FullMethodName       = "/gator.v1.GatorService/Browse"
+	GatorService_MarkRead_FullMethodName     = "/gator.v1.GatorService/MarkRead"
+)
+
+// GatorServiceClient is the client API for GatorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GatorServiceClient interface {
+	// ListFeeds lists every feed gator stores, marking those the user
+	// follows and counting the users who follow each.
+	ListFeeds(ctx context.Context, in *ListFeedsRequest, opts ...grpc.CallOption) (*ListFeedsResponse, error)
+	// FollowFeed follows a stored feed, named by URL or name.
+	FollowFeed(ctx context.Context, in *FollowFeedRequest, opts ...grpc.CallOption) (*FollowFeedResponse, error)
+	// UnfollowFeed stops following a feed.
+	UnfollowFeed(ctx context.Context, in *UnfollowFeedRequest, opts ...grpc.CallOption) (*UnfollowFeedResponse, error)
+	// Browse returns the newest posts from the feeds the user follows.
+	Browse(ctx context.Context, in *BrowseRequest, opts ...grpc.CallOption) (*BrowseResponse, error)
+	// MarkRead marks a post as read for the user.
+	MarkRead(ctx context.Context, in *MarkReadRequest, opts ...grpc.CallOption) (*MarkReadResponse, error)
+}
+
+type gatorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGatorServiceClient(cc grpc.ClientConnInterface) GatorServiceClient {
+	return &gatorServiceClient{cc}
+}
+
+func (c *gatorServiceClient) ListFeeds(ctx context.Context, in *ListFeedsRequest, opts ...grpc.CallOption) (*ListFeedsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFeedsResponse)
+	err := c.cc.Invoke(ctx, GatorService_ListFeeds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatorServiceClient) FollowFeed(ctx context.Context, in *FollowFeedRequest, opts ...grpc.CallOption) (*FollowFeedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FollowFeedResponse)
+	err := c.cc.Invoke(ctx, GatorService_FollowFeed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatorServiceClient) UnfollowFeed(ctx context.Context, in *UnfollowFeedRequest, opts ...grpc.CallOption) (*UnfollowFeedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnfollowFeedResponse)
+	err := c.cc.Invoke(ctx, GatorService_UnfollowFeed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatorServiceClient) Browse(ctx context.Context, in *BrowseRequest, opts ...grpc.CallOption) (*BrowseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BrowseResponse)
+	err := c.cc.Invoke(ctx, GatorService_Browse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatorServiceClient) MarkRead(ctx context.Context, in *MarkReadRequest, opts ...grpc.CallOption) (*MarkReadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MarkReadResponse)
+	err := c.cc.Invoke(ctx, GatorService_MarkRead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GatorServiceServer is the server API for GatorService service.
+// All implementations must embed UnimplementedGatorServiceServer
+// for forward compatibility.
+type GatorServiceServer interface {
+	// ListFeeds lists every feed gator stores, marking those the user
+	// follows and counting the users who follow each.
+	ListFeeds(context.Context, *ListFeedsRequest) (*ListFeedsResponse, error)
+	// FollowFeed follows a stored feed, named by URL or name.
+	FollowFeed(context.Context, *FollowFeedRequest) (*FollowFeedResponse, error)
+	// UnfollowFeed stops following a feed.
+	UnfollowFeed(context.Context, *UnfollowFeedRequest) (*UnfollowFeedResponse, error)
+	// Browse returns the newest posts from the feeds the user follows.
+	Browse(context.Context, *BrowseRequest) (*BrowseResponse, error)
+	// MarkRead marks a post as read for the user.
+	MarkRead(context.Context, *MarkReadRequest) (*MarkReadResponse, error)
+	mustEmbedUnimplementedGatorServiceServer()
+}
+
+// UnimplementedGatorServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGatorServiceServer struct{}
+
+func (UnimplementedGatorServiceServer) ListFeeds(context.Context, *ListFeedsRequest) (*ListFeedsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListFeeds not implemented")
+}
+func (UnimplementedGatorServiceServer) FollowFeed(context.Context, *FollowFeedRequest) (*FollowFeedResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FollowFeed not implemented")
+}
+func (UnimplementedGatorServiceServer) UnfollowFeed(context.Context, *UnfollowFeedRequest) (*UnfollowFeedResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UnfollowFeed not implemented")
+}
+func (UnimplementedGatorServiceServer) Browse(context.Context, *BrowseRequest) (*BrowseResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Browse not implemented")
+}
+func (UnimplementedGatorServiceServer) MarkRead(context.Context, *MarkReadRequest) (*MarkReadResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method MarkRead not implemented")
+}
+func (UnimplementedGatorServiceServer) mustEmbedUnimplementedGatorServiceServer() {}
+func (UnimplementedGatorServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeGatorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GatorServiceServer will
+// result in compilation errors.
+type UnsafeGatorServiceServer interface {
+	mustEmbedUnimplementedGatorServiceServer()
+}
+
+func RegisterGatorServiceServer(s grpc.ServiceRegistrar, srv GatorServiceServer) {
+	// If the following call panics, it indicates UnimplementedGatorServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GatorService_ServiceDesc, srv)
+}
+
+func _GatorService_ListFeeds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFeedsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatorServiceServer).ListFeeds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatorService_ListFeeds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatorServiceServer).ListFeeds(ctx, req.(*ListFeedsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatorService_FollowFeed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowFeedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatorServiceServer).FollowFeed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatorService_FollowFeed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatorServiceServer).FollowFeed(ctx, req.(*FollowFeedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatorService_UnfollowFeed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnfollowFeedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatorServiceServer).UnfollowFeed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatorService_UnfollowFeed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatorServiceServer).UnfollowFeed(ctx, req.(*UnfollowFeedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatorService_Browse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BrowseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatorServiceServer).Browse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatorService_Browse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatorServiceServer).Browse(ctx, req.(*BrowseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatorService_MarkRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatorServiceServer).MarkRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatorService_MarkRead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatorServiceServer).MarkRead(ctx, req.(*MarkReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GatorService_ServiceDesc is the grpc.ServiceDesc for GatorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GatorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gator.v1.GatorService",
+	HandlerType: (*GatorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListFeeds",
+			Handler:    _GatorService_ListFeeds_Handler,
+		},
+		{
+			MethodName: "FollowFeed",
+			Handler:    _GatorService_FollowFeed_Handler,
+		},
+		{
+			MethodName: "UnfollowFeed",
+			Handler:    _GatorService_UnfollowFeed_Handler,
+		},
+		{
+			MethodName: "Browse",
+			Handler:    _GatorService_Browse_Handler,
+		},
+		{
+			MethodName: "MarkRead",
+			Handler:    _GatorService_MarkRead_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/gator/v1/gator.proto",
+}
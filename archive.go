@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// maxArchiveImages caps how many images one archived page pulls in.
+	maxArchiveImages = 100
+	// maxArchiveFileSize caps the page and each image.
+	maxArchiveFileSize = 20 << 20
+)
+
+var (
+	imgTagPattern    = regexp.MustCompile(`(?is)<img\b[^>]*>`)
+	imgSrcPattern    = regexp.MustCompile(`(?is)(\ssrc\s*=\s*)("[^"]*"|'[^']*')`)
+	imgSrcsetPattern = regexp.MustCompile(`(?is)\s+srcset\s*=\s*("[^"]*"|'[^']*')`)
+)
+
+// handlerArchive saves a post's page and the images it shows under the
+// data directory, with the images pointed at the local copies.
+func handlerArchive(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return usageErrorf("archive command requires a post ID or a number from the last listing")
+	}
+
+	postID, err := resolvePostRef(s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	post, err := s.db.GetPostById(dbCtx, postID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundErrorf("post %s does not exist", postID)
+		}
+		return fmt.Errorf("failed to get post: %v", err)
+	}
+
+	dataDir, err := s.Config.DataDirPath()
+	if err != nil {
+		return fmt.Errorf("failed to locate data directory: %v", err)
+	}
+	dir := filepath.Join(dataDir, "archive", post.ID.String())
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %v", err)
+	}
+
+	pageURL, err := url.Parse(post.Url)
+	if err != nil {
+		return fmt.Errorf("invalid post URL %s: %v", post.Url, err)
+	}
+
+	page, _, err := fetchForArchive(ctx, s, post.Url)
+	if err != nil {
+		return networkError(err, fmt.Errorf("failed to download %s: %v", post.Url, err))
+	}
+
+	// download every distinct image once, in page order
+	local := make(map[string]string)
+	for _, tag := range imgTagPattern.FindAllString(string(page), -1) {
+		match := imgSrcPattern.FindStringSubmatch(tag)
+		if match == nil {
+			continue
+		}
+		src := html.UnescapeString(strings.Trim(match[2], `"'`))
+		if _, seen := local[src]; seen || strings.HasPrefix(src, "data:") {
+			continue
+		}
+		if len(local) >= maxArchiveImages {
+			fmt.Printf("Stopping after %d images\n", maxArchiveImages)
+			break
+		}
+
+		local[src] = ""
+		name, err := archiveImage(ctx, s, pageURL, src, dir, len(local))
+		if err != nil {
+			fmt.Printf("Error saving image %s: %v\n", src, err)
+			continue
+		}
+		local[src] = name
+	}
+
+	rewritten := imgTagPattern.ReplaceAllStringFunc(string(page), func(tag string) string {
+		match := imgSrcPattern.FindStringSubmatch(tag)
+		if match == nil {
+			return tag
+		}
+		name := local[html.UnescapeString(strings.Trim(match[2], `"'`))]
+		if name == "" {
+			return tag
+		}
+		// srcset would win over the local src, so drop it
+		tag = imgSrcsetPattern.ReplaceAllString(tag, "")
+		return imgSrcPattern.ReplaceAllLiteralString(tag, match[1]+`"`+name+`"`)
+	})
+
+	target := filepath.Join(dir, "index.html")
+	if err := os.WriteFile(target, []byte(rewritten), 0o644); err != nil {
+		return fmt.Errorf("failed to save page: %v", err)
+	}
+
+	saved := 0
+	for _, name := range local {
+		if name != "" {
+			saved++
+		}
+	}
+	infof("Archived %s with %d images to %s\n", post.Title, saved, target)
+
+	return nil
+}
+
+// archiveImage saves the image at src, relative to the page, into dir as
+// img-<n> with an extension matching its type, returning the file name.
+func archiveImage(ctx context.Context, s *state, pageURL *url.URL, src, dir string, n int) (string, error) {
+	ref, err := url.Parse(src)
+	if err != nil {
+		return "", err
+	}
+	imageURL := pageURL.ResolveReference(ref)
+
+	data, contentType, err := fetchForArchive(ctx, s, imageURL.String())
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("img-%d%s", n, imageExtension(imageURL.Path, contentType))
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// fetchForArchive downloads rawURL, up to maxArchiveFileSize, returning
+// the body and its Content-Type.
+func fetchForArchive(ctx context.Context, s *state, rawURL string) ([]byte, string, error) {
+	if err := s.hosts.Wait(ctx, rawURL); err != nil {
+		return nil, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", s.userAgent(nil))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("bad response status: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArchiveFileSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxArchiveFileSize {
+		return nil, "", fmt.Errorf("larger than %s", formatBytes(maxArchiveFileSize))
+	}
+
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+var preferredImageExtensions = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+	"image/avif":    ".avif",
+}
+
+// imageExtension keeps the extension from the image's URL when it agrees
+// with the Content-Type, and otherwise picks one from the type.
+func imageExtension(urlPath, contentType string) string {
+	ext := path.Ext(urlPath)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ext
+	}
+
+	if ext != "" && strings.HasPrefix(mime.TypeByExtension(ext), mediaType) {
+		return ext
+	}
+	if preferred, ok := preferredImageExtensions[mediaType]; ok {
+		return preferred
+	}
+	if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ext
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+// atomFeed is an Atom document, which gator reads into the same RSSFeed
+// shape as RSS so the rest of the pipeline sees no difference. YouTube
+// publishes its channel and playlist feeds only as Atom.
+type atomFeed struct {
+	Title    string      `xml:"http://www.w3.org/2005/Atom title"`
+	Subtitle string      `xml:"http://www.w3.org/2005/Atom subtitle"`
+	Links    []AtomLink  `xml:"http://www.w3.org/2005/Atom link"`
+	Author   atomPerson  `xml:"http://www.w3.org/2005/Atom author"`
+	Icon     string      `xml:"http://www.w3.org/2005/Atom icon"`
+	Entries  []atomEntry `xml:"http://www.w3.org/2005/Atom entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"http://www.w3.org/2005/Atom name"`
+}
+
+type atomEntry struct {
+	ID         string      `xml:"http://www.w3.org/2005/Atom id"`
+	Title      string      `xml:"http://www.w3.org/2005/Atom title"`
+	Links      []AtomLink  `xml:"http://www.w3.org/2005/Atom link"`
+	Published  string      `xml:"http://www.w3.org/2005/Atom published"`
+	Updated    string      `xml:"http://www.w3.org/2005/Atom updated"`
+	Summary    string      `xml:"http://www.w3.org/2005/Atom summary"`
+	Content    string      `xml:"http://www.w3.org/2005/Atom content"`
+	Author     atomPerson  `xml:"http://www.w3.org/2005/Atom author"`
+	MediaGroup *MediaGroup `xml:"http://search.yahoo.com/mrss/ group"`
+
+	// yt:videoId, which YouTube entries carry alongside their link
+	VideoID string `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
+}
+
+// rootElement skips to the document's first element.
+func rootElement(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return xml.StartElement{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+// alternateLink picks the link an Atom element points readers to: the
+// rel="alternate" one, which is also what a link without rel means.
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+func (a atomFeed) toRSS() RSSFeed {
+	var feed RSSFeed
+	feed.Channel.Title = a.Title
+	feed.Channel.Description = a.Subtitle
+	feed.Channel.Link = alternateLink(a.Links)
+	feed.Channel.AtomLinks = a.Links
+	feed.Channel.Author = a.Author.Name
+	if a.Icon != "" {
+		feed.Channel.Image = &ITunesImage{Href: a.Icon}
+	}
+
+	for _, entry := range a.Entries {
+		item := RSSItem{
+			Title:       entry.Title,
+			Link:        alternateLink(entry.Links),
+			GUID:        entry.ID,
+			Description: entry.Summary,
+			PubDate:     entry.Published,
+			Author:      entry.Author.Name,
+			MediaGroup:  entry.MediaGroup,
+		}
+		if item.Description == "" {
+			item.Description = entry.Content
+		}
+		if item.Description == "" && entry.MediaGroup != nil {
+			item.Description = strings.TrimSpace(entry.MediaGroup.Description)
+		}
+		if item.PubDate == "" {
+			item.PubDate = entry.Updated
+		}
+
+		if entry.VideoID != "" {
+			if item.Link == "" {
+				item.Link = youtubeWatchURL(entry.VideoID)
+			}
+			if item.ThumbnailURL() == "" {
+				item.Thumbnails = []MediaThumbnail{{URL: youtubeThumbnailURL(entry.VideoID)}}
+			}
+		}
+
+		feed.Channel.Item = append(feed.Channel.Item, item)
+	}
+
+	return feed
+}
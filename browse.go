@@ -0,0 +1,545 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+const defaultBrowseLimit = 10
+
+// browseSorts are the orders browse --sort accepts; published is the
+// default. Names sort A to Z and everything else newest or biggest first,
+// unless --asc or --desc says otherwise.
+var browseSorts = []string{"published", "added", "feed", "title", "points", "comments", "score"}
+
+// postOrder checks a sort key, taking date as the old name for published,
+// and reports whether it sorts descending by default.
+func postOrder(sortBy string) (string, bool, error) {
+	if sortBy == "date" {
+		sortBy = "published"
+	}
+	if !slices.Contains(browseSorts, sortBy) {
+		return "", false, fmt.Errorf("unknown sort %s, expected one of %s", sortBy, strings.Join(browseSorts, ", "))
+	}
+	return sortBy, sortBy != "feed" && sortBy != "title", nil
+}
+
+func handlerBrowse(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("browse", flag.ContinueOnError)
+	sortBy := fs.String("sort", "published", "order posts by "+strings.Join(browseSorts, ", "))
+	asc := fs.Bool("asc", false, "sort in ascending order")
+	desc := fs.Bool("desc", false, "sort in descending order")
+	edited := fs.Bool("edited", false, "list posts the feed has edited since they were stored, latest edit first")
+	folderName := fs.String("folder", "", "only list posts in this folder")
+	pickOne := fs.Bool("pick", false, "pick a post to open from a filterable list instead of printing them")
+	absolute := fs.Bool("absolute", false, "show full dates instead of how long ago posts were published")
+	groupBy := fs.String("group-by", "", "list posts under headings by "+strings.Join(browseGroups, " or "))
+	templateText := fs.String("template", "", "Go template each post is printed with, e.g. '{{.FeedName}}: {{.Title}}'")
+	table := addTableFlags(fs)
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	sortKey, descending, err := postOrder(*sortBy)
+	if err != nil {
+		return usageError(err)
+	}
+	if *asc && *desc {
+		return usageErrorf("--asc and --desc can't be combined")
+	}
+	if *asc || *desc {
+		descending = *desc
+	}
+
+	out, err := newStoryOutput(s, *templateText, table)
+	if err != nil {
+		return err
+	}
+	out.absolute = *absolute
+	if *groupBy != "" {
+		if !slices.Contains(browseGroups, *groupBy) {
+			return usageErrorf("unknown group %s, expected one of %s", *groupBy, strings.Join(browseGroups, ", "))
+		}
+		if out.tmpl != nil || out.table != nil || *pickOne {
+			return usageErrorf("--group-by only applies to the usual listing, not --template, --format or --pick")
+		}
+		out.groupBy = *groupBy
+	}
+	if out.table != nil && *pickOne {
+		return usageErrorf("--pick can't be combined with --format %s", *table.format)
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	limit, err := browseLimit(ctx, s, user)
+	if err != nil {
+		return err
+	}
+	if *pickOne {
+		limit = pickLimit
+	}
+	if fs.NArg() > 0 {
+		n, err := strconv.Atoi(fs.Arg(0))
+		if err != nil || n <= 0 {
+			return usageErrorf("browse limit must be a positive number")
+		}
+		limit = n
+	}
+
+	loc := userLocation(user)
+
+	if *pickOne && !canPick() {
+		return usageErrorf("browse --pick needs a terminal")
+	}
+	if *edited {
+		if *folderName != "" {
+			return usageErrorf("--edited can't be combined with --folder")
+		}
+		return browseEdited(ctx, s, user, limit, loc, out)
+	}
+
+	var folder sql.NullString
+	if *folderName != "" {
+		f, err := getFolder(ctx, s, user, *folderName)
+		if err != nil {
+			return err
+		}
+		folder = sql.NullString{String: f.Name, Valid: true}
+	}
+
+	posts, err := s.db.GetPostsForUser(ctx, database.GetPostsForUserParams{
+		UserID:     user.ID,
+		Folder:     folder,
+		SortBy:     sortKey,
+		Descending: descending,
+		MaxPosts:   int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get posts: %v", err)
+	}
+
+	stories := collapseDuplicates(posts)
+	if *pickOne {
+		return browsePick(ctx, s, user, stories, loc)
+	}
+	if err := out.show(s, stories, loc); err != nil {
+		return err
+	}
+
+	return markSeen(ctx, s, user)
+}
+
+// browsePick opens the story the user picks. The user counts as having
+// seen the posts before picking, since the picker may well outlast the
+// command's timeout.
+func browsePick(ctx context.Context, s *state, user database.User, stories []story, loc *time.Location) error {
+	if len(stories) == 0 {
+		fmt.Println("No posts")
+		return nil
+	}
+	if err := markSeen(ctx, s, user); err != nil {
+		return err
+	}
+
+	rows := make([]database.GetPostsForUserRow, 0, len(stories))
+	for _, st := range stories {
+		rows = append(rows, st.Row)
+	}
+	post, err := pickPost(rows, loc)
+	if err != nil {
+		return err
+	}
+
+	if err := openBrowser(post.Url); err != nil {
+		return fmt.Errorf("failed to open %s: %v", post.Url, err)
+	}
+	infof("Opened %s\n", post.Url)
+	return nil
+}
+
+// browseEdited lists the posts a feed went back and changed, so
+// corrections don't go unnoticed.
+func browseEdited(ctx context.Context, s *state, user database.User, limit int, loc *time.Location, out storyOutput) error {
+	rows, err := s.db.GetEditedPostsForUser(ctx, database.GetEditedPostsForUserParams{
+		UserID:   user.ID,
+		MaxPosts: int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get edited posts: %v", err)
+	}
+	if len(rows) == 0 && out.table == nil {
+		fmt.Println("No edited posts")
+		return nil
+	}
+
+	stories := make([]story, 0, len(rows))
+	for _, row := range rows {
+		stories = append(stories, story{Row: database.GetPostsForUserRow{Post: row.Post, FeedName: row.FeedName}})
+	}
+	return out.show(s, stories, loc)
+}
+
+// storyOutput is how browse prints its posts: as the usual listing, with
+// a template, or as a CSV or TSV table.
+type storyOutput struct {
+	tmpl    *template.Template
+	table   *tableFlags
+	columns []tableColumn[postView]
+	// absolute shows full dates in the usual listing instead of "3h ago",
+	// and groupBy puts it under headings
+	absolute bool
+	groupBy  string
+}
+
+// newStoryOutput works out the output from the flags. Without --format
+// or --template, browse_template from the config applies.
+func newStoryOutput(s *state, templateText string, table tableFlags) (storyOutput, error) {
+	tabular, err := table.tabular()
+	if err != nil {
+		return storyOutput{}, err
+	}
+	if tabular {
+		if templateText != "" {
+			return storyOutput{}, usageErrorf("--template can't be combined with --format %s", *table.format)
+		}
+		columns, err := selectColumns(table, postColumns, []string{"published_at", "feed", "title", "url"})
+		if err != nil {
+			return storyOutput{}, err
+		}
+		return storyOutput{table: &table, columns: columns}, nil
+	}
+
+	if templateText == "" {
+		templateText = s.Config.BrowseTemplate
+	}
+	if templateText == "" {
+		return storyOutput{}, nil
+	}
+	if !strings.HasSuffix(templateText, "\n") {
+		templateText += "\n"
+	}
+
+	tmpl, err := template.New("browse").Parse(templateText)
+	if err != nil {
+		return storyOutput{}, usageErrorf("invalid browse template: %v", err)
+	}
+	return storyOutput{tmpl: tmpl}, nil
+}
+
+// postView is what a browse template or table sees of each post. Times
+// are in the user's timezone and zero when unknown; Score and Comments
+// are zero for sources that don't report them. Note is the user's note on
+// the post, if any.
+type postView struct {
+	Index       int
+	ID          uuid.UUID
+	FeedName    string
+	Title       string
+	URL         string
+	Description string
+	Author      string
+	PublishedAt time.Time
+	EditedAt    time.Time
+	Score       int32
+	Comments    int32
+	AlsoIn      []string
+	Note        string
+}
+
+func newPostView(index int, st story, loc *time.Location) postView {
+	post := st.Row.Post
+	view := postView{
+		Index:       index,
+		ID:          post.ID,
+		FeedName:    st.Row.FeedName,
+		Title:       post.Title,
+		URL:         post.Url,
+		Description: post.Description.String,
+		Author:      post.Author.String,
+		Score:       post.Score.Int32,
+		Comments:    post.CommentCount.Int32,
+		AlsoIn:      st.AlsoFeeds,
+		Note:        st.Row.Note.String,
+	}
+	if post.PublishedAt.Valid {
+		view.PublishedAt = post.PublishedAt.Time.In(loc)
+	}
+	if post.EditedAt.Valid {
+		view.EditedAt = post.EditedAt.Time.In(loc)
+	}
+	return view
+}
+
+var postColumns = []tableColumn[postView]{
+	{"index", func(v postView) string { return strconv.Itoa(v.Index) }},
+	{"id", func(v postView) string { return v.ID.String() }},
+	{"feed", func(v postView) string { return v.FeedName }},
+	{"title", func(v postView) string { return v.Title }},
+	{"url", func(v postView) string { return v.URL }},
+	{"description", func(v postView) string { return v.Description }},
+	{"author", func(v postView) string { return v.Author }},
+	{"published_at", func(v postView) string { return tableTime(v.PublishedAt, !v.PublishedAt.IsZero()) }},
+	{"edited_at", func(v postView) string { return tableTime(v.EditedAt, !v.EditedAt.IsZero()) }},
+	{"score", func(v postView) string { return strconv.Itoa(int(v.Score)) }},
+	{"comments", func(v postView) string { return strconv.Itoa(int(v.Comments)) }},
+	{"also_in", func(v postView) string { return strings.Join(v.AlsoIn, ",") }},
+	{"note", func(v postView) string { return v.Note }},
+}
+
+// show prints stories and remembers them, so `gator open <n>` can refer
+// to the posts by number whichever way they were printed.
+func (o storyOutput) show(s *state, stories []story, loc *time.Location) error {
+	if o.tmpl == nil && o.table == nil {
+		if o.groupBy != "" {
+			printGroupedStories(s, stories, o.groupBy, loc, o.absolute)
+		} else {
+			printStories(s, stories, loc, o.absolute)
+		}
+		return nil
+	}
+
+	views := make([]postView, 0, len(stories))
+	ids := make([]uuid.UUID, 0, len(stories))
+	for i, st := range stories {
+		views = append(views, newPostView(i+1, st, loc))
+		ids = append(ids, st.Row.Post.ID)
+	}
+
+	if o.table != nil {
+		if err := writeTable(*o.table, o.columns, views); err != nil {
+			return err
+		}
+	} else {
+		for _, view := range views {
+			if err := o.tmpl.Execute(os.Stdout, view); err != nil {
+				return fmt.Errorf("failed to print post %s: %v", view.ID, err)
+			}
+		}
+	}
+
+	if err := saveListing(s, ids); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving listing: %v\n", err)
+	}
+	return nil
+}
+
+// printStories prints a numbered listing and remembers it, so
+// `gator open <n>` can refer to the posts by number.
+func printStories(s *state, stories []story, loc *time.Location, absolute bool) {
+	ids := make([]uuid.UUID, 0, len(stories))
+	for i, st := range stories {
+		printStory(i+1, st, loc, absolute)
+		ids = append(ids, st.Row.Post.ID)
+	}
+
+	if err := saveListing(s, ids); err != nil {
+		fmt.Printf("Error saving listing: %v\n", err)
+	}
+}
+
+func printStory(index int, st story, loc *time.Location, absolute bool) {
+	printPost(index, st.Row.Post, st.Row.FeedName, loc, absolute)
+	if len(st.AlsoFeeds) > 0 {
+		fmt.Printf("  Also in: %s\n", strings.Join(st.AlsoFeeds, ", "))
+	}
+	if st.Row.Note.Valid {
+		for _, line := range strings.Split(st.Row.Note.String, "\n") {
+			fmt.Printf("  > %s\n", line)
+		}
+	}
+}
+
+// browseGroups are what browse --group-by can group posts by.
+var browseGroups = []string{"feed", "day"}
+
+type storyGroup struct {
+	heading string
+	stories []story
+}
+
+// groupStories splits stories by feed or by the day they were published
+// in loc. Groups come in the order their first story does, and stories
+// keep their order within a group.
+func groupStories(stories []story, by string, loc *time.Location) []storyGroup {
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	var groups []storyGroup
+	index := make(map[string]int)
+	for _, st := range stories {
+		heading := st.Row.FeedName
+		if by == "day" {
+			heading = "Unknown date"
+			if published := st.Row.Post.PublishedAt; published.Valid {
+				t := published.Time.In(loc)
+				switch day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc); {
+				case day.Equal(today):
+					heading = "Today"
+				case day.Equal(today.AddDate(0, 0, -1)):
+					heading = "Yesterday"
+				case day.Year() == today.Year():
+					heading = day.Format("Mon Jan 2")
+				default:
+					heading = day.Format("Mon Jan 2 2006")
+				}
+			}
+		}
+
+		i, ok := index[heading]
+		if !ok {
+			i = len(groups)
+			index[heading] = i
+			groups = append(groups, storyGroup{heading: heading})
+		}
+		groups[i].stories = append(groups[i].stories, st)
+	}
+	return groups
+}
+
+// printGroupedStories prints stories under a heading per group, with how
+// many posts each has. Posts are numbered through the whole listing, as
+// `gator open <n>` counts them.
+func printGroupedStories(s *state, stories []story, by string, loc *time.Location, absolute bool) {
+	ids := make([]uuid.UUID, 0, len(stories))
+	for i, group := range groupStories(stories, by, loc) {
+		if i > 0 {
+			fmt.Println()
+		}
+		noun := "posts"
+		if len(group.stories) == 1 {
+			noun = "post"
+		}
+		fmt.Printf("== %s (%d %s) ==\n", group.heading, len(group.stories), noun)
+		for _, st := range group.stories {
+			ids = append(ids, st.Row.Post.ID)
+			printStory(len(ids), st, loc, absolute)
+		}
+	}
+
+	if err := saveListing(s, ids); err != nil {
+		fmt.Printf("Error saving listing: %v\n", err)
+	}
+}
+
+func printPost(index int, post database.Post, feedName string, loc *time.Location, absolute bool) {
+	published := "unknown date"
+	if post.PublishedAt.Valid {
+		published = postDate(post.PublishedAt.Time, loc, absolute)
+	}
+
+	fmt.Printf("%d. [%s] %s\n", index, feedName, post.Title)
+	if episode := podcastSummary(post); episode != "" {
+		fmt.Printf("  %s\n", episode)
+	}
+	fmt.Printf("  %s | %s | %s\n", published, post.ID, post.Url)
+	if post.EditedAt.Valid {
+		fmt.Printf("  Edited %s\n", postDate(post.EditedAt.Time, loc, absolute))
+	}
+	if activity := engagementSummary(post); activity != "" {
+		fmt.Printf("  %s\n", activity)
+	}
+	if post.ThumbnailUrl.Valid {
+		fmt.Printf("  Image: %s\n", post.ThumbnailUrl.String)
+	}
+}
+
+// postDate shows when something happened to a post, as how long ago,
+// e.g. "3h ago", or with absolute as the full date in loc.
+func postDate(t time.Time, loc *time.Location, absolute bool) string {
+	if absolute {
+		return t.In(loc).Format("Mon Jan 2 2006 15:04 MST")
+	}
+	return relativeTime(t, time.Now(), loc)
+}
+
+// relativeTime renders how long before now t was, to the largest whole
+// unit: "just now", "5m ago", "3h ago" or "2d ago". Past a month it gives
+// the date in loc instead, which reads better than a count of days.
+func relativeTime(t, now time.Time, loc *time.Location) string {
+	d := now.Sub(t)
+	ahead := d < 0
+	if ahead {
+		// clock skew, or a feed dating posts in the future
+		d = -d
+	}
+
+	var ago string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		ago = fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		ago = fmt.Sprintf("%dh", int(d/time.Hour))
+	case d < 30*24*time.Hour:
+		ago = fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	default:
+		t = t.In(loc)
+		if t.Year() == now.In(loc).Year() {
+			return t.Format("Jan 2")
+		}
+		return t.Format("Jan 2 2006")
+	}
+
+	if ahead {
+		return "in " + ago
+	}
+	return ago + " ago"
+}
+
+// engagementSummary renders a post's score and comment count, e.g.
+// "412 points · 87 comments", for sources that report them.
+func engagementSummary(post database.Post) string {
+	var parts []string
+	if post.Score.Valid {
+		parts = append(parts, fmt.Sprintf("%d points", post.Score.Int32))
+	}
+	if post.CommentCount.Valid {
+		parts = append(parts, fmt.Sprintf("%d comments", post.CommentCount.Int32))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// podcastSummary renders the episode number and duration of podcast
+// posts, e.g. "S2E14 · 1:02:03"; it's empty for ordinary articles.
+func podcastSummary(post database.Post) string {
+	var parts []string
+
+	switch {
+	case post.Season.Valid && post.Episode.Valid:
+		parts = append(parts, fmt.Sprintf("S%dE%d", post.Season.Int32, post.Episode.Int32))
+	case post.Episode.Valid:
+		parts = append(parts, fmt.Sprintf("Episode %d", post.Episode.Int32))
+	}
+
+	if post.DurationSeconds.Valid {
+		parts = append(parts, formatDuration(post.DurationSeconds.Int32))
+	}
+
+	if post.Author.Valid {
+		parts = append(parts, post.Author.String)
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+func formatDuration(seconds int32) string {
+	h, m, sec := seconds/3600, seconds%3600/60, seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+	return fmt.Sprintf("%d:%02d", m, sec)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/necodeus/gator/internal/config"
+)
+
+// corsMaxAge is how long, in seconds, browsers may cache a preflight.
+const corsMaxAge = "600"
+
+// corsMethods are the methods the API uses.
+const corsMethods = "GET, POST, PUT, DELETE"
+
+// cors lets pages from other origins, such as a browser extension or a
+// separately hosted frontend, call serve. It answers preflights itself and
+// adds the CORS headers to every response to an allowed origin.
+type cors struct {
+	origins     []string
+	headers     string
+	credentials bool
+}
+
+// newCORS returns nil when no origins are configured, which leaves
+// cross-origin requests to the browser's same-origin policy. Credentials
+// can't be allowed for any origin: every site could then call the API as
+// whoever's browser visits it.
+func newCORS(cfg *config.Config) (*cors, error) {
+	if len(cfg.CORSOrigins) == 0 {
+		return nil, nil
+	}
+
+	headers := []string{"Authorization", "Content-Type"}
+	for _, header := range cfg.CORSHeaders {
+		header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+		if header != "" && !slices.Contains(headers, header) {
+			headers = append(headers, header)
+		}
+	}
+
+	origins := make([]string, 0, len(cfg.CORSOrigins))
+	for _, origin := range cfg.CORSOrigins {
+		origins = append(origins, strings.TrimRight(strings.TrimSpace(origin), "/"))
+	}
+	if cfg.CORSCredentials && slices.Contains(origins, "*") {
+		return nil, errors.New("cors_credentials needs cors_origins to list origins, not \"*\"")
+	}
+
+	return &cors{
+		origins:     origins,
+		headers:     strings.Join(headers, ", "),
+		credentials: cfg.CORSCredentials,
+	}, nil
+}
+
+func (c *cors) allowed(origin string) bool {
+	return slices.Contains(c.origins, "*") || slices.ContainsFunc(c.origins, func(o string) bool {
+		return strings.EqualFold(o, origin)
+	})
+}
+
+func (c *cors) handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if origin == "" || !c.allowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// newCORS allows credentials only for listed origins, never "*"
+		if slices.Contains(c.origins, "*") {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.credentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+			w.Header().Set("Access-Control-Allow-Headers", c.headers)
+			w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Access-Control-Expose-Headers", "Retry-After")
+		next.ServeHTTP(w, r)
+	})
+}
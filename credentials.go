@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+const (
+	feedAuthBasic  = "basic"
+	feedAuthBearer = "bearer"
+	feedAuthQuery  = "query"
+)
+
+// feedAuth is how a private feed proves who is asking: HTTP Basic
+// credentials, a bearer token, or a token passed as a query parameter.
+// It is stored in feeds.auth encrypted with the key in the data directory.
+type feedAuth struct {
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Param    string `json:"param,omitempty"`
+}
+
+// apply adds the credentials to req.
+func (a *feedAuth) apply(req *http.Request) {
+	if a == nil {
+		return
+	}
+
+	switch a.Type {
+	case feedAuthBasic:
+		req.SetBasicAuth(a.Username, a.Password)
+	case feedAuthBearer:
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	case feedAuthQuery:
+		query := req.URL.Query()
+		query.Set(a.Param, a.Token)
+		req.URL.RawQuery = query.Encode()
+	}
+}
+
+// strip removes a query token from u, so a URL the feed redirected to
+// can be stored without the secret in it.
+func (a *feedAuth) strip(u *url.URL) *url.URL {
+	if a == nil || a.Type != feedAuthQuery {
+		return u
+	}
+
+	stripped := *u
+	query := stripped.Query()
+	query.Del(a.Param)
+	stripped.RawQuery = query.Encode()
+	return &stripped
+}
+
+// String describes the credentials without giving away the secret part.
+func (a *feedAuth) String() string {
+	switch a.Type {
+	case feedAuthBasic:
+		return fmt.Sprintf("basic auth as %s", a.Username)
+	case feedAuthBearer:
+		return "a bearer token"
+	case feedAuthQuery:
+		return fmt.Sprintf("a token in the %s query parameter", a.Param)
+	default:
+		return a.Type
+	}
+}
+
+// feedAuthFor decrypts the credentials stored for feed, returning nil
+// when it has none.
+func (s *state) feedAuthFor(feed *database.Feed) (*feedAuth, error) {
+	if feed == nil || len(feed.Auth) == 0 {
+		return nil, nil
+	}
+
+	key, err := loadSecretKey(s, false)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := decryptSecret(key, feed.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials for %s: %v", feed.Name, err)
+	}
+
+	var auth feedAuth
+	if err := json.Unmarshal(plaintext, &auth); err != nil {
+		return nil, fmt.Errorf("stored credentials for %s are corrupt: %v", feed.Name, err)
+	}
+
+	return &auth, nil
+}
+
+// loadSecretKey reads the 256-bit key credentials are encrypted with. With
+// create set, a missing key file is generated; the key never leaves this
+// machine, so losing it means setting the credentials again.
+func loadSecretKey(s *state, create bool) ([]byte, error) {
+	path, err := s.Config.SecretKeyFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("secret key %s is not 32 bytes long", path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) || !create {
+		return nil, fmt.Errorf("failed to read secret key: %v", err)
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate secret key: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %v", err)
+	}
+	// O_EXCL so two commands racing to create the key can't both win
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if os.IsExist(err) {
+			return loadSecretKey(s, false)
+		}
+		return nil, fmt.Errorf("failed to save secret key: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(key); err != nil {
+		return nil, fmt.Errorf("failed to save secret key: %v", err)
+	}
+
+	return key, nil
+}
+
+// encryptSecret seals plaintext with AES-GCM, prefixing the random nonce.
+func encryptSecret(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decryptSecret(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// feedSetAuth stores the credentials a private feed is fetched with:
+//
+//	feed set-auth <feed> basic <username> [password]
+//	feed set-auth <feed> bearer [token]
+//	feed set-auth <feed> query <param> [token]
+//	feed set-auth <feed> none
+//
+// A password or token left off the command line is read from stdin, so it
+// stays out of the shell history.
+func feedSetAuth(ctx context.Context, s *state, args []string) error {
+	if len(args) < 2 {
+		return usageErrorf("feed set-auth requires a feed and basic, bearer, query or none")
+	}
+
+	// checked before asking for a secret that would only be turned away
+	ownerCtx, cancelOwner := context.WithTimeout(ctx, s.dbTimeout)
+	feed, err := exactFeed(ownerCtx, s, args[0])
+	if err == nil {
+		err = requireFeedOwner(ownerCtx, s, feed)
+	}
+	cancelOwner()
+	if err != nil {
+		return err
+	}
+
+	var auth *feedAuth
+	switch kind, rest := strings.ToLower(args[1]), args[2:]; kind {
+	case "none":
+	case feedAuthBasic:
+		if len(rest) == 0 {
+			return usageErrorf("basic auth requires a username")
+		}
+		auth = &feedAuth{Type: kind, Username: rest[0]}
+		if len(rest) > 1 {
+			auth.Password = rest[1]
+		} else if password, err := readSecret("Password"); err != nil {
+			return err
+		} else {
+			auth.Password = password
+		}
+	case feedAuthBearer:
+		auth = &feedAuth{Type: kind}
+		if len(rest) > 0 {
+			auth.Token = rest[0]
+		} else if token, err := readSecret("Token"); err != nil {
+			return err
+		} else {
+			auth.Token = token
+		}
+	case feedAuthQuery:
+		if len(rest) == 0 {
+			return usageErrorf("query auth requires the name of the query parameter")
+		}
+		auth = &feedAuth{Type: kind, Param: rest[0]}
+		if len(rest) > 1 {
+			auth.Token = rest[1]
+		} else if token, err := readSecret("Token"); err != nil {
+			return err
+		} else {
+			auth.Token = token
+		}
+	default:
+		return usageErrorf("unknown auth type %s, expected basic, bearer, query or none", args[1])
+	}
+
+	var sealed []byte
+	if auth != nil {
+		key, err := loadSecretKey(s, true)
+		if err != nil {
+			return err
+		}
+		plaintext, err := json.Marshal(auth)
+		if err != nil {
+			return err
+		}
+		if sealed, err = encryptSecret(key, plaintext); err != nil {
+			return fmt.Errorf("failed to encrypt credentials: %v", err)
+		}
+	}
+
+	// reading the secret may have waited on the user, so the timeout
+	// starts here
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	if err := s.db.SetFeedAuth(ctx, database.SetFeedAuthParams{
+		ID:   feed.ID,
+		Auth: sealed,
+	}); err != nil {
+		return fmt.Errorf("failed to update feed: %v", err)
+	}
+
+	if auth == nil {
+		infof("%s is now fetched without credentials\n", feed.Name)
+	} else {
+		infof("%s is now fetched with %s\n", feed.Name, auth)
+	}
+
+	return nil
+}
+
+// readSecret reads one line from stdin, prompting when it is a terminal
+// and keeping what is typed off the screen.
+func readSecret(prompt string) (string, error) {
+	if stdinIsTerminal() {
+		fmt.Printf("%s: ", prompt)
+		restore, err := hideInput()
+		if err != nil {
+			return "", fmt.Errorf("can't hide what is typed (%v), pipe the %s in instead", err, strings.ToLower(prompt))
+		}
+		defer func() {
+			restore()
+			// the newline typed wasn't echoed either
+			fmt.Println()
+		}()
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	secret := strings.TrimRight(line, "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("no %s given", strings.ToLower(prompt))
+	}
+	return secret, nil
+}
@@ -0,0 +1,473 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/necodeus/gator/internal/config"
+)
+
+const daemonStopTimeout = 10 * time.Second
+
+func handlerDaemon(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return usageErrorf("daemon command requires a subcommand: start, run, stop, reload, status")
+	}
+
+	switch cmd.Args[0] {
+	case "start":
+		return daemonStart(s, cmd.Args[1:])
+	case "run":
+		return daemonRun(ctx, s, cmd.Args[1:])
+	case "stop":
+		return daemonStop(s)
+	case "reload":
+		return daemonReload(s)
+	case "status":
+		return daemonStatus(s)
+	default:
+		return usageErrorf("unknown daemon subcommand: %s", cmd.Args[0])
+	}
+}
+
+type daemonOptions struct {
+	every     time.Duration
+	serve     string
+	publicURL string
+	notify    bool
+	multiUser bool
+	grpc      string
+	logFile   bool
+	// everySet is whether --every was given, which daemon_every then
+	// doesn't override
+	everySet bool
+}
+
+// daemonFlags are shared by start, which passes them through untouched,
+// and run, which acts on them.
+func daemonFlags(name string) (*flag.FlagSet, *daemonOptions) {
+	opts := &daemonOptions{}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.DurationVar(&opts.every, "every", 5*time.Minute, "how often to check which feeds are due (default daemon_every from the config)")
+	fs.StringVar(&opts.serve, "serve", "", "also serve the feed on this address")
+	fs.StringVar(&opts.publicURL, "public-url", "", "with --serve, URL the server is reachable on; enables WebSub")
+	fs.BoolVar(&opts.multiUser, "multi-user", false, "with --serve, let every user with a password log in")
+	fs.StringVar(&opts.grpc, "grpc", "", "with --serve, also serve the gRPC API on this address")
+	fs.BoolVar(&opts.notify, "notify", false, "show a desktop notification when relevant posts arrive")
+	fs.BoolVar(&opts.logFile, "log-file", false, "log to the rotated log file in the data directory instead of stdout")
+	return fs, opts
+}
+
+// daemonStart re-runs gator as `daemon run --log-file` in a new session,
+// detached from the terminal. The child's own stdout and stderr are the
+// log file too, for anything it prints before taking the log over.
+func daemonStart(s *state, args []string) error {
+	fs, _ := daemonFlags("daemon start")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	pidPath, err := s.Config.PIDFilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate PID file: %v", err)
+	}
+	if pid, running := readPIDFile(pidPath); running {
+		return conflictErrorf("daemon is already running (pid %d)", pid)
+	}
+
+	logPath, err := s.Config.LogFilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate log file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %v", err)
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	defer logFile.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate gator executable: %v", err)
+	}
+
+	child := exec.Command(executable, append(append(globalFlags(), "daemon", "run", "--log-file"), args...)...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("failed to start daemon: %v", err)
+	}
+
+	infof("Daemon started (pid %d), logging to %s\n", child.Process.Pid, logPath)
+
+	return child.Process.Release()
+}
+
+// daemonRun runs the scheduler, and the feed server if asked for, in the
+// foreground until it is signalled. This is what init systems should run.
+func daemonRun(ctx context.Context, s *state, args []string) (err error) {
+	fs, daemonOpts := daemonFlags("daemon run")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "every" {
+			daemonOpts.everySet = true
+		}
+	})
+	if !daemonOpts.everySet {
+		every, err := s.Config.DaemonEveryDuration()
+		if err != nil {
+			return &exitCodeError{exitConfig, err}
+		}
+		daemonOpts.every = every
+	}
+	if daemonOpts.every <= 0 {
+		return usageErrorf("--every must be a positive duration")
+	}
+
+	if daemonOpts.notify {
+		sink, err := desktopSink()
+		if err != nil {
+			return err
+		}
+		if s.notifier == nil {
+			s.notifier = newNotifier(s.Config.NotifyMinScoreOrDefault())
+		}
+		s.notifier.add(sink)
+	}
+
+	if daemonOpts.logFile {
+		logFile, openErr := openRotatingLog(s)
+		if openErr != nil {
+			return openErr
+		}
+		defer logFile.Close()
+
+		restoreStderr := timestampOutput(&os.Stderr, logFile)
+		defer restoreStderr()
+		restoreStdout := timestampOutput(&os.Stdout, logFile)
+		defer restoreStdout()
+
+		// main reports the error once output is back on the terminal,
+		// which a detached daemon doesn't have
+		defer func() {
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+		}()
+	} else {
+		restore := timestampOutput(&os.Stdout, os.Stdout)
+		defer restore()
+	}
+
+	quiet, err := loadQuietHours(ctx, s)
+	if err != nil {
+		return err
+	}
+	if quiet != nil {
+		infof("Quiet hours %s\n", quiet)
+		if s.notifier != nil {
+			s.notifier.quiet = quiet.pausesNotifications
+		}
+	}
+
+	pidPath, err := s.Config.PIDFilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate PID file: %v", err)
+	}
+	if err := writePIDFile(pidPath); err != nil {
+		return err
+	}
+	defer os.Remove(pidPath)
+
+	infof("Daemon running (pid %d)\n", os.Getpid())
+
+	if addr := s.Config.PprofAddress(); addr != "" {
+		stopPprof, err := servePprof(addr)
+		if err != nil {
+			return err
+		}
+		defer stopPprof()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// set before serving starts, since the probes read it
+	s.scheduler = newSchedulerHealth(daemonOpts.every)
+
+	serveErr := make(chan error, 1)
+	if daemonOpts.serve != "" {
+		go func() {
+			err := serveFeed(ctx, s, serveOptions{
+				addr:      daemonOpts.serve,
+				publicURL: daemonOpts.publicURL,
+				multiUser: daemonOpts.multiUser,
+				grpcAddr:  daemonOpts.grpc,
+			})
+			// without the server the daemon isn't doing what it was asked to
+			cancel()
+			serveErr <- err
+		}()
+	}
+
+	opts := aggOptions{quiet: quiet}
+	if cacheDir, err := s.Config.CacheDirPath(); err == nil {
+		opts.cache = feedCache{dir: cacheDir}
+	}
+	reload := func(ctx context.Context, s *state, opts aggOptions) (*state, aggOptions, time.Duration, error) {
+		return reloadDaemon(ctx, s, opts, daemonOpts)
+	}
+	if err := runScheduler(ctx, s, opts, daemonOpts.every, reload); err != nil {
+		return err
+	}
+
+	if daemonOpts.serve != "" {
+		if err := <-serveErr; err != nil {
+			fmt.Printf("Error serving feed: %v\n", err)
+			return err
+		}
+	}
+
+	infof("Daemon stopped\n")
+
+	return nil
+}
+
+// reloadDaemon rereads the config for the scheduler on SIGHUP: timeouts,
+// the host delay, feed size and item limits, fetch_workers, quiet hours,
+// notification targets, log_level and, unless --every was given,
+// daemon_every. The scheduler carries on with the returned copy of s
+// and interval; a server started alongside keeps what it started with,
+// and db_url or the HTTP client's settings still take a restart.
+// filter_script is read afresh for every feed regardless.
+func reloadDaemon(ctx context.Context, s *state, opts aggOptions, daemonOpts *daemonOptions) (*state, aggOptions, time.Duration, error) {
+	cfg := config.Config{}
+	cfg, err := cfg.Read()
+	if err != nil {
+		return nil, opts, 0, fmt.Errorf("failed to read config: %v", err)
+	}
+
+	level, err := parseVerbosity(cfg.LogLevel)
+	if err != nil {
+		return nil, opts, 0, err
+	}
+	hostDelay, err := cfg.HostDelayDuration()
+	if err != nil {
+		return nil, opts, 0, err
+	}
+	dbTimeout, err := cfg.DBTimeoutDuration()
+	if err != nil {
+		return nil, opts, 0, err
+	}
+	fetchTimeout, err := cfg.FetchTimeoutDuration()
+	if err != nil {
+		return nil, opts, 0, err
+	}
+
+	every := daemonOpts.every
+	if !daemonOpts.everySet {
+		if every, err = cfg.DaemonEveryDuration(); err != nil {
+			return nil, opts, 0, err
+		}
+	}
+
+	next := *s
+	next.Config = &cfg
+	next.hosts = newHostLimiter(hostDelay)
+	next.maxItems = cfg.MaxFeedItemsOrDefault()
+	next.maxFeedSize = cfg.MaxFeedSizeOrDefault()
+	next.itemsPerFetch = cfg.ItemsPerFetchOrDefault()
+	next.dbTimeout = dbTimeout
+	next.fetchTimeout = fetchTimeout
+
+	next.notifier = newConfiguredNotifier(&next)
+	if daemonOpts.notify {
+		sink, err := desktopSink()
+		if err != nil {
+			return nil, opts, 0, err
+		}
+		next.notifier.add(sink)
+	}
+
+	quiet, err := loadQuietHours(ctx, &next)
+	if err != nil {
+		return nil, opts, 0, err
+	}
+	if quiet != nil {
+		infof("Quiet hours %s\n", quiet)
+		next.notifier.quiet = quiet.pausesNotifications
+	}
+	opts.quiet = quiet
+
+	if !levelFromFlags {
+		setLogLevel(level)
+	}
+	return &next, opts, every, nil
+}
+
+// daemonReload asks the running daemon to reread the config.
+func daemonReload(s *state) error {
+	pidPath, err := s.Config.PIDFilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate PID file: %v", err)
+	}
+
+	pid, running := readPIDFile(pidPath)
+	if !running {
+		return notFoundErrorf("daemon is not running")
+	}
+
+	if err := syscall.Kill(pid, syscall.SIGHUP); err != nil {
+		return fmt.Errorf("failed to signal daemon (pid %d): %v", pid, err)
+	}
+	infof("Asked the daemon (pid %d) to reload its config\n", pid)
+
+	return nil
+}
+
+func daemonStop(s *state) error {
+	pidPath, err := s.Config.PIDFilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate PID file: %v", err)
+	}
+
+	pid, running := readPIDFile(pidPath)
+	if !running {
+		fmt.Println("Daemon is not running")
+		return nil
+	}
+
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to stop daemon (pid %d): %v", pid, err)
+	}
+
+	deadline := time.Now().Add(daemonStopTimeout)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("daemon (pid %d) did not stop within %s", pid, daemonStopTimeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	infof("Daemon stopped (pid %d)\n", pid)
+
+	return nil
+}
+
+func daemonStatus(s *state) error {
+	pidPath, err := s.Config.PIDFilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate PID file: %v", err)
+	}
+
+	pid, running := readPIDFile(pidPath)
+	if !running {
+		fmt.Println("Daemon is not running")
+		return nil
+	}
+
+	fmt.Printf("Daemon is running (pid %d)\n", pid)
+	if logPath, err := s.Config.LogFilePath(); err == nil {
+		fmt.Printf("Log: %s\n", logPath)
+	}
+
+	return nil
+}
+
+// writePIDFile creates the PID file, refusing if another live daemon owns
+// it. A file left behind by a daemon that died is replaced.
+func writePIDFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %v", err)
+	}
+
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, err = fmt.Fprintf(file, "%d\n", os.Getpid())
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write PID file: %v", err)
+			}
+			return nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to create PID file: %v", err)
+		}
+
+		if pid, running := readPIDFile(path); running {
+			return conflictErrorf("daemon is already running (pid %d)", pid)
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove stale PID file: %v", err)
+		}
+	}
+}
+
+// readPIDFile returns the PID recorded at path and whether that process
+// is still alive.
+func readPIDFile(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+
+	return pid, processAlive(pid)
+}
+
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// timestampOutput sends every line written to *f, os.Stdout or os.Stderr,
+// to out prefixed with the time, so the daemon's log reads as a log. The
+// returned func undoes it.
+func timestampOutput(f **os.File, out io.Writer) func() {
+	original := *f
+	r, w, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+	*f = w
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			fmt.Fprintf(out, "%s %s\n", time.Now().Format(time.RFC3339), scanner.Text())
+		}
+		io.Copy(out, r)
+	}()
+
+	return func() {
+		*f = original
+		w.Close()
+		<-done
+		r.Close()
+	}
+}
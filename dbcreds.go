@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/lib/pq"
+	"github.com/necodeus/gator/internal/config"
+)
+
+const (
+	dbPasswordKeyring = "keyring"
+	keyringService    = "gator"
+)
+
+// credentialConnector opens database connections with a password that is
+// not in db_url. The password is looked up on the first connection, so
+// commands that never touch the database never ask the keyring for it.
+type credentialConnector struct {
+	cfg *config.Config
+
+	once      sync.Once
+	connector driver.Connector
+	err       error
+}
+
+func newCredentialConnector(cfg *config.Config) *credentialConnector {
+	return &credentialConnector{cfg: cfg}
+}
+
+func (c *credentialConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.once.Do(func() {
+		dsn, err := databaseDSN(c.cfg)
+		if err == nil {
+			dsn, err = withSearchPath(dsn, c.cfg.DBSchema)
+		}
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.connector, c.err = pq.NewConnector(dsn)
+	})
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.connector.Connect(ctx)
+}
+
+func (c *credentialConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// databaseDSN is db_url with the password filled in from wherever
+// db_password_source says it is kept. With no source the password is in
+// db_url itself or, when it isn't, lib/pq finds it in the pgpass file.
+func databaseDSN(cfg *config.Config) (string, error) {
+	if cfg.PgpassFile != "" {
+		os.Setenv("PGPASSFILE", cfg.PgpassFile)
+	}
+	checkPgpassFile()
+
+	switch cfg.DbPasswordSource {
+	case "":
+		return cfg.DbUrl, nil
+	case dbPasswordKeyring:
+	default:
+		return "", fmt.Errorf("unknown db_password_source %s, expected %s", cfg.DbPasswordSource, dbPasswordKeyring)
+	}
+
+	u, err := databaseURL(cfg.DbUrl)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := u.User.Password(); ok {
+		return cfg.DbUrl, nil
+	}
+
+	password, err := keyringGet(keyringAccount(u))
+	if err != nil {
+		return "", err
+	}
+	u.User = url.UserPassword(u.User.Username(), password)
+	return u.String(), nil
+}
+
+// schemaName is the Postgres identifiers db_schema may be, which need no
+// quoting in search_path.
+var schemaName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// withSearchPath points every session opened with dsn at schema, which
+// lib/pq passes on as a run-time parameter. dsn is either a URL or
+// key=value pairs. An empty schema leaves dsn, and Postgres' default of
+// public, alone.
+func withSearchPath(dsn, schema string) (string, error) {
+	if schema == "" {
+		return dsn, nil
+	}
+	if !schemaName.MatchString(schema) {
+		return "", fmt.Errorf("invalid db_schema %q: use letters, digits and underscores", schema)
+	}
+
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " search_path=" + schema, nil
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", fmt.Errorf("invalid db_url: %v", err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// databaseURL parses db_url, which has to be a postgres:// URL for the
+// keyring to know which account's password to use.
+func databaseURL(dbURL string) (*url.URL, error) {
+	u, err := url.Parse(dbURL)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return nil, fmt.Errorf("db_url must be a postgres:// URL to keep its password in the keyring")
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("db_url must name the database user to keep its password in the keyring")
+	}
+	return u, nil
+}
+
+// keyringAccount names the keyring entry for the user, server and database
+// in u, so different databases keep different passwords.
+func keyringAccount(u *url.URL) string {
+	return fmt.Sprintf("%s@%s/%s", u.User.Username(), u.Host, strings.TrimPrefix(u.Path, "/"))
+}
+
+// checkPgpassFile warns about a pgpass file lib/pq will skip because others
+// can read it.
+func checkPgpassFile() {
+	path := os.Getenv("PGPASSFILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return
+		}
+		path = filepath.Join(home, ".pgpass")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		fmt.Printf("Ignoring %s: it must not be readable by group or others (chmod 600)\n", path)
+	}
+}
+
+// keyringGet, keyringSet and keyringDelete use the OS keyring through its
+// command line tool: secret-tool (Secret Service) on Linux and the BSDs,
+// security (Keychain) on macOS.
+func keyringGet(account string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", account, "-w")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "account", account)
+	default:
+		return "", fmt.Errorf("the keyring is not supported on %s", runtime.GOOS)
+	}
+
+	out, err := cmd.Output()
+	password := strings.TrimRight(string(out), "\r\n")
+	if err != nil || password == "" {
+		return "", fmt.Errorf("no password for %s in the keyring, store one with gator dbpassword store", account)
+	}
+	return password, nil
+}
+
+func keyringSet(account, password string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// security reads the command from stdin, keeping the password out
+		// of the process list
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+			keychainQuote(keyringService), keychainQuote(account), keychainQuote(password)))
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("secret-tool", "store", "--label=gator database password",
+			"service", keyringService, "account", account)
+		cmd.Stdin = strings.NewReader(password)
+	default:
+		return fmt.Errorf("the keyring is not supported on %s", runtime.GOOS)
+	}
+
+	return runKeyringTool(cmd)
+}
+
+func keyringDelete(account string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", account)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("secret-tool", "clear", "service", keyringService, "account", account)
+	default:
+		return fmt.Errorf("the keyring is not supported on %s", runtime.GOOS)
+	}
+
+	return runKeyringTool(cmd)
+}
+
+func runKeyringTool(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", filepath.Base(cmd.Path), msg)
+		}
+		return fmt.Errorf("%s: %v", filepath.Base(cmd.Path), err)
+	}
+	return nil
+}
+
+// keychainQuote quotes s for the command line security -i reads.
+func keychainQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// handlerDBPassword moves the database password into the keyring, or takes
+// it back out.
+func handlerDBPassword(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) != 1 {
+		return usageErrorf("dbpassword command requires a subcommand: store, forget")
+	}
+
+	u, err := databaseURL(s.Config.DbUrl)
+	if err != nil {
+		return err
+	}
+	account := keyringAccount(u)
+
+	switch cmd.Args[0] {
+	case "store":
+		password, ok := u.User.Password()
+		if !ok {
+			if password, err = readSecret("Database password"); err != nil {
+				return err
+			}
+		}
+		if err := keyringSet(account, password); err != nil {
+			return fmt.Errorf("failed to store password: %v", err)
+		}
+
+		// the password now lives only in the keyring
+		u.User = url.User(u.User.Username())
+		s.Config.DbUrl = u.String()
+		s.Config.DbPasswordSource = dbPasswordKeyring
+		if err := config.Write(*s.Config); err != nil {
+			return fmt.Errorf("failed to write config: %v", err)
+		}
+
+		infof("Password for %s is now kept in the keyring\n", account)
+	case "forget":
+		if err := keyringDelete(account); err != nil {
+			return fmt.Errorf("failed to remove password: %v", err)
+		}
+
+		s.Config.DbPasswordSource = ""
+		if err := config.Write(*s.Config); err != nil {
+			return fmt.Errorf("failed to write config: %v", err)
+		}
+
+		infof("Password for %s removed from the keyring; put it in db_url or a pgpass file\n", account)
+	default:
+		return usageErrorf("unknown dbpassword subcommand: %s", cmd.Args[0])
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// trackingParams are query parameters that only identify where a click
+// came from, so two URLs differing only in them are the same article.
+var trackingParams = []string{"fbclid", "gclid", "mc_cid", "mc_eid", "ref", "ref_src", "source"}
+
+// canonicalPostURL reduces an article URL to a form shared by every feed
+// that links to it: https, lowercase host, no "www.", no fragment, no
+// tracking parameters and no trailing slash.
+func canonicalPostURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = "https"
+	u.Host = strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+	for _, key := range trackingParams {
+		query.Del(key)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// titleHash fingerprints a title ignoring case, punctuation and spacing,
+// which catches the same story syndicated under slightly different
+// formatting. Empty titles have no fingerprint.
+func titleHash(title string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+
+	if b.Len() == 0 {
+		return ""
+	}
+	return hashKey([]byte(b.String()))
+}
+
+// story is one article in browse output together with the other feeds
+// that carried it.
+type story struct {
+	Row       database.GetPostsForUserRow
+	AlsoFeeds []string
+}
+
+func dedupKeys(post database.Post) []string {
+	canonical := post.CanonicalUrl.String
+	if !post.CanonicalUrl.Valid {
+		canonical = canonicalPostURL(post.Url)
+	}
+	hash := post.TitleHash.String
+	if !post.TitleHash.Valid {
+		hash = titleHash(post.Title)
+	}
+
+	keys := []string{"url:" + canonical}
+	if hash != "" {
+		keys = append(keys, "title:"+hash)
+	}
+	return keys
+}
+
+// collapseDuplicates folds posts sharing a canonical URL into the first
+// (newest) one, keeping the original order. A shared title fingerprint
+// only folds posts from different feeds, since one feed reusing a title,
+// like "Weekly roundup", is posting something new.
+func collapseDuplicates(rows []database.GetPostsForUserRow) []story {
+	var stories []story
+	seen := make(map[string]int)
+
+	for _, row := range rows {
+		keys := dedupKeys(row.Post)
+
+		index := -1
+		for _, key := range keys {
+			i, ok := seen[key]
+			if !ok {
+				continue
+			}
+			if strings.HasPrefix(key, "title:") && stories[i].Row.Post.FeedID == row.Post.FeedID {
+				continue
+			}
+			index = i
+			break
+		}
+
+		if index < 0 {
+			stories = append(stories, story{Row: row})
+			index = len(stories) - 1
+		} else if st := &stories[index]; row.FeedName != st.Row.FeedName && !slices.Contains(st.AlsoFeeds, row.FeedName) {
+			st.AlsoFeeds = append(st.AlsoFeeds, row.FeedName)
+		}
+
+		for _, key := range keys {
+			seen[key] = index
+		}
+	}
+
+	return stories
+}
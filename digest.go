@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// maxDigestSummary caps the one-line summary under each post, in runes.
+const maxDigestSummary = 160
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`, "`", "\\`", "<", `\<`,
+)
+
+func handlerDigest(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("digest", flag.ContinueOnError)
+	since := fs.Duration("since", 24*time.Hour, "include posts gator stored within this long; defaults to the digest.since preference")
+	out := fs.String("out", "", "write the digest to this file instead of stdout")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if *since <= 0 {
+		return usageErrorf("--since must be positive")
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if !flagSet(fs, "since") {
+		value, ok, err := userPreference(ctx, s, user.ID, prefDigestSince)
+		if err != nil {
+			return err
+		}
+		if d, err := time.ParseDuration(value); ok && err == nil && d > 0 {
+			*since = d
+		}
+	}
+
+	start := time.Now().UTC().Add(-*since)
+	posts, err := s.db.GetPostsForUserSince(ctx, database.GetPostsForUserSinceParams{
+		UserID: user.ID,
+		Since:  sql.NullTime{Time: start, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get posts: %v", err)
+	}
+
+	rows := make([]database.GetPostsForUserRow, 0, len(posts))
+	for _, post := range posts {
+		rows = append(rows, database.GetPostsForUserRow(post))
+	}
+
+	digest := renderDigest(collapseDuplicates(rows), start, userLocation(user))
+
+	if *out == "" {
+		fmt.Print(digest)
+	} else {
+		if err := os.WriteFile(*out, []byte(digest), 0o644); err != nil {
+			return fmt.Errorf("failed to write digest: %v", err)
+		}
+		infof("Wrote a digest of %d posts to %s\n", len(rows), *out)
+	}
+
+	if s.Config.OnDigest != "" {
+		runHook(ctx, "on_digest", s.Config.OnDigest, digestEvent{
+			Event:  "digest",
+			Since:  start,
+			Posts:  len(rows),
+			Digest: digest,
+			File:   *out,
+		})
+	}
+	return nil
+}
+
+// renderDigest lays stories out as Markdown, one section per feed in
+// alphabetical order and each feed's posts oldest first.
+func renderDigest(stories []story, since time.Time, loc *time.Location) string {
+	byFeed := make(map[string][]story)
+	var feeds []string
+	for _, st := range stories {
+		name := st.Row.FeedName
+		if _, ok := byFeed[name]; !ok {
+			feeds = append(feeds, name)
+		}
+		byFeed[name] = append(byFeed[name], st)
+	}
+	slices.SortFunc(feeds, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Digest for %s\n\n", time.Now().In(loc).Format("Monday, January 2, 2006"))
+	if len(stories) == 0 {
+		fmt.Fprintf(&b, "No new posts since %s.\n", since.In(loc).Format("Mon Jan 2 2006 15:04 MST"))
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%d new posts from %d feeds since %s.\n", len(stories), len(feeds), since.In(loc).Format("Mon Jan 2 2006 15:04 MST"))
+
+	for _, name := range feeds {
+		fmt.Fprintf(&b, "\n## %s\n\n", markdownEscaper.Replace(name))
+		for _, st := range byFeed[name] {
+			post := st.Row.Post
+			fmt.Fprintf(&b, "- [%s](%s)", markdownEscaper.Replace(post.Title), markdownURL(post.Url))
+			if summary := digestSummary(post.Description.String); summary != "" {
+				fmt.Fprintf(&b, " — %s", markdownEscaper.Replace(summary))
+			}
+			if len(st.AlsoFeeds) > 0 {
+				fmt.Fprintf(&b, " (also in %s)", markdownEscaper.Replace(strings.Join(st.AlsoFeeds, ", ")))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// digestSummary reduces a post description, often HTML, to its first
+// sentence, cut short if it runs long.
+func digestSummary(description string) string {
+	text := strings.Join(strings.Fields(parseHTML(description).textContent()), " ")
+
+	for i := 0; i < len(text); i++ {
+		if strings.ContainsRune(".!?", rune(text[i])) && (i+1 == len(text) || text[i+1] == ' ') {
+			text = text[:i+1]
+			break
+		}
+	}
+
+	if utf8.RuneCountInString(text) > maxDigestSummary {
+		runes := []rune(text)
+		text = strings.TrimSpace(string(runes[:maxDigestSummary-1])) + "…"
+	}
+	return text
+}
+
+// markdownURL makes a link target safe inside (...).
+func markdownURL(link string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(link)
+}
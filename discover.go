@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// defaultDiscoverLimit is how many of the instance's own feeds discover
+// suggests.
+const defaultDiscoverLimit = 10
+
+// maxDirectorySize bounds what is read of directory_url.
+const maxDirectorySize = 1 << 20
+
+// builtinDirectory is the curated list of feeds gator ships with.
+//
+//go:embed directory.json
+var builtinDirectory []byte
+
+// directoryEntry is a feed in a directory: gator's own or the one at
+// directory_url, which uses the same JSON.
+type directoryEntry struct {
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Topics      []string `json:"topics,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// matches reports whether the entry is about topic: one of its topics, or
+// found in its name or description. An empty topic matches everything.
+func (e directoryEntry) matches(topic string) bool {
+	if topic == "" {
+		return true
+	}
+	topic = strings.ToLower(topic)
+	if slices.ContainsFunc(e.Topics, func(t string) bool { return strings.ToLower(t) == topic }) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(e.Name), topic) || strings.Contains(strings.ToLower(e.Description), topic)
+}
+
+// handlerDiscover suggests feeds to follow, optionally on one topic: the
+// feeds most followed by other users here, then feeds from the directory
+// with the command that adds each one.
+func handlerDiscover(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("discover", flag.ContinueOnError)
+	limit := fs.Int("limit", defaultDiscoverLimit, "maximum number of this instance's feeds to suggest")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if *limit <= 0 {
+		return usageErrorf("limit must be a positive number")
+	}
+	topic := strings.TrimSpace(strings.Join(fs.Args(), " "))
+
+	// directory_url gets fetch_timeout, the queries db_timeout
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(dbCtx, s)
+	if err != nil {
+		return err
+	}
+
+	popular, err := s.db.GetPopularFeeds(dbCtx, database.GetPopularFeedsParams{
+		UserID:   user.ID,
+		Topic:    sql.NullString{String: topic, Valid: topic != ""},
+		MaxFeeds: int32(*limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get popular feeds: %v", err)
+	}
+
+	// directory feeds already stored are followed by name, or left out if
+	// the user follows them
+	stored, err := s.db.GetFeeds(dbCtx)
+	if err != nil {
+		return fmt.Errorf("failed to get feeds: %v", err)
+	}
+	follows, err := s.db.GetFeedFollowsForUser(dbCtx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get follows: %v", err)
+	}
+	storedByURL := make(map[string]database.Feed, len(stored))
+	for _, feed := range stored {
+		storedByURL[feed.Url] = feed
+	}
+	following := make(map[string]bool, len(follows))
+	for _, follow := range follows {
+		following[follow.FeedUrl] = true
+	}
+	suggested := make(map[string]bool)
+
+	if len(popular) > 0 {
+		fmt.Println("Followed on this instance:")
+		for _, row := range popular {
+			suggested[row.Feed.Url] = true
+			noun := "followers"
+			if row.Followers == 1 {
+				noun = "follower"
+			}
+			fmt.Printf("- %s (%d %s)\n", row.Feed.Name, row.Followers, noun)
+			fmt.Printf("  gator follow %s\n", shellQuote(row.Feed.Name))
+		}
+	}
+
+	entries, err := loadDirectory(ctx, s)
+	if err != nil {
+		return err
+	}
+	var listed int
+	for _, entry := range entries {
+		if !entry.matches(topic) || following[entry.URL] || suggested[entry.URL] {
+			continue
+		}
+		suggested[entry.URL] = true
+
+		if listed == 0 {
+			if len(popular) > 0 {
+				fmt.Println()
+			}
+			fmt.Println("From the directory:")
+		}
+		listed++
+
+		fmt.Printf("- %s", entry.Name)
+		if len(entry.Topics) > 0 {
+			fmt.Printf(" [%s]", strings.Join(entry.Topics, ", "))
+		}
+		fmt.Println()
+		if entry.Description != "" {
+			fmt.Printf("  %s\n", entry.Description)
+		}
+		if feed, ok := storedByURL[entry.URL]; ok {
+			fmt.Printf("  gator follow %s\n", shellQuote(feed.Name))
+		} else {
+			fmt.Printf("  gator addfeed %s\n", shellQuote(entry.URL))
+		}
+	}
+
+	if len(popular) == 0 && listed == 0 {
+		if topic != "" {
+			fmt.Printf("No feeds to suggest about %s\n", topic)
+		} else {
+			fmt.Println("No feeds to suggest")
+		}
+	}
+	return nil
+}
+
+// loadDirectory returns gator's directory followed by any entries from
+// directory_url it doesn't already have. The remote directory is a
+// nicety, so failing to load it is reported and the built-in one used.
+func loadDirectory(ctx context.Context, s *state) ([]directoryEntry, error) {
+	var entries []directoryEntry
+	if err := json.Unmarshal(builtinDirectory, &entries); err != nil {
+		return nil, fmt.Errorf("failed to read the built-in directory: %v", err)
+	}
+	if s.Config.DirectoryURL == "" {
+		return entries, nil
+	}
+
+	remote, err := fetchDirectory(ctx, s, s.Config.DirectoryURL)
+	if err != nil {
+		fmt.Printf("Error loading directory_url, using the built-in directory: %v\n", err)
+		return entries, nil
+	}
+	for _, entry := range remote {
+		if entry.URL == "" || slices.ContainsFunc(entries, func(e directoryEntry) bool { return e.URL == entry.URL }) {
+			continue
+		}
+		if entry.Name == "" {
+			entry.Name = entry.URL
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func fetchDirectory(ctx context.Context, s *state, directoryURL string) ([]directoryEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, directoryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", s.userAgent(nil))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad response status: %s", resp.Status)
+	}
+
+	var entries []directoryEntry
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDirectorySize)).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decoding directory: %w", err)
+	}
+	return entries, nil
+}
+
+// shellQuote quotes s for pasting into a shell, leaving it bare when that
+// is safe.
+func shellQuote(s string) string {
+	safe := s != "" && !strings.ContainsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:@%+=,", r))
+	})
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// progressWriter counts bytes passing through it and redraws a single
+// status line so long downloads show they're still moving.
+type progressWriter struct {
+	name    string
+	total   int64
+	written int64
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	p.print()
+	return len(b), nil
+}
+
+func (p *progressWriter) print() {
+	if p.total > 0 {
+		percent := float64(p.written) / float64(p.total) * 100
+		infof("\rDownloading %s: %5.1f%% (%s / %s)", p.name, percent, formatBytes(p.written), formatBytes(p.total))
+		return
+	}
+	infof("\rDownloading %s: %s", p.name, formatBytes(p.written))
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// enclosureFileName picks a local file name for an enclosure, falling back
+// to the post ID when the URL path has nothing usable in it.
+func enclosureFileName(enclosureURL, fallback string) string {
+	u, err := url.Parse(enclosureURL)
+	if err != nil {
+		return fallback
+	}
+	name := path.Base(u.Path)
+	if name == "" || name == "." || name == "/" {
+		return fallback
+	}
+	return name
+}
+
+func handlerDownload(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return usageErrorf("download command requires a post ID")
+	}
+
+	postID, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post ID %s: %v", cmd.Args[0], err)
+	}
+
+	dir := "."
+	if len(cmd.Args) > 1 {
+		dir = cmd.Args[1]
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	post, err := s.db.GetPostById(dbCtx, postID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundErrorf("post %s does not exist", postID)
+		}
+		return fmt.Errorf("failed to get post: %v", err)
+	}
+
+	if !post.EnclosureUrl.Valid {
+		return fmt.Errorf("post %s has no enclosure to download", postID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, post.EnclosureUrl.String, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", s.userAgent(nil))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("downloading enclosure: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad response status: %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	name := enclosureFileName(post.EnclosureUrl.String, post.ID.String())
+	target := filepath.Join(dir, name)
+	file, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	total := resp.ContentLength
+	if total <= 0 && post.EnclosureLength.Valid {
+		total = post.EnclosureLength.Int64
+	}
+
+	progress := &progressWriter{name: name, total: total}
+	if _, err := io.Copy(file, io.TeeReader(resp.Body, progress)); err != nil {
+		infof("\n")
+		return fmt.Errorf("failed to save enclosure: %v", err)
+	}
+	infof("\n")
+
+	infof("Saved %s\n", target)
+
+	return nil
+}
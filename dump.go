@@ -0,0 +1,891 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// A dump is the whole database as plain JSON, independent of the backend
+// it came from: users, feeds, follows, tags, posts, read state, stars,
+// notes, preferences and keyword weights. Feed credentials are left out, since
+// they are encrypted with a key only this machine has, and so are WebSub
+// subscriptions, which belong to the server that made them.
+//
+// As NDJSON, the first line is the header and every other line one
+// record, {"type": "posts", "record": {...}}. As JSON, it is one object
+// with the header fields and an array per type.
+const (
+	dumpFormat  = "gator-dump"
+	dumpVersion = 1
+	// dumpPageSize is how many posts or reads are read at once
+	dumpPageSize = 1000
+)
+
+// dumpTypes are the record types in the order they are written and
+// restored, each after the ones it refers to.
+var dumpTypes = []string{
+	"users", "feeds", "feed_follows", "feed_tags", "feed_aliases", "saved_searches", "folders", "posts", "post_tags", "post_reads", "post_stars", "post_notes", "preferences", "keyword_weights",
+}
+
+type dumpHeader struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+type dumpLine struct {
+	Type   string          `json:"type"`
+	Record json.RawMessage `json:"record"`
+}
+
+type dumpUser struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Timezone     *string    `json:"timezone,omitempty"`
+	LastSeenAt   *time.Time `json:"last_seen_at,omitempty"`
+	PasswordHash *string    `json:"password_hash,omitempty"`
+	IsAdmin      bool       `json:"is_admin,omitempty"`
+}
+
+type dumpFeed struct {
+	ID                  uuid.UUID  `json:"id"`
+	Name                string     `json:"name"`
+	URL                 string     `json:"url"`
+	UserID              uuid.UUID  `json:"user_id"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	Author              *string    `json:"author,omitempty"`
+	ImageURL            *string    `json:"image_url,omitempty"`
+	UserAgent           *string    `json:"user_agent,omitempty"`
+	Schedule            *string    `json:"schedule,omitempty"`
+	LastFetchedAt       *time.Time `json:"last_fetched_at,omitempty"`
+	PollIntervalSeconds *int32     `json:"poll_interval_seconds,omitempty"`
+	SkipHours           *int32     `json:"skip_hours,omitempty"`
+	SkipDays            *int32     `json:"skip_days,omitempty"`
+	Scraper             *string    `json:"scraper,omitempty"`
+	Priority            int32      `json:"priority,omitempty"`
+	PausedAt            *time.Time `json:"paused_at,omitempty"`
+	Tier                string     `json:"tier,omitempty"`
+	Shared              bool       `json:"shared,omitempty"`
+}
+
+type dumpFeedFollow struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	FeedID    uuid.UUID `json:"feed_id"`
+}
+
+type dumpFeedTag struct {
+	UserID uuid.UUID `json:"user_id"`
+	FeedID uuid.UUID `json:"feed_id"`
+	Tag    string    `json:"tag"`
+}
+
+type dumpFeedAlias struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Alias     string    `json:"alias"`
+	FeedID    uuid.UUID `json:"feed_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type dumpSavedSearch struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	Name      string     `json:"name"`
+	Terms     string     `json:"terms"`
+	FeedID    *uuid.UUID `json:"feed_id,omitempty"`
+	Tag       *string    `json:"tag,omitempty"`
+	Starred   bool       `json:"starred,omitempty"`
+	Unread    bool       `json:"unread,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type dumpFolder struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Keywords  []string  `json:"keywords,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Authors   []string  `json:"authors,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type dumpPost struct {
+	ID              uuid.UUID  `json:"id"`
+	FeedID          uuid.UUID  `json:"feed_id"`
+	Title           string     `json:"title"`
+	URL             string     `json:"url"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Description     *string    `json:"description,omitempty"`
+	PublishedAt     *time.Time `json:"published_at,omitempty"`
+	EnclosureURL    *string    `json:"enclosure_url,omitempty"`
+	EnclosureType   *string    `json:"enclosure_type,omitempty"`
+	EnclosureLength *int64     `json:"enclosure_length,omitempty"`
+	Author          *string    `json:"author,omitempty"`
+	ImageURL        *string    `json:"image_url,omitempty"`
+	DurationSeconds *int32     `json:"duration_seconds,omitempty"`
+	Episode         *int32     `json:"episode,omitempty"`
+	Season          *int32     `json:"season,omitempty"`
+	ThumbnailURL    *string    `json:"thumbnail_url,omitempty"`
+	CanonicalURL    *string    `json:"canonical_url,omitempty"`
+	TitleHash       *string    `json:"title_hash,omitempty"`
+	Score           *int32     `json:"score,omitempty"`
+	CommentCount    *int32     `json:"comment_count,omitempty"`
+	Relevance       float64    `json:"relevance,omitempty"`
+	GUID            *string    `json:"guid,omitempty"`
+	EditedAt        *time.Time `json:"edited_at,omitempty"`
+}
+
+type dumpPostTag struct {
+	PostID uuid.UUID `json:"post_id"`
+	Tag    string    `json:"tag"`
+}
+
+type dumpPostRead struct {
+	UserID uuid.UUID `json:"user_id"`
+	PostID uuid.UUID `json:"post_id"`
+	ReadAt time.Time `json:"read_at"`
+}
+
+type dumpPostStar struct {
+	UserID uuid.UUID `json:"user_id"`
+	PostID uuid.UUID `json:"post_id"`
+	// Note is only in dumps from before notes were kept apart from stars
+	Note      *string   `json:"note,omitempty"`
+	StarredAt time.Time `json:"starred_at"`
+}
+
+type dumpPostNote struct {
+	UserID    uuid.UUID `json:"user_id"`
+	PostID    uuid.UUID `json:"post_id"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type dumpPreference struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type dumpKeywordWeight struct {
+	Keyword string  `json:"keyword"`
+	Weight  float64 `json:"weight"`
+}
+
+// optional returns a pointer to v when valid, for the nullable columns.
+func optional[T any](v T, valid bool) *T {
+	if !valid {
+		return nil
+	}
+	return &v
+}
+
+func nullString(p *string) sql.NullString {
+	if p == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *p, Valid: true}
+}
+
+func nullTime(p *time.Time) sql.NullTime {
+	if p == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *p, Valid: true}
+}
+
+func nullInt32(p *int32) sql.NullInt32 {
+	if p == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *p, Valid: true}
+}
+
+func nullInt64(p *int64) sql.NullInt64 {
+	if p == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *p, Valid: true}
+}
+
+// dumpWriter writes records grouped by type, as NDJSON lines or as the
+// arrays of one JSON object.
+type dumpWriter struct {
+	w       *bufio.Writer
+	ndjson  bool
+	section string
+	first   bool
+	counts  map[string]int
+}
+
+func newDumpWriter(w io.Writer, ndjson bool) (*dumpWriter, error) {
+	d := &dumpWriter{w: bufio.NewWriter(w), ndjson: ndjson, counts: make(map[string]int)}
+
+	header, err := json.Marshal(dumpHeader{Format: dumpFormat, Version: dumpVersion, ExportedAt: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+	if ndjson {
+		_, err = fmt.Fprintf(d.w, "{\"type\":\"header\",\"record\":%s}\n", header)
+	} else {
+		// the header's fields open the object the arrays go in
+		_, err = d.w.Write(header[:len(header)-1])
+	}
+	return d, err
+}
+
+func (d *dumpWriter) write(kind string, record any) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	d.counts[kind]++
+
+	if d.ndjson {
+		line, err := json.Marshal(dumpLine{Type: kind, Record: data})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(d.w, "%s\n", line)
+		return err
+	}
+
+	if kind != d.section {
+		if d.section != "" {
+			d.w.WriteString("]")
+		}
+		fmt.Fprintf(d.w, ",\n%q:[", kind)
+		d.section, d.first = kind, true
+	}
+	if !d.first {
+		d.w.WriteString(",")
+	}
+	d.first = false
+	d.w.WriteString("\n")
+	_, err = d.w.Write(data)
+	return err
+}
+
+func (d *dumpWriter) close() error {
+	if !d.ndjson {
+		if d.section != "" {
+			d.w.WriteString("]")
+		}
+		d.w.WriteString("}\n")
+	}
+	return d.w.Flush()
+}
+
+// exportAll writes the whole database as a dump.
+func exportAll(ctx context.Context, s *state, args []string) error {
+	fs := flag.NewFlagSet("export all", flag.ContinueOnError)
+	format := fs.String("format", "ndjson", "output format: ndjson or json")
+	out := fs.String("out", "", "file to write to (defaults to stdout)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if *format != "ndjson" && *format != "json" {
+		return fmt.Errorf("unsupported export format: %s", *format)
+	}
+
+	// the dump holds password hashes, so it is only readable by its owner
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.OpenFile(*out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %v", *out, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	d, err := newDumpWriter(w, *format == "ndjson")
+	if err != nil {
+		return fmt.Errorf("failed to write export: %v", err)
+	}
+	if err := writeDump(ctx, s, d); err != nil {
+		return err
+	}
+	if err := d.close(); err != nil {
+		return fmt.Errorf("failed to write export: %v", err)
+	}
+
+	if *out != "" {
+		infof("Exported %d users, %d feeds, %d posts and %d reads to %s\n",
+			d.counts["users"], d.counts["feeds"], d.counts["posts"], d.counts["post_reads"], *out)
+	}
+	return nil
+}
+
+// writeDump reads every table in dumpTypes order, posts and reads a page
+// at a time, each read bounded by the database timeout.
+func writeDump(ctx context.Context, s *state, d *dumpWriter) error {
+	query := func(f func(ctx context.Context) error) error {
+		ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+		defer cancel()
+		return f(ctx)
+	}
+	fail := func(what string, err error) error {
+		return fmt.Errorf("failed to export %s: %v", what, err)
+	}
+
+	var users []database.User
+	var feeds []database.Feed
+	var follows []database.FeedFollow
+	var tags []database.FeedTag
+	var aliases []database.FeedAlias
+	var searches []database.SavedSearch
+	var folders []database.Folder
+	if err := query(func(ctx context.Context) error {
+		var err error
+		if users, err = s.db.GetUsers(ctx); err != nil {
+			return err
+		}
+		if feeds, err = s.db.GetFeeds(ctx); err != nil {
+			return err
+		}
+		if follows, err = s.db.DumpFeedFollows(ctx); err != nil {
+			return err
+		}
+		if tags, err = s.db.DumpFeedTags(ctx); err != nil {
+			return err
+		}
+		if aliases, err = s.db.DumpFeedAliases(ctx); err != nil {
+			return err
+		}
+		if searches, err = s.db.DumpSavedSearches(ctx); err != nil {
+			return err
+		}
+		folders, err = s.db.DumpFolders(ctx)
+		return err
+	}); err != nil {
+		return fail("feeds", err)
+	}
+
+	for _, u := range users {
+		if err := d.write("users", dumpUser{
+			ID:           u.ID,
+			Name:         u.Name,
+			CreatedAt:    u.CreatedAt,
+			UpdatedAt:    u.UpdatedAt,
+			Timezone:     optional(u.Timezone.String, u.Timezone.Valid),
+			LastSeenAt:   optional(u.LastSeenAt.Time, u.LastSeenAt.Valid),
+			PasswordHash: optional(u.PasswordHash.String, u.PasswordHash.Valid),
+			IsAdmin:      u.IsAdmin,
+		}); err != nil {
+			return fail("users", err)
+		}
+	}
+	for _, f := range feeds {
+		if err := d.write("feeds", dumpFeed{
+			ID:                  f.ID,
+			Name:                f.Name,
+			URL:                 f.Url,
+			UserID:              f.UserID,
+			CreatedAt:           f.CreatedAt,
+			UpdatedAt:           f.UpdatedAt,
+			Author:              optional(f.Author.String, f.Author.Valid),
+			ImageURL:            optional(f.ImageUrl.String, f.ImageUrl.Valid),
+			UserAgent:           optional(f.UserAgent.String, f.UserAgent.Valid),
+			Schedule:            optional(f.Schedule.String, f.Schedule.Valid),
+			LastFetchedAt:       optional(f.LastFetchedAt.Time, f.LastFetchedAt.Valid),
+			PollIntervalSeconds: optional(f.PollIntervalSeconds.Int32, f.PollIntervalSeconds.Valid),
+			SkipHours:           optional(f.SkipHours.Int32, f.SkipHours.Valid),
+			SkipDays:            optional(f.SkipDays.Int32, f.SkipDays.Valid),
+			Scraper:             optional(f.Scraper.String, f.Scraper.Valid),
+			Priority:            f.Priority,
+			PausedAt:            optional(f.PausedAt.Time, f.PausedAt.Valid),
+			Tier:                f.Tier,
+			Shared:              f.Shared,
+		}); err != nil {
+			return fail("feeds", err)
+		}
+	}
+	for _, f := range follows {
+		if err := d.write("feed_follows", dumpFeedFollow(f)); err != nil {
+			return fail("follows", err)
+		}
+	}
+	for _, t := range tags {
+		if err := d.write("feed_tags", dumpFeedTag(t)); err != nil {
+			return fail("tags", err)
+		}
+	}
+	for _, a := range aliases {
+		if err := d.write("feed_aliases", dumpFeedAlias(a)); err != nil {
+			return fail("aliases", err)
+		}
+	}
+	for _, ss := range searches {
+		if err := d.write("saved_searches", dumpSavedSearch{
+			UserID:    ss.UserID,
+			Name:      ss.Name,
+			Terms:     ss.Terms,
+			FeedID:    optional(ss.FeedID.UUID, ss.FeedID.Valid),
+			Tag:       optional(ss.Tag.String, ss.Tag.Valid),
+			Starred:   ss.Starred,
+			Unread:    ss.Unread,
+			CreatedAt: ss.CreatedAt,
+		}); err != nil {
+			return fail("saved searches", err)
+		}
+	}
+	for _, f := range folders {
+		if err := d.write("folders", dumpFolder(f)); err != nil {
+			return fail("folders", err)
+		}
+	}
+
+	after := uuid.Nil
+	for {
+		var posts []database.Post
+		if err := query(func(ctx context.Context) error {
+			var err error
+			posts, err = s.db.DumpPosts(ctx, database.DumpPostsParams{ID: after, Limit: dumpPageSize})
+			return err
+		}); err != nil {
+			return fail("posts", err)
+		}
+
+		for _, p := range posts {
+			if err := d.write("posts", dumpPost{
+				ID:              p.ID,
+				FeedID:          p.FeedID,
+				Title:           p.Title,
+				URL:             p.Url,
+				CreatedAt:       p.CreatedAt,
+				UpdatedAt:       p.UpdatedAt,
+				Description:     optional(p.Description.String, p.Description.Valid),
+				PublishedAt:     optional(p.PublishedAt.Time, p.PublishedAt.Valid),
+				EnclosureURL:    optional(p.EnclosureUrl.String, p.EnclosureUrl.Valid),
+				EnclosureType:   optional(p.EnclosureType.String, p.EnclosureType.Valid),
+				EnclosureLength: optional(p.EnclosureLength.Int64, p.EnclosureLength.Valid),
+				Author:          optional(p.Author.String, p.Author.Valid),
+				ImageURL:        optional(p.ImageUrl.String, p.ImageUrl.Valid),
+				DurationSeconds: optional(p.DurationSeconds.Int32, p.DurationSeconds.Valid),
+				Episode:         optional(p.Episode.Int32, p.Episode.Valid),
+				Season:          optional(p.Season.Int32, p.Season.Valid),
+				ThumbnailURL:    optional(p.ThumbnailUrl.String, p.ThumbnailUrl.Valid),
+				CanonicalURL:    optional(p.CanonicalUrl.String, p.CanonicalUrl.Valid),
+				TitleHash:       optional(p.TitleHash.String, p.TitleHash.Valid),
+				Score:           optional(p.Score.Int32, p.Score.Valid),
+				CommentCount:    optional(p.CommentCount.Int32, p.CommentCount.Valid),
+				Relevance:       p.Relevance,
+				GUID:            optional(p.Guid.String, p.Guid.Valid),
+				EditedAt:        optional(p.EditedAt.Time, p.EditedAt.Valid),
+			}); err != nil {
+				return fail("posts", err)
+			}
+		}
+		if len(posts) < dumpPageSize {
+			break
+		}
+		after = posts[len(posts)-1].ID
+	}
+
+	var postTags []database.PostTag
+	if err := query(func(ctx context.Context) error {
+		var err error
+		postTags, err = s.db.DumpPostTags(ctx)
+		return err
+	}); err != nil {
+		return fail("post tags", err)
+	}
+	for _, t := range postTags {
+		if err := d.write("post_tags", dumpPostTag(t)); err != nil {
+			return fail("post tags", err)
+		}
+	}
+
+	afterUser, afterPost := uuid.Nil, uuid.Nil
+	for {
+		var reads []database.PostRead
+		if err := query(func(ctx context.Context) error {
+			var err error
+			reads, err = s.db.DumpPostReads(ctx, database.DumpPostReadsParams{
+				AfterUserID: afterUser,
+				AfterPostID: afterPost,
+				MaxRows:     dumpPageSize,
+			})
+			return err
+		}); err != nil {
+			return fail("reads", err)
+		}
+
+		for _, r := range reads {
+			if err := d.write("post_reads", dumpPostRead(r)); err != nil {
+				return fail("reads", err)
+			}
+		}
+		if len(reads) < dumpPageSize {
+			break
+		}
+		afterUser, afterPost = reads[len(reads)-1].UserID, reads[len(reads)-1].PostID
+	}
+
+	var stars []database.PostStar
+	var notes []database.PostNote
+	var prefs []database.UserPreference
+	var weights []database.KeywordWeight
+	if err := query(func(ctx context.Context) error {
+		var err error
+		if stars, err = s.db.DumpPostStars(ctx); err != nil {
+			return err
+		}
+		if notes, err = s.db.DumpPostNotes(ctx); err != nil {
+			return err
+		}
+		if prefs, err = s.db.DumpPreferences(ctx); err != nil {
+			return err
+		}
+		weights, err = s.db.GetKeywordWeights(ctx)
+		return err
+	}); err != nil {
+		return fail("stars, notes and preferences", err)
+	}
+
+	for _, st := range stars {
+		if err := d.write("post_stars", dumpPostStar{
+			UserID:    st.UserID,
+			PostID:    st.PostID,
+			StarredAt: st.StarredAt,
+		}); err != nil {
+			return fail("stars", err)
+		}
+	}
+	for _, n := range notes {
+		if err := d.write("post_notes", dumpPostNote(n)); err != nil {
+			return fail("notes", err)
+		}
+	}
+	for _, p := range prefs {
+		if err := d.write("preferences", dumpPreference(p)); err != nil {
+			return fail("preferences", err)
+		}
+	}
+	for _, w := range weights {
+		if err := d.write("keyword_weights", dumpKeywordWeight(w)); err != nil {
+			return fail("keyword weights", err)
+		}
+	}
+
+	return nil
+}
+
+// importAll restores a dump into an empty database, in one transaction,
+// so a dump that fails halfway leaves nothing behind.
+func importAll(ctx context.Context, s *state, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("import all requires a dump file, or - for stdin")
+	}
+
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", args[0], err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	users, err := s.db.GetUsers(checkCtx)
+	var feeds int64
+	if err == nil {
+		feeds, err = s.db.CountFeeds(checkCtx)
+	}
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to check the database is empty: %v", err)
+	}
+	if len(users) > 0 || feeds > 0 {
+		return fmt.Errorf("import all needs an empty database, run reset first")
+	}
+
+	counts := make(map[string]int)
+	err = s.withTx(ctx, func(tx *state) error {
+		return readDump(bufio.NewReader(r), func(kind string, record json.RawMessage) error {
+			if err := restoreRecord(ctx, tx, kind, record); err != nil {
+				return fmt.Errorf("failed to import %s record %d: %v", kind, counts[kind]+1, err)
+			}
+			counts[kind]++
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	infof("Imported %d users, %d feeds, %d posts and %d reads\n",
+		counts["users"], counts["feeds"], counts["posts"], counts["post_reads"])
+	return nil
+}
+
+// readDump calls restore for every record of a dump in either format.
+func readDump(r io.Reader, restore func(kind string, record json.RawMessage) error) error {
+	decoder := json.NewDecoder(r)
+
+	var first map[string]json.RawMessage
+	if err := decoder.Decode(&first); err != nil {
+		return fmt.Errorf("not a gator dump: %v", err)
+	}
+
+	// NDJSON starts with a header line
+	if kind, ok := first["type"]; ok {
+		var header dumpHeader
+		if string(kind) != `"header"` || json.Unmarshal(first["record"], &header) != nil {
+			return fmt.Errorf("not a gator dump: the first line is not a header")
+		}
+		if err := checkDumpHeader(header); err != nil {
+			return err
+		}
+
+		for {
+			var line dumpLine
+			err := decoder.Decode(&line)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read dump: %v", err)
+			}
+			if err := restore(line.Type, line.Record); err != nil {
+				return err
+			}
+		}
+	}
+
+	var header dumpHeader
+	json.Unmarshal(first["format"], &header.Format)
+	json.Unmarshal(first["version"], &header.Version)
+	if err := checkDumpHeader(header); err != nil {
+		return err
+	}
+
+	for _, kind := range dumpTypes {
+		var records []json.RawMessage
+		if raw, ok := first[kind]; ok {
+			if err := json.Unmarshal(raw, &records); err != nil {
+				return fmt.Errorf("failed to read %s: %v", kind, err)
+			}
+		}
+		for _, record := range records {
+			if err := restore(kind, record); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkDumpHeader(header dumpHeader) error {
+	if header.Format != dumpFormat {
+		return fmt.Errorf("not a gator dump")
+	}
+	if header.Version > dumpVersion {
+		return fmt.Errorf("the dump is version %d, this gator reads up to version %d", header.Version, dumpVersion)
+	}
+	return nil
+}
+
+func restoreRecord(ctx context.Context, s *state, kind string, record json.RawMessage) error {
+	switch kind {
+	case "users":
+		var u dumpUser
+		if err := json.Unmarshal(record, &u); err != nil {
+			return err
+		}
+		return s.db.RestoreUser(ctx, database.RestoreUserParams{
+			ID:           u.ID,
+			CreatedAt:    u.CreatedAt,
+			UpdatedAt:    u.UpdatedAt,
+			Name:         u.Name,
+			Timezone:     nullString(u.Timezone),
+			LastSeenAt:   nullTime(u.LastSeenAt),
+			PasswordHash: nullString(u.PasswordHash),
+			IsAdmin:      u.IsAdmin,
+		})
+	case "feeds":
+		var f dumpFeed
+		if err := json.Unmarshal(record, &f); err != nil {
+			return err
+		}
+		// dumps from before tiers have none
+		if f.Tier == "" {
+			f.Tier = tierNormal
+		}
+		return s.db.RestoreFeed(ctx, database.RestoreFeedParams{
+			ID:                  f.ID,
+			CreatedAt:           f.CreatedAt,
+			UpdatedAt:           f.UpdatedAt,
+			Name:                f.Name,
+			Url:                 f.URL,
+			UserID:              f.UserID,
+			Author:              nullString(f.Author),
+			ImageUrl:            nullString(f.ImageURL),
+			UserAgent:           nullString(f.UserAgent),
+			Schedule:            nullString(f.Schedule),
+			LastFetchedAt:       nullTime(f.LastFetchedAt),
+			PollIntervalSeconds: nullInt32(f.PollIntervalSeconds),
+			SkipHours:           nullInt32(f.SkipHours),
+			SkipDays:            nullInt32(f.SkipDays),
+			Scraper:             nullString(f.Scraper),
+			Priority:            f.Priority,
+			PausedAt:            nullTime(f.PausedAt),
+			Tier:                f.Tier,
+			Shared:              f.Shared,
+		})
+	case "feed_follows":
+		var f dumpFeedFollow
+		if err := json.Unmarshal(record, &f); err != nil {
+			return err
+		}
+		return s.db.RestoreFeedFollow(ctx, database.RestoreFeedFollowParams(f))
+	case "feed_tags":
+		var t dumpFeedTag
+		if err := json.Unmarshal(record, &t); err != nil {
+			return err
+		}
+		return s.db.AddFeedTag(ctx, database.AddFeedTagParams(t))
+	case "feed_aliases":
+		var a dumpFeedAlias
+		if err := json.Unmarshal(record, &a); err != nil {
+			return err
+		}
+		return s.db.RestoreFeedAlias(ctx, database.RestoreFeedAliasParams(a))
+	case "saved_searches":
+		var ss dumpSavedSearch
+		if err := json.Unmarshal(record, &ss); err != nil {
+			return err
+		}
+		params := database.RestoreSavedSearchParams{
+			UserID:    ss.UserID,
+			Name:      ss.Name,
+			Terms:     ss.Terms,
+			Tag:       nullString(ss.Tag),
+			Starred:   ss.Starred,
+			Unread:    ss.Unread,
+			CreatedAt: ss.CreatedAt,
+		}
+		if ss.FeedID != nil {
+			params.FeedID = uuid.NullUUID{UUID: *ss.FeedID, Valid: true}
+		}
+		return s.db.RestoreSavedSearch(ctx, params)
+	case "folders":
+		var f dumpFolder
+		if err := json.Unmarshal(record, &f); err != nil {
+			return err
+		}
+		return s.db.RestoreFolder(ctx, database.RestoreFolderParams{
+			UserID:    f.UserID,
+			Name:      f.Name,
+			Keywords:  nonNil(f.Keywords),
+			Tags:      nonNil(f.Tags),
+			Authors:   nonNil(f.Authors),
+			CreatedAt: f.CreatedAt,
+		})
+	case "posts":
+		var p dumpPost
+		if err := json.Unmarshal(record, &p); err != nil {
+			return err
+		}
+		return s.db.RestorePost(ctx, database.RestorePostParams{
+			ID:              p.ID,
+			CreatedAt:       p.CreatedAt,
+			UpdatedAt:       p.UpdatedAt,
+			Title:           p.Title,
+			Url:             p.URL,
+			Description:     nullString(p.Description),
+			PublishedAt:     nullTime(p.PublishedAt),
+			FeedID:          p.FeedID,
+			EnclosureUrl:    nullString(p.EnclosureURL),
+			EnclosureType:   nullString(p.EnclosureType),
+			EnclosureLength: nullInt64(p.EnclosureLength),
+			Author:          nullString(p.Author),
+			ImageUrl:        nullString(p.ImageURL),
+			DurationSeconds: nullInt32(p.DurationSeconds),
+			Episode:         nullInt32(p.Episode),
+			Season:          nullInt32(p.Season),
+			ThumbnailUrl:    nullString(p.ThumbnailURL),
+			CanonicalUrl:    nullString(p.CanonicalURL),
+			TitleHash:       nullString(p.TitleHash),
+			Score:           nullInt32(p.Score),
+			CommentCount:    nullInt32(p.CommentCount),
+			Relevance:       p.Relevance,
+			Guid:            nullString(p.GUID),
+			EditedAt:        nullTime(p.EditedAt),
+		})
+	case "post_tags":
+		var t dumpPostTag
+		if err := json.Unmarshal(record, &t); err != nil {
+			return err
+		}
+		return s.db.AddPostTags(ctx, database.AddPostTagsParams{PostID: t.PostID, Tags: []string{t.Tag}})
+	case "post_reads":
+		var r dumpPostRead
+		if err := json.Unmarshal(record, &r); err != nil {
+			return err
+		}
+		return s.db.RestorePostRead(ctx, database.RestorePostReadParams(r))
+	case "post_stars":
+		var st dumpPostStar
+		if err := json.Unmarshal(record, &st); err != nil {
+			return err
+		}
+		if err := s.db.RestorePostStar(ctx, database.RestorePostStarParams{
+			UserID:    st.UserID,
+			PostID:    st.PostID,
+			StarredAt: st.StarredAt,
+		}); err != nil {
+			return err
+		}
+		if st.Note == nil {
+			return nil
+		}
+		return s.db.RestorePostNote(ctx, database.RestorePostNoteParams{
+			UserID:    st.UserID,
+			PostID:    st.PostID,
+			Note:      *st.Note,
+			CreatedAt: st.StarredAt,
+			UpdatedAt: st.StarredAt,
+		})
+	case "post_notes":
+		var n dumpPostNote
+		if err := json.Unmarshal(record, &n); err != nil {
+			return err
+		}
+		return s.db.RestorePostNote(ctx, database.RestorePostNoteParams(n))
+	case "preferences":
+		var p dumpPreference
+		if err := json.Unmarshal(record, &p); err != nil {
+			return err
+		}
+		return s.db.RestorePreference(ctx, database.RestorePreferenceParams(p))
+	case "keyword_weights":
+		var w dumpKeywordWeight
+		if err := json.Unmarshal(record, &w); err != nil {
+			return err
+		}
+		return s.db.SetKeywordWeight(ctx, database.SetKeywordWeightParams(w))
+	default:
+		// records from a newer gator that this one doesn't know about
+		return nil
+	}
+}
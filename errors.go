@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+// Exit codes, so monitoring and scripts can tell what is wrong without
+// parsing output.
+const (
+	exitFailure    = 1
+	exitConfig     = 2
+	exitDatabase   = 3
+	exitSchema     = 4
+	exitNetwork    = 5
+	exitUsage      = 6
+	exitNotFound   = 7
+	exitConflict   = 8
+	exitPermission = 9
+)
+
+// exitCodeError makes main exit with code instead of the usual 1.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// usageError marks err, e.g. from parsing flags, as a usage error.
+func usageError(err error) error {
+	return &exitCodeError{exitUsage, err}
+}
+
+// usageErrorf reports a command given the wrong arguments.
+func usageErrorf(format string, args ...any) error {
+	return &exitCodeError{exitUsage, fmt.Errorf(format, args...)}
+}
+
+// notFoundErrorf reports a user, feed or post that doesn't exist.
+func notFoundErrorf(format string, args ...any) error {
+	return &exitCodeError{exitNotFound, fmt.Errorf(format, args...)}
+}
+
+// conflictErrorf reports something that already exists or is already
+// happening.
+func conflictErrorf(format string, args ...any) error {
+	return &exitCodeError{exitConflict, fmt.Errorf(format, args...)}
+}
+
+// permissionErrorf reports something the current user isn't allowed to
+// do.
+func permissionErrorf(format string, args ...any) error {
+	return &exitCodeError{exitPermission, fmt.Errorf(format, args...)}
+}
+
+// networkError marks err as a network failure when cause is one: the
+// server couldn't be reached at all, as opposed to answering badly.
+func networkError(cause, err error) error {
+	var netErr net.Error
+	if errors.As(cause, &netErr) {
+		return &exitCodeError{exitNetwork, err}
+	}
+	return err
+}
+
+// exitWith reports err on stderr and exits with the code for its
+// category.
+func exitWith(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.code)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		os.Exit(exitNetwork)
+	}
+	os.Exit(exitFailure)
+}
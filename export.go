@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// exportedPost is the on-disk shape of a post in JSON exports.
+type exportedPost struct {
+	ID              uuid.UUID  `json:"id"`
+	Feed            string     `json:"feed"`
+	FeedID          uuid.UUID  `json:"feed_id"`
+	Title           string     `json:"title"`
+	URL             string     `json:"url"`
+	Description     string     `json:"description,omitempty"`
+	PublishedAt     *time.Time `json:"published_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Author          string     `json:"author,omitempty"`
+	ImageURL        string     `json:"image_url,omitempty"`
+	ThumbnailURL    string     `json:"thumbnail_url,omitempty"`
+	EnclosureURL    string     `json:"enclosure_url,omitempty"`
+	EnclosureType   string     `json:"enclosure_type,omitempty"`
+	EnclosureLength *int64     `json:"enclosure_length,omitempty"`
+	DurationSeconds *int32     `json:"duration_seconds,omitempty"`
+	Episode         *int32     `json:"episode,omitempty"`
+	Season          *int32     `json:"season,omitempty"`
+	Tags            []string   `json:"tags,omitempty"`
+}
+
+var exportCSVHeader = []string{
+	"id", "feed", "feed_id", "title", "url", "description", "published_at",
+	"created_at", "updated_at", "author", "image_url", "thumbnail_url",
+	"enclosure_url", "enclosure_type", "enclosure_length",
+	"duration_seconds", "episode", "season", "tags",
+}
+
+func handlerExport(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return usageErrorf("export command requires a subcommand: posts, rss, reading-list, all")
+	}
+
+	// a full export reads page by page, bounding each read itself, and
+	// posts may ask which feed --feed means before its timeout starts
+	switch cmd.Args[0] {
+	case "all":
+		return exportAll(ctx, s, cmd.Args[1:])
+	case "posts":
+		return exportPosts(ctx, s, cmd.Args[1:])
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	switch cmd.Args[0] {
+	case "rss":
+		return exportRSS(ctx, s, cmd.Args[1:])
+	case "reading-list":
+		return exportReadingList(ctx, s, cmd.Args[1:])
+	default:
+		return usageErrorf("unknown export subcommand: %s", cmd.Args[0])
+	}
+}
+
+func exportPosts(ctx context.Context, s *state, args []string) error {
+	fs := flag.NewFlagSet("export posts", flag.ContinueOnError)
+	feedName := fs.String("feed", "", "only export posts from this feed")
+	format := fs.String("format", "json", "output format: csv or json")
+	out := fs.String("out", "", "file to write to (defaults to stdout)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	if *format != "csv" && *format != "json" {
+		return usageErrorf("unsupported export format: %s", *format)
+	}
+
+	feedID, err := feedFilter(ctx, s, *feedName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+	if feedID.Valid {
+		following, err := s.db.IsFollowingFeed(ctx, database.IsFollowingFeedParams{UserID: user.ID, FeedID: feedID.UUID})
+		if err != nil {
+			return fmt.Errorf("failed to check follow: %v", err)
+		}
+		if !following {
+			return notFoundErrorf("%s is not following %s", user.Name, *feedName)
+		}
+	}
+
+	posts, err := s.db.GetPostsForExport(ctx, database.GetPostsForExportParams{
+		UserID: user.ID,
+		FeedID: feedID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get posts: %v", err)
+	}
+
+	w, err := openOutput(*out)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	exported := make([]exportedPost, 0, len(posts))
+	for _, row := range posts {
+		exported = append(exported, newExportedPost(row.Post, row.FeedName))
+	}
+	if err := loadPostTags(ctx, s, exported); err != nil {
+		return err
+	}
+
+	if *format == "csv" {
+		err = writePostsCSV(w, exported)
+	} else {
+		err = writePostsJSON(w, exported)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write export: %v", err)
+	}
+
+	if *out != "" {
+		infof("Exported %d posts to %s\n", len(exported), *out)
+	}
+
+	return nil
+}
+
+func exportRSS(ctx context.Context, s *state, args []string) error {
+	fs := flag.NewFlagSet("export rss", flag.ContinueOnError)
+	limit := fs.Int("limit", defaultPublishLimit, "maximum number of posts to include")
+	out := fs.String("out", "", "file to write to (defaults to stdout)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	if *limit <= 0 {
+		return usageErrorf("limit must be a positive number")
+	}
+
+	user, posts, err := followedPosts(ctx, s, *limit)
+	if err != nil {
+		return err
+	}
+
+	w, err := openOutput(*out)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if err := writeOutputFeed(w, user, posts); err != nil {
+		return fmt.Errorf("failed to write feed: %v", err)
+	}
+
+	return nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// openOutput opens the file named by an --out flag, or stdout when the
+// flag was left empty.
+func openOutput(path string) (io.WriteCloser, error) {
+	if path == "" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	return file, nil
+}
+
+// loadPostTags fills in the tags filter_script gave posts.
+func loadPostTags(ctx context.Context, s *state, posts []exportedPost) error {
+	ids := make([]uuid.UUID, 0, len(posts))
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+	}
+	tags, err := s.db.GetPostTags(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get post tags: %v", err)
+	}
+
+	byPost := make(map[uuid.UUID][]string)
+	for _, t := range tags {
+		byPost[t.PostID] = append(byPost[t.PostID], t.Tag)
+	}
+	for i := range posts {
+		posts[i].Tags = byPost[posts[i].ID]
+	}
+	return nil
+}
+
+func newExportedPost(post database.Post, feedName string) exportedPost {
+	p := exportedPost{
+		ID:            post.ID,
+		Feed:          feedName,
+		FeedID:        post.FeedID,
+		Title:         post.Title,
+		URL:           post.Url,
+		Description:   post.Description.String,
+		CreatedAt:     post.CreatedAt,
+		UpdatedAt:     post.UpdatedAt,
+		Author:        post.Author.String,
+		ImageURL:      post.ImageUrl.String,
+		ThumbnailURL:  post.ThumbnailUrl.String,
+		EnclosureURL:  post.EnclosureUrl.String,
+		EnclosureType: post.EnclosureType.String,
+	}
+	if post.PublishedAt.Valid {
+		p.PublishedAt = &post.PublishedAt.Time
+	}
+	if post.EnclosureLength.Valid {
+		p.EnclosureLength = &post.EnclosureLength.Int64
+	}
+	if post.DurationSeconds.Valid {
+		p.DurationSeconds = &post.DurationSeconds.Int32
+	}
+	if post.Episode.Valid {
+		p.Episode = &post.Episode.Int32
+	}
+	if post.Season.Valid {
+		p.Season = &post.Season.Int32
+	}
+	return p
+}
+
+func writePostsJSON(w io.Writer, posts []exportedPost) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(posts)
+}
+
+func writePostsCSV(w io.Writer, posts []exportedPost) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportCSVHeader); err != nil {
+		return err
+	}
+
+	for _, p := range posts {
+		record := []string{
+			p.ID.String(),
+			p.Feed,
+			p.FeedID.String(),
+			p.Title,
+			p.URL,
+			p.Description,
+			formatOptionalTime(p.PublishedAt),
+			p.CreatedAt.Format(time.RFC3339),
+			p.UpdatedAt.Format(time.RFC3339),
+			p.Author,
+			p.ImageURL,
+			p.ThumbnailURL,
+			p.EnclosureURL,
+			p.EnclosureType,
+			formatOptionalInt(p.EnclosureLength),
+			formatOptionalInt(p.DurationSeconds),
+			formatOptionalInt(p.Episode),
+			formatOptionalInt(p.Season),
+			strings.Join(p.Tags, ","),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatOptionalInt[T int32 | int64](n *T) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.FormatInt(int64(*n), 10)
+}
@@ -0,0 +1,526 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+func handlerFeed(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return usageErrorf("feed command requires a subcommand: set-user-agent, set-schedule, set-auth, set-header, set-priority, set-tier, pause, resume, delete, chown, share, unshare")
+	}
+
+	switch cmd.Args[0] {
+	case "set-user-agent":
+		return feedSetUserAgent(ctx, s, cmd.Args[1:])
+	case "set-schedule":
+		return feedSetSchedule(ctx, s, cmd.Args[1:])
+	case "set-auth":
+		return feedSetAuth(ctx, s, cmd.Args[1:])
+	case "set-header":
+		return feedSetHeader(ctx, s, cmd.Args[1:])
+	case "set-priority":
+		return feedSetPriority(ctx, s, cmd.Args[1:])
+	case "set-tier":
+		return feedSetTier(ctx, s, cmd.Args[1:])
+	case "pause":
+		return feedPause(ctx, s, cmd.Args[1:], true)
+	case "resume":
+		return feedPause(ctx, s, cmd.Args[1:], false)
+	case "delete":
+		return feedDelete(ctx, s, cmd.Args[1:])
+	case "chown":
+		return feedChown(ctx, s, cmd.Args[1:])
+	case "share":
+		return feedShare(ctx, s, cmd.Args[1:], true)
+	case "unshare":
+		return feedShare(ctx, s, cmd.Args[1:], false)
+	default:
+		return usageErrorf("unknown feed subcommand: %s", cmd.Args[0])
+	}
+}
+
+// feedSetUserAgent overrides the User-Agent sent when fetching one feed;
+// leaving out the value goes back to the configured default. This and the
+// other fetch settings apply for every follower, so like delete they need
+// the feed in full and its owner or an admin.
+func feedSetUserAgent(ctx context.Context, s *state, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("feed set-user-agent requires a feed and optionally a User-Agent")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	feed, err := exactFeed(ctx, s, args[0])
+	if err != nil {
+		return err
+	}
+	if err := requireFeedOwner(ctx, s, feed); err != nil {
+		return err
+	}
+
+	userAgent := strings.TrimSpace(strings.Join(args[1:], " "))
+	err = s.db.SetFeedUserAgent(ctx, database.SetFeedUserAgentParams{
+		ID:        feed.ID,
+		UserAgent: sql.NullString{String: userAgent, Valid: userAgent != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update feed: %v", err)
+	}
+
+	if userAgent == "" {
+		infof("%s now uses the default User-Agent\n", feed.Name)
+	} else {
+		infof("%s now uses User-Agent %q\n", feed.Name, userAgent)
+	}
+
+	return nil
+}
+
+// feedSetSchedule sets the cron expression `agg --every` uses to decide
+// when a feed is due; leaving it out goes back to the feed's own polling hints.
+func feedSetSchedule(ctx context.Context, s *state, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("feed set-schedule requires a feed and optionally a cron expression")
+	}
+
+	schedule := strings.TrimSpace(strings.Join(args[1:], " "))
+	if schedule != "" {
+		if _, err := parseCron(schedule); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	feed, err := exactFeed(ctx, s, args[0])
+	if err != nil {
+		return err
+	}
+	if err := requireFeedOwner(ctx, s, feed); err != nil {
+		return err
+	}
+
+	err = s.db.SetFeedSchedule(ctx, database.SetFeedScheduleParams{
+		ID:       feed.ID,
+		Schedule: sql.NullString{String: schedule, Valid: schedule != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update feed: %v", err)
+	}
+
+	if schedule == "" {
+		infof("%s is now fetched as often as the publisher allows\n", feed.Name)
+	} else {
+		infof("%s is now fetched on schedule %q\n", feed.Name, schedule)
+	}
+
+	return nil
+}
+
+// feedSetPriority sets how many points every post from a feed scores on
+// top of its keywords; leaving it out goes back to 0.
+func feedSetPriority(ctx context.Context, s *state, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return usageErrorf("feed set-priority requires a feed and optionally a priority")
+	}
+
+	priority := 0
+	if len(args) == 2 {
+		var err error
+		if priority, err = strconv.Atoi(args[1]); err != nil {
+			return fmt.Errorf("invalid priority %q", args[1])
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	feed, err := exactFeed(ctx, s, args[0])
+	if err != nil {
+		return err
+	}
+	if err := requireFeedOwner(ctx, s, feed); err != nil {
+		return err
+	}
+
+	err = s.db.SetFeedPriority(ctx, database.SetFeedPriorityParams{
+		ID:       feed.ID,
+		Priority: int32(priority),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update feed: %v", err)
+	}
+
+	infof("%s now has priority %d; posts stored from now on are scored with it\n", feed.Name, priority)
+	return nil
+}
+
+// Feed tiers say how eagerly the scheduler fetches a feed.
+const (
+	tierHigh   = "high"
+	tierNormal = "normal"
+	tierLow    = "low"
+)
+
+// feedSetTier sets how often `agg --every` fetches a feed compared to
+// the rest, and where its posts land among equals in browse; leaving it
+// out goes back to normal.
+func feedSetTier(ctx context.Context, s *state, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return usageErrorf("feed set-tier requires a feed and optionally a tier: high, normal or low")
+	}
+
+	tier := tierNormal
+	if len(args) == 2 {
+		tier = strings.ToLower(args[1])
+		if tier != tierHigh && tier != tierNormal && tier != tierLow {
+			return usageErrorf("unknown tier %s, expected high, normal or low", args[1])
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	feed, err := exactFeed(ctx, s, args[0])
+	if err != nil {
+		return err
+	}
+	if err := requireFeedOwner(ctx, s, feed); err != nil {
+		return err
+	}
+
+	err = s.db.SetFeedTier(ctx, database.SetFeedTierParams{
+		ID:   feed.ID,
+		Tier: tier,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update feed: %v", err)
+	}
+
+	infof("%s is now in the %s tier\n", feed.Name, tier)
+	return nil
+}
+
+// feedPause stops agg and the daemon fetching a feed until it is resumed.
+// Its posts, follows and read history stay as they are; agg --feed and
+// refresh still fetch it when asked to by name. Pausing stops the feed for
+// every follower, so only its owner or an admin may do it.
+func feedPause(ctx context.Context, s *state, args []string, pause bool) error {
+	verb := "resume"
+	if pause {
+		verb = "pause"
+	}
+	if len(args) == 0 {
+		return usageErrorf("feed %s requires a feed", verb)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	feed, err := exactFeed(ctx, s, strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	if err := requireFeedOwner(ctx, s, feed); err != nil {
+		return err
+	}
+
+	var changed int64
+	if pause {
+		changed, err = s.db.PauseFeed(ctx, feed.ID)
+	} else {
+		changed, err = s.db.ResumeFeed(ctx, feed.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s feed: %v", verb, err)
+	}
+
+	switch {
+	case changed == 0 && pause:
+		infof("%s is already paused\n", feed.Name)
+	case changed == 0:
+		infof("%s is not paused\n", feed.Name)
+	case pause:
+		infof("Paused %s; agg skips it until feed resume\n", feed.Name)
+	default:
+		infof("Resumed %s\n", feed.Name)
+	}
+	return nil
+}
+
+// feedDelete removes a feed with its posts for everyone. Only the user who
+// added the feed, or an admin, may delete it.
+func feedDelete(ctx context.Context, s *state, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("feed delete requires a feed")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	feed, err := exactFeed(ctx, s, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := requireFeedOwner(ctx, s, feed); err != nil {
+		return err
+	}
+
+	if _, err := s.db.DeleteFeed(ctx, feed.ID); err != nil {
+		return fmt.Errorf("failed to delete feed: %v", err)
+	}
+
+	infof("Deleted %s\n", feed.Name)
+	return nil
+}
+
+// exactFeed looks up the feed for a subcommand whose effect reaches
+// every user, like delete. Unlike findFeed it only takes the feed's URL,
+// one of the current user's aliases or its exact name, so a typo can't
+// land on some other feed.
+func exactFeed(ctx context.Context, s *state, ref string) (database.Feed, error) {
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return database.Feed{}, err
+	}
+	feed, err := lookupFeed(ctx, s, user.ID, ref)
+	if err == sql.ErrNoRows {
+		return feed, notFoundErrorf("no feed has the URL, alias or name %s; this needs one of those in full", ref)
+	}
+	if err != nil {
+		return feed, fmt.Errorf("failed to get feed: %v", err)
+	}
+	return feed, nil
+}
+
+// requireFeedOwner fails unless the current user added feed or is an
+// admin.
+func requireFeedOwner(ctx context.Context, s *state, feed database.Feed) error {
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+	if feed.UserID == user.ID {
+		return nil
+	}
+	if err := requireAdmin(ctx, s); err != nil {
+		return fmt.Errorf("%s was added by another user: %w", feed.Name, err)
+	}
+	return nil
+}
+
+// feedShare makes a feed one every user follows, now and when they
+// register, for a team's common sources; each still has their own read
+// and starred posts. Unsharing stops new users following the feed and
+// leaves existing follows alone. Only the feed's owner or an admin may
+// change it.
+func feedShare(ctx context.Context, s *state, args []string, shared bool) error {
+	verb := "unshare"
+	if shared {
+		verb = "share"
+	}
+	if len(args) == 0 {
+		return usageErrorf("feed %s requires a feed", verb)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	feed, err := exactFeed(ctx, s, strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	if err := requireFeedOwner(ctx, s, feed); err != nil {
+		return err
+	}
+
+	var changed, followed int64
+	err = s.withTx(ctx, func(tx *state) error {
+		changed, err = tx.db.SetFeedShared(ctx, database.SetFeedSharedParams{ID: feed.ID, Shared: shared})
+		if err != nil {
+			return fmt.Errorf("failed to %s feed: %v", verb, err)
+		}
+		if !shared {
+			return nil
+		}
+		// sharing again follows it for anyone who unfollowed it since
+		followed, err = tx.db.FollowFeedForAllUsers(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("failed to follow feed: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case shared:
+		infof("Shared %s; %d more users follow it\n", feed.Name, followed)
+	case changed == 0:
+		infof("%s is not shared\n", feed.Name)
+	default:
+		infof("%s is no longer shared; its followers keep it\n", feed.Name)
+	}
+	return nil
+}
+
+// feedChown hands a feed over to another user, e.g. before its owner is
+// removed, which only the owner or an admin may do. Every change is kept
+// with who made it; with only a feed it lists them.
+func feedChown(ctx context.Context, s *state, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return usageErrorf("feed chown requires a feed and optionally the user to give it to")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	feed, err := exactFeed(ctx, s, args[0])
+	if err != nil {
+		return err
+	}
+
+	owner, err := s.db.GetUserById(ctx, feed.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get owner: %v", err)
+	}
+
+	if len(args) == 1 {
+		changes, err := s.db.GetFeedOwnerChanges(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get owner changes: %v", err)
+		}
+		fmt.Printf("%s belongs to %s\n", feed.Name, owner.Name)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, change := range changes {
+			fmt.Fprintf(w, "%s\t%s -> %s\tby %s\n", change.ChangedAt.Local().Format(time.DateTime),
+				change.FromUser, change.ToUser, change.ChangedBy)
+		}
+		return w.Flush()
+	}
+
+	if err := requireFeedOwner(ctx, s, feed); err != nil {
+		return err
+	}
+
+	var to database.User
+	err = s.withTx(ctx, func(tx *state) error {
+		users, err := tx.db.GetUsersByName(ctx, args[1])
+		if err != nil {
+			return fmt.Errorf("failed to get user: %v", err)
+		}
+		if len(users) == 0 {
+			return notFoundErrorf("user %s does not exist", args[1])
+		}
+		to = users[0]
+		if to.ID == owner.ID {
+			return conflictErrorf("%s already belongs to %s", feed.Name, to.Name)
+		}
+
+		if err := tx.db.SetFeedOwner(ctx, database.SetFeedOwnerParams{ID: feed.ID, UserID: to.ID}); err != nil {
+			return fmt.Errorf("failed to update feed: %v", err)
+		}
+		if err := tx.db.RecordFeedOwnerChange(ctx, database.RecordFeedOwnerChangeParams{
+			FeedID:    feed.ID,
+			FromUser:  owner.Name,
+			ToUser:    to.Name,
+			ChangedBy: s.Config.CurrentUserName,
+		}); err != nil {
+			return fmt.Errorf("failed to record owner change: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	infof("%s now belongs to %s\n", feed.Name, to.Name)
+	return nil
+}
+
+// feedSetHeader adds a header, such as a Referer or a Cookie, to every
+// request for a feed; leaving out the value removes it. With only a feed
+// it lists the headers set.
+func feedSetHeader(ctx context.Context, s *state, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("feed set-header requires a feed, a header name and optionally a value")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	feed, err := exactFeed(ctx, s, args[0])
+	if err != nil {
+		return err
+	}
+	// headers can carry cookies, so even listing them is the owner's
+	if err := requireFeedOwner(ctx, s, feed); err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		headers, err := s.db.GetFeedHeaders(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get headers: %v", err)
+		}
+		if len(headers) == 0 {
+			fmt.Printf("%s has no custom headers\n", feed.Name)
+		}
+		for _, header := range headers {
+			fmt.Printf("%s: %s\n", header.Name, header.Value)
+		}
+		return nil
+	}
+
+	name := http.CanonicalHeaderKey(strings.TrimSuffix(strings.TrimSpace(args[1]), ":"))
+	if name == "" || strings.ContainsAny(name, " \t\r\n") {
+		return usageErrorf("invalid header name %q", args[1])
+	}
+
+	value := strings.TrimSpace(strings.Join(args[2:], " "))
+	if strings.ContainsAny(value, "\r\n") {
+		return usageErrorf("header values can't span lines")
+	}
+
+	if value == "" {
+		removed, err := s.db.DeleteFeedHeader(ctx, database.DeleteFeedHeaderParams{
+			FeedID: feed.ID,
+			Name:   name,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update feed: %v", err)
+		}
+		if removed == 0 {
+			return fmt.Errorf("%s has no %s header", feed.Name, name)
+		}
+		infof("%s no longer sends %s\n", feed.Name, name)
+		return nil
+	}
+
+	err = s.db.SetFeedHeader(ctx, database.SetFeedHeaderParams{
+		FeedID: feed.ID,
+		Name:   name,
+		Value:  value,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update feed: %v", err)
+	}
+
+	infof("%s now sends %s: %s\n", feed.Name, name, value)
+	return nil
+}
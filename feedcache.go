@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// feedCacheKeep is how many distinct bodies are kept per feed.
+const feedCacheKeep = 5
+
+// feedCache keeps raw feed bodies on disk, one directory per feed URL and
+// one file per distinct body, so feeds can be re-parsed or re-aggregated
+// without downloading them again.
+type feedCache struct {
+	dir string
+}
+
+func hashKey(value []byte) string {
+	sum := sha256.Sum256(value)
+	return hex.EncodeToString(sum[:16])
+}
+
+func (c feedCache) feedDir(feedURL string) string {
+	return filepath.Join(c.dir, hashKey([]byte(feedURL)))
+}
+
+// Store saves data for feedURL and returns the file it was written to.
+// Storing a body that's already cached only refreshes its timestamp.
+func (c feedCache) Store(feedURL string, data []byte) (string, error) {
+	dir := c.feedDir(feedURL)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	path := filepath.Join(dir, hashKey(data)+".xml")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write cache: %v", err)
+	}
+
+	// the URL is kept next to the bodies so the cache can be browsed by hand
+	if err := os.WriteFile(filepath.Join(dir, "url"), []byte(feedURL+"\n"), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write cache: %v", err)
+	}
+
+	if err := c.prune(dir); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// Latest returns the most recently stored body for feedURL.
+func (c feedCache) Latest(feedURL string) ([]byte, error) {
+	entries, err := c.bodies(c.feedDir(feedURL))
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no cached copy of %s", feedURL)
+	}
+	return os.ReadFile(entries[0])
+}
+
+// bodies lists the cached files in dir, newest first.
+func (c feedCache) bodies(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+
+	modTimes := make(map[string]int64, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[path] = info.ModTime().UnixNano()
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		return modTimes[paths[i]] > modTimes[paths[j]]
+	})
+
+	return paths, nil
+}
+
+func (c feedCache) prune(dir string) error {
+	paths, err := c.bodies(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths[min(feedCacheKeep, len(paths)):] {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to prune cache: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// findFeed looks a feed up by whatever the user typed: its URL, one of
+// the current user's aliases, its name, a prefix of its name or, failing
+// those, a fuzzy match on the name. Each step is only tried when the one
+// before found nothing. When a step finds several feeds the user picks
+// one, if there is a terminal to ask on, which is why the lookups bound
+// themselves rather than run under the caller's timeout. It returns
+// sql.ErrNoRows when nothing matches; see exactFeed for commands that
+// shouldn't guess.
+func findFeed(ctx context.Context, s *state, ref string) (database.Feed, error) {
+	ref = strings.TrimSpace(ref)
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	feed, err := findFeedByURL(ctx, s, ref)
+	if err != sql.ErrNoRows {
+		return feed, err
+	}
+
+	users, err := s.db.GetUsersByName(ctx, s.Config.CurrentUserName)
+	if err != nil {
+		return database.Feed{}, err
+	}
+	if len(users) > 0 {
+		feed, err := findFeedByAlias(ctx, s, users[0].ID, ref)
+		if err != sql.ErrNoRows {
+			return feed, err
+		}
+	}
+
+	feeds, err := s.db.GetFeeds(ctx)
+	if err != nil {
+		return database.Feed{}, err
+	}
+
+	query := strings.ToLower(ref)
+	matchers := []func(name string) bool{
+		func(name string) bool { return name == query },
+		func(name string) bool { return strings.HasPrefix(name, query) },
+		func(name string) bool { return strings.Contains(name, query) },
+		func(name string) bool { return isSubsequence(query, name) },
+	}
+
+	for _, matches := range matchers {
+		var found []database.Feed
+		for _, feed := range feeds {
+			if matches(strings.ToLower(feed.Name)) {
+				found = append(found, feed)
+			}
+		}
+
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], nil
+		default:
+			return chooseFeed(ref, found)
+		}
+	}
+
+	return database.Feed{}, sql.ErrNoRows
+}
+
+// lookupFeed looks a feed up by its URL, one of userID's aliases or its
+// exact name only, never asking which of several was meant, for callers
+// with nobody to ask, like the API. It returns sql.ErrNoRows when nothing
+// matches.
+func lookupFeed(ctx context.Context, s *state, userID uuid.UUID, ref string) (database.Feed, error) {
+	ref = strings.TrimSpace(ref)
+
+	feed, err := findFeedByURL(ctx, s, ref)
+	if err != sql.ErrNoRows {
+		return feed, err
+	}
+
+	feed, err = findFeedByAlias(ctx, s, userID, ref)
+	if err != sql.ErrNoRows {
+		return feed, err
+	}
+
+	feeds, err := s.db.GetFeedsByName(ctx, ref)
+	if err != nil {
+		return database.Feed{}, err
+	}
+	if len(feeds) == 0 {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	return feeds[0], nil
+}
+
+// feedFilter resolves a --feed flag with findFeed into the feed ID a
+// query filters by, which is null when the flag wasn't given.
+func feedFilter(ctx context.Context, s *state, ref string) (uuid.NullUUID, error) {
+	if ref == "" {
+		return uuid.NullUUID{}, nil
+	}
+	feed, err := findFeed(ctx, s, ref)
+	if err == sql.ErrNoRows {
+		return uuid.NullUUID{}, notFoundErrorf("feed %s does not exist", ref)
+	}
+	if err != nil {
+		return uuid.NullUUID{}, fmt.Errorf("failed to get feed: %v", err)
+	}
+	return uuid.NullUUID{UUID: feed.ID, Valid: true}, nil
+}
+
+// isSubsequence reports whether the letters of query appear in s in
+// order, so "hnws" matches "hacker news".
+func isSubsequence(query, s string) bool {
+	if query == "" {
+		return false
+	}
+
+	rest := []rune(query)
+	for _, r := range s {
+		if r == rest[0] {
+			rest = rest[1:]
+			if len(rest) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// chooseFeed asks the user which of several matching feeds they meant,
+// with the picker where the terminal allows. Without a terminal on stdin
+// there is nobody to ask, so it fails with the candidates listed instead.
+func chooseFeed(ref string, feeds []database.Feed) (database.Feed, error) {
+	if !stdinIsTerminal() {
+		names := make([]string, 0, len(feeds))
+		for _, feed := range feeds {
+			names = append(names, feed.Name)
+		}
+		return database.Feed{}, fmt.Errorf("%q matches several feeds: %s", ref, strings.Join(names, ", "))
+	}
+
+	if canPick() {
+		items := make([]string, 0, len(feeds))
+		for _, feed := range feeds {
+			items = append(items, fmt.Sprintf("%s (%s)", feed.Name, feed.Url))
+		}
+		choice, err := pick(fmt.Sprintf("%q matches several feeds", ref), items)
+		if err != nil {
+			return database.Feed{}, fmt.Errorf("no feed chosen")
+		}
+		return feeds[choice], nil
+	}
+
+	fmt.Printf("%q matches several feeds:\n", ref)
+	for i, feed := range feeds {
+		fmt.Printf("%d. %s (%s)\n", i+1, feed.Name, feed.Url)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Which one? [1-%d] ", len(feeds))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return database.Feed{}, fmt.Errorf("no feed chosen")
+		}
+
+		choice, err := strconv.Atoi(strings.TrimSpace(line))
+		if err == nil && choice >= 1 && choice <= len(feeds) {
+			return feeds[choice-1], nil
+		}
+	}
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// normalizeFeedURL puts a feed URL into the single form gator stores:
+// lowercase scheme and host, no default port, no fragment and no trailing
+// slash. Inputs without a scheme are assumed to be https.
+func normalizeFeedURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid feed URL %s: %v", raw, err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid feed URL %s: scheme must be http or https", raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid feed URL %s: missing host", raw)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	switch {
+	case port != "":
+		// JoinHostPort puts IPv6 literals back in their brackets
+		host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		host = "[" + host + "]"
+	}
+	u.Host = host
+
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+
+	return u.String(), nil
+}
+
+// feedURLVariants lists the spellings a feed could have been stored under:
+// the input as typed, its normalized form, and that form with the other
+// scheme, so http/https duplicates are caught.
+func feedURLVariants(raw string) []string {
+	variants := []string{raw}
+
+	normalized, err := normalizeFeedURL(raw)
+	if err != nil {
+		return variants
+	}
+	variants = append(variants, normalized)
+
+	if rest, ok := strings.CutPrefix(normalized, "https://"); ok {
+		variants = append(variants, "http://"+rest)
+	} else if rest, ok := strings.CutPrefix(normalized, "http://"); ok {
+		variants = append(variants, "https://"+rest)
+	}
+
+	return variants
+}
+
+// findFeedByURL looks a feed up by any of its URL variants. It returns
+// sql.ErrNoRows when none of them is stored.
+func findFeedByURL(ctx context.Context, s *state, raw string) (database.Feed, error) {
+	for _, variant := range feedURLVariants(raw) {
+		feed, err := s.db.GetFeedByUrl(ctx, variant)
+		if err == nil {
+			return feed, nil
+		}
+		if err != sql.ErrNoRows {
+			return database.Feed{}, err
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/necodeus/gator/internal/config"
+	"github.com/necodeus/gator/internal/database"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newHTTPClient builds the one client every fetch goes through. Its
+// transport keeps connections to each host open between requests and
+// resumes TLS sessions, so aggregating many feeds from the same few
+// hosts skips most handshakes. The knobs come from the config; in fixture
+// mode the transport is wrapped to record or replay responses.
+func newHTTPClient(cfg *config.Config) (*http.Client, error) {
+	idleConnTimeout, err := cfg.IdleConnTimeoutDuration()
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     !cfg.DisableHTTP2,
+		MaxIdleConns:          cfg.MaxIdleConnsOrDefault(),
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHostOrDefault(),
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		TLSClientConfig: &tls.Config{
+			ClientSessionCache: tls.NewLRUClientSessionCache(cfg.TLSSessionCacheSizeOrDefault()),
+		},
+	}
+	if cfg.DisableHTTP2 {
+		// a non-nil, empty map is what keeps the transport on HTTP/1.1
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	client := &http.Client{Transport: transport}
+
+	if cfg.FixtureMode != "" {
+		fixtureDir, err := cfg.FixtureDirPath()
+		if err != nil {
+			return nil, err
+		}
+		fixtures, err := newFixtureTransport(cfg.FixtureMode, fixtureDir, transport)
+		if err != nil {
+			return nil, err
+		}
+		client.Transport = fixtures
+	}
+
+	return client, nil
+}
+
+// requestOptions are what a request for one feed carries besides its URL.
+type requestOptions struct {
+	userAgent string
+	headers   []database.FeedHeader
+	auth      *feedAuth
+	// maxSize caps the response body in bytes; 0 means no limit
+	maxSize int64
+}
+
+// requestOptions returns the options feed is fetched with. A nil feed
+// gets the global ones.
+func (s *state) requestOptions(ctx context.Context, feed *database.Feed) (requestOptions, error) {
+	opts := requestOptions{userAgent: s.userAgent(feed), maxSize: s.maxFeedSize}
+	if feed == nil {
+		return opts, nil
+	}
+
+	auth, err := s.feedAuthFor(feed)
+	if err != nil {
+		return requestOptions{}, err
+	}
+	opts.auth = auth
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	opts.headers, err = s.db.GetFeedHeaders(dbCtx, feed.ID)
+	if err != nil {
+		return requestOptions{}, fmt.Errorf("failed to get headers for %s: %v", feed.Name, err)
+	}
+
+	return opts, nil
+}
+
+// apply sets up req as the options ask. The feed's own headers override
+// gator's, and credentials override both.
+func (opts requestOptions) apply(req *http.Request) {
+	req.Header.Set("User-Agent", opts.userAgent)
+	for _, header := range opts.headers {
+		req.Header.Set(header.Name, header.Value)
+	}
+	opts.auth.apply(req)
+}
+
+// badStatusError is a feed answering with anything but 200 OK.
+type badStatusError struct {
+	code   int
+	status string
+}
+
+func (e *badStatusError) Error() string {
+	return fmt.Sprintf("bad response status: %s", e.status)
+}
+
+// FeedFetcher opens feeds for reading. Everything that fetches a feed
+// goes through the one on state, so tests can serve canned bodies
+// without the network.
+type FeedFetcher interface {
+	// Fetch returns the body of the feed at feedURL for the caller to
+	// read and close, as openFeed does.
+	Fetch(ctx context.Context, feedURL string, opts requestOptions) (body io.ReadCloser, permanentURL string, err error)
+}
+
+// httpFetcher fetches feeds over HTTP with client.
+type httpFetcher struct {
+	client *http.Client
+}
+
+func (f httpFetcher) Fetch(ctx context.Context, feedURL string, opts requestOptions) (io.ReadCloser, string, error) {
+	return openFeed(ctx, f.client, feedURL, opts)
+}
+
+func fetchFeed(ctx context.Context, fetcher FeedFetcher, feedURL string, opts requestOptions, maxItems int) (*RSSFeed, error) {
+	body, permanentURL, err := fetcher.Fetch(ctx, feedURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	feed, err := parseFeed(body, maxItems)
+	if err != nil {
+		return nil, err
+	}
+	feed.PermanentURL = permanentURL
+
+	return feed, nil
+}
+
+// bodyTooLargeError is a feed response over the configured size limit.
+type bodyTooLargeError struct {
+	limit int64
+}
+
+func (e *bodyTooLargeError) Error() string {
+	return fmt.Sprintf("response is larger than %s, see max_feed_size_mb", formatBytes(e.limit))
+}
+
+// limitedBody reads at most limit bytes of a response body and fails
+// with bodyTooLargeError past that, rather than ending early the way
+// io.LimitReader alone would, which parsers take for a truncated feed.
+type limitedBody struct {
+	io.Reader
+	body  io.Closer
+	limit int64
+	read  int64
+}
+
+func newLimitedBody(body io.ReadCloser, limit int64) *limitedBody {
+	return &limitedBody{Reader: io.LimitReader(body, limit+1), body: body, limit: limit}
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return 0, &bodyTooLargeError{limit: b.limit}
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
+
+// openFeed requests a feed and returns its body for the caller to read
+// and close. permanentURL is set when the feed was reached only through
+// permanent redirects. A body over opts.maxSize fails reading with
+// bodyTooLargeError.
+func openFeed(ctx context.Context, httpClient *http.Client, feedURL string, opts requestOptions) (body io.ReadCloser, permanentURL string, err error) {
+	// the span covers everything up to the response headers; reading the
+	// body is the caller's
+	ctx, span := tracer.Start(ctx, "feed.fetch", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() { endSpan(span, err) }()
+	ctx = withClientTrace(ctx)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("creating request: %w", err)
+	}
+	opts.apply(req)
+
+	// only a chain made entirely of permanent redirects means the feed moved
+	redirected, permanent := false, true
+	client := *httpClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		redirected = true
+		switch req.Response.StatusCode {
+		case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+		default:
+			permanent = false
+		}
+		return nil
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// the request URL may carry a token, so report the feed's own
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = feedURL
+		}
+		return nil, "", fmt.Errorf("fetching feed: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, "", &badStatusError{code: resp.StatusCode, status: resp.Status}
+	}
+
+	if opts.maxSize > 0 && resp.ContentLength > opts.maxSize {
+		resp.Body.Close()
+		return nil, "", &bodyTooLargeError{limit: opts.maxSize}
+	}
+
+	if redirected && permanent {
+		permanentURL = opts.auth.strip(resp.Request.URL).String()
+	}
+
+	if opts.maxSize > 0 {
+		return newLimitedBody(resp.Body, opts.maxSize), permanentURL, nil
+	}
+	return resp.Body, permanentURL, nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// testFeed is a small RSS feed for fakeFetcher to serve.
+const testFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+<channel>
+  <title>Example Blog</title>
+  <link>https://example.com/</link>
+  <description>Posts from example.com</description>
+  <item>
+    <title>Second post</title>
+    <link>https://example.com/2</link>
+    <pubDate>Tue, 02 Jan 2024 10:00:00 +0000</pubDate>
+  </item>
+  <item>
+    <title>First post</title>
+    <link>https://example.com/1</link>
+    <pubDate>Mon, 01 Jan 2024 10:00:00 +0000</pubDate>
+  </item>
+</channel>
+</rss>`
+
+// fakeFetcher serves canned bodies by URL in place of the network,
+// answering 404 for anything else.
+type fakeFetcher struct {
+	bodies map[string]string
+	// fetched lists the URLs asked for, in order
+	fetched []string
+}
+
+func (f *fakeFetcher) Fetch(ctx context.Context, feedURL string, opts requestOptions) (io.ReadCloser, string, error) {
+	f.fetched = append(f.fetched, feedURL)
+	body, ok := f.bodies[feedURL]
+	if !ok {
+		return nil, "", &badStatusError{code: http.StatusNotFound, status: "404 Not Found"}
+	}
+	return io.NopCloser(strings.NewReader(body)), "", nil
+}
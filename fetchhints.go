@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// fetchHints are what a publisher asks of pollers: a minimum interval
+// from <ttl> or the syndication module, and hours (GMT) and days on which
+// not to poll at all, as bitmasks.
+type fetchHints struct {
+	interval  time.Duration
+	skipHours int32
+	skipDays  int32
+}
+
+var syndicationPeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// fetchHintsFor reads the polling hints out of a parsed feed, ignoring
+// any that don't parse. When both <ttl> and sy:updatePeriod are given
+// the longer interval wins.
+func fetchHintsFor(rss *RSSFeed) fetchHints {
+	var hints fetchHints
+	channel := rss.Channel
+
+	if ttl, err := strconv.Atoi(strings.TrimSpace(channel.TTL)); err == nil && ttl > 0 {
+		hints.interval = time.Duration(ttl) * time.Minute
+	}
+
+	if period, ok := syndicationPeriods[strings.ToLower(strings.TrimSpace(channel.UpdatePeriod))]; ok {
+		frequency, err := strconv.Atoi(strings.TrimSpace(channel.UpdateFrequency))
+		if err != nil || frequency <= 0 {
+			frequency = 1
+		}
+		hints.interval = max(hints.interval, period/time.Duration(frequency))
+	}
+
+	for _, value := range channel.SkipHours {
+		// RSS hours run 0-23, though some feeds write 24 for midnight
+		if hour, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && hour >= 0 && hour <= 24 {
+			hints.skipHours |= 1 << (hour % 24)
+		}
+	}
+
+	for _, value := range channel.SkipDays {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(strings.TrimSpace(value), day.String()) {
+				hints.skipDays |= 1 << day
+			}
+		}
+	}
+
+	return hints
+}
+
+// skipped reports whether the feed asked not to be polled at t.
+func (h fetchHints) skipped(t time.Time) bool {
+	t = t.UTC()
+	return h.skipHours&(1<<t.Hour()) != 0 || h.skipDays&(1<<t.Weekday()) != 0
+}
+
+// storedFetchHints reads back the hints saved for feed.
+func storedFetchHints(feed database.Feed) fetchHints {
+	return fetchHints{
+		interval:  time.Duration(feed.PollIntervalSeconds.Int32) * time.Second,
+		skipHours: feed.SkipHours.Int32,
+		skipDays:  feed.SkipDays.Int32,
+	}
+}
+
+func saveFetchHints(ctx context.Context, s *state, feed database.Feed, rss *RSSFeed) error {
+	hints := fetchHintsFor(rss)
+	if hints == storedFetchHints(feed) {
+		return nil
+	}
+
+	seconds := int32(hints.interval / time.Second)
+	return s.db.UpdateFeedFetchHints(ctx, database.UpdateFeedFetchHintsParams{
+		ID:                  feed.ID,
+		PollIntervalSeconds: sql.NullInt32{Int32: seconds, Valid: seconds > 0},
+		SkipHours:           sql.NullInt32{Int32: hints.skipHours, Valid: hints.skipHours != 0},
+		SkipDays:            sql.NullInt32{Int32: hints.skipDays, Valid: hints.skipDays != 0},
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	fixtureModeRecord = "record"
+	fixtureModeReplay = "replay"
+)
+
+// fixtureTransport records every HTTP response to a directory, or replays
+// previously recorded ones without touching the network. It makes fetches
+// deterministic for integration tests and lets a misbehaving feed be
+// debugged offline.
+type fixtureTransport struct {
+	mode string
+	dir  string
+	next http.RoundTripper
+}
+
+func newFixtureTransport(mode, dir string, next http.RoundTripper) (*fixtureTransport, error) {
+	if mode != fixtureModeRecord && mode != fixtureModeReplay {
+		return nil, fmt.Errorf("invalid fixture_mode %q, expected %s or %s", mode, fixtureModeRecord, fixtureModeReplay)
+	}
+
+	if mode == fixtureModeRecord {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create fixture directory: %v", err)
+		}
+	}
+
+	return &fixtureTransport{mode: mode, dir: dir, next: next}, nil
+}
+
+// fixturePath names the file a request's response is stored in.
+func (t *fixtureTransport) fixturePath(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.String()))
+	return filepath.Join(t.dir, hex.EncodeToString(sum[:16])+".http")
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := t.fixturePath(req)
+
+	if t.mode == fixtureModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("no fixture recorded for %s", req.URL)
+			}
+			return nil, err
+		}
+		return http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := httputil.DumpResponse(resp, true)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("recording fixture: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("recording fixture: %w", err)
+	}
+
+	return http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+}
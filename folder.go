@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// handlerFolder manages the current user's folders: virtual feeds that
+// gather the followed posts matching their keywords, tags and authors
+// from whichever feeds they come from. Nothing is copied into a folder;
+// browse --folder works its posts out when asked.
+func handlerFolder(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return folderList(ctx, s, nil)
+	}
+
+	switch cmd.Args[0] {
+	case "add":
+		return folderAdd(ctx, s, cmd.Args[1:])
+	case "remove":
+		return folderRemove(ctx, s, cmd.Args[1:])
+	case "list":
+		return folderList(ctx, s, cmd.Args[1:])
+	default:
+		return usageErrorf("unknown folder subcommand: %s", cmd.Args[0])
+	}
+}
+
+// folderAdd defines a folder, or redefines one of the same name.
+func folderAdd(ctx context.Context, s *state, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("folder add requires a name")
+	}
+	name := strings.TrimSpace(args[0])
+	if name == "" {
+		return usageErrorf("folder name must not be empty")
+	}
+
+	var keywords, tags, authors stringList
+	fs := flag.NewFlagSet("folder add", flag.ContinueOnError)
+	fs.Var(&keywords, "keyword", "include posts whose titles match this, in search syntax (repeatable)")
+	fs.Var(&tags, "tag", "include posts with this tag, or from feeds with it (repeatable)")
+	fs.Var(&authors, "author", "include posts by this author (repeatable)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("unexpected argument %s, quote folder names with spaces", fs.Arg(0))
+	}
+	if len(keywords) == 0 && len(tags) == 0 && len(authors) == 0 {
+		return usageErrorf("folder add requires at least one --keyword, --tag or --author")
+	}
+
+	// authors are matched ignoring case
+	for i, author := range authors {
+		authors[i] = strings.ToLower(strings.TrimSpace(author))
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.SaveFolder(ctx, database.SaveFolderParams{
+		UserID:   user.ID,
+		Name:     name,
+		Keywords: nonNil(keywords),
+		Tags:     nonNil(tags),
+		Authors:  nonNil(authors),
+	}); err != nil {
+		return fmt.Errorf("failed to save folder: %v", err)
+	}
+
+	infof("Saved folder %s, browse it with browse --folder %q\n", name, name)
+	return nil
+}
+
+// nonNil keeps an empty list from reaching the NOT NULL array columns as
+// NULL.
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+func folderRemove(ctx context.Context, s *state, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("folder remove requires a folder name")
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.db.DeleteFolder(ctx, database.DeleteFolderParams{UserID: user.ID, Name: args[0]})
+	if err != nil {
+		return fmt.Errorf("failed to remove folder: %v", err)
+	}
+	if removed == 0 {
+		return notFoundErrorf("folder %s does not exist", args[0])
+	}
+
+	infof("Removed folder %s\n", args[0])
+	return nil
+}
+
+func folderList(ctx context.Context, s *state, args []string) error {
+	if len(args) > 0 {
+		return usageErrorf("folder list takes no arguments")
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	folders, err := s.db.GetFoldersForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get folders: %v", err)
+	}
+	if len(folders) == 0 {
+		fmt.Println("No folders, add one with folder add <name> --keyword <keyword>")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, folder := range folders {
+		fmt.Fprintf(w, "%s\t%s\n", folder.Name, folderRule(folder))
+	}
+	return w.Flush()
+}
+
+// folderRule describes what a folder gathers, e.g.
+// "keywords release; tags golang, go".
+func folderRule(folder database.Folder) string {
+	var parts []string
+	for _, part := range []struct {
+		label  string
+		values []string
+	}{
+		{"keywords", folder.Keywords},
+		{"tags", folder.Tags},
+		{"authors", folder.Authors},
+	} {
+		if len(part.values) > 0 {
+			parts = append(parts, part.label+" "+strings.Join(part.values, ", "))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// getFolder looks up one of the current user's folders by name.
+func getFolder(ctx context.Context, s *state, user database.User, name string) (database.Folder, error) {
+	folder, err := s.db.GetFolder(ctx, database.GetFolderParams{UserID: user.ID, Name: name})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return folder, notFoundErrorf("folder %s does not exist, add it with folder add", name)
+		}
+		return folder, fmt.Errorf("failed to get folder: %v", err)
+	}
+	return folder, nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+func handlerFollow(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return usageErrorf("follow command requires a feed name or URL")
+	}
+
+	ref := strings.Join(cmd.Args, " ")
+	feed, err := findFeed(ctx, s, ref)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundErrorf("feed %s does not exist, add it with addfeed first", ref)
+		}
+		return fmt.Errorf("failed to get feed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:     uuid.New(),
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to follow feed: %v", err)
+	}
+
+	infof("%s is now following %s\n", user.Name, feed.Name)
+
+	return nil
+}
+
+func handlerFollowing(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("following", flag.ContinueOnError)
+	table := addTableFlags(fs)
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	tabular, err := table.tabular()
+	if err != nil {
+		return err
+	}
+	columns, err := selectColumns(table, followColumns, []string{"name", "url", "tags"})
+	if err != nil {
+		return err
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get follows: %v", err)
+	}
+
+	tags, err := s.db.GetFeedTagsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get tags: %v", err)
+	}
+
+	feedTags := make(map[uuid.UUID][]string)
+	for _, tag := range tags {
+		feedTags[tag.FeedID] = append(feedTags[tag.FeedID], tag.Tag)
+	}
+
+	if tabular {
+		rows := make([]followRow, 0, len(follows))
+		for _, follow := range follows {
+			rows = append(rows, followRow{follow, feedTags[follow.FeedID]})
+		}
+		return writeTable(table, columns, rows)
+	}
+
+	for _, follow := range follows {
+		if tags := feedTags[follow.FeedID]; len(tags) > 0 {
+			fmt.Printf("- %s (%s) [%s]\n", follow.FeedName, follow.FeedUrl, strings.Join(tags, ", "))
+			continue
+		}
+		fmt.Printf("- %s (%s)\n", follow.FeedName, follow.FeedUrl)
+	}
+
+	folders, err := s.db.GetFoldersForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get folders: %v", err)
+	}
+	for _, folder := range folders {
+		fmt.Printf("- %s (folder: %s)\n", folder.Name, folderRule(folder))
+	}
+
+	return nil
+}
+
+// followRow is a follow as the following command lists it.
+type followRow struct {
+	Follow database.GetFeedFollowsForUserRow
+	Tags   []string
+}
+
+var followColumns = []tableColumn[followRow]{
+	{"feed_id", func(r followRow) string { return r.Follow.FeedID.String() }},
+	{"name", func(r followRow) string { return r.Follow.FeedName }},
+	{"url", func(r followRow) string { return r.Follow.FeedUrl }},
+	{"tags", func(r followRow) string { return strings.Join(r.Tags, ",") }},
+	{"followed_at", func(r followRow) string { return tableTime(r.Follow.CreatedAt, true) }},
+}
+
+// handlerUnfollow unfollows the named feed or, without one, whichever
+// followed feed the user picks.
+func handlerUnfollow(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		if !canPick() {
+			return usageErrorf("unfollow command requires a feed name or URL")
+		}
+		return unfollowPicked(ctx, s)
+	}
+
+	ref := strings.Join(cmd.Args, " ")
+	feed, err := findFeed(ctx, s, ref)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundErrorf("feed %s does not exist", ref)
+		}
+		return fmt.Errorf("failed to get feed: %v", err)
+	}
+
+	return unfollow(ctx, s, feed)
+}
+
+// unfollowPicked lets the user pick one of the feeds they follow to
+// unfollow.
+func unfollowPicked(ctx context.Context, s *state) error {
+	queryCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(queryCtx, s)
+	if err != nil {
+		return err
+	}
+	follows, err := s.db.GetFeedFollowsForUser(queryCtx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get follows: %v", err)
+	}
+	if len(follows) == 0 {
+		return notFoundErrorf("%s is not following any feeds", user.Name)
+	}
+
+	items := make([]string, 0, len(follows))
+	for _, follow := range follows {
+		items = append(items, fmt.Sprintf("%s (%s)", follow.FeedName, follow.FeedUrl))
+	}
+	choice, err := pick("Unfollow", items)
+	if err != nil {
+		return err
+	}
+
+	return unfollow(ctx, s, database.Feed{ID: follows[choice].FeedID, Name: follows[choice].FeedName})
+}
+
+// unfollow stops the current user following feed.
+func unfollow(ctx context.Context, s *state, feed database.Feed) error {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.db.DeleteFeedFollow(ctx, database.DeleteFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unfollow feed: %v", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%s is not following %s", user.Name, feed.Name)
+	}
+
+	infof("%s unfollowed %s\n", user.Name, feed.Name)
+
+	return nil
+}
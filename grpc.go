@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+	gatorv1 "github.com/necodeus/gator/api/gator/v1"
+	"github.com/necodeus/gator/internal/database"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcServer implements the GatorService of api/gator/v1/gator.proto on
+// top of the same queries the commands and the HTTP API use.
+type grpcServer struct {
+	gatorv1.UnimplementedGatorServiceServer
+
+	s         *state
+	multiUser bool
+}
+
+// serveGRPC serves the gRPC API on addr until ctx is cancelled.
+func serveGRPC(ctx context.Context, s *state, addr string, multiUser bool) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := grpc.NewServer()
+	gatorv1.RegisterGatorServiceServer(server, &grpcServer{s: s, multiUser: multiUser})
+
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+
+	infof("Serving gRPC API on %s\n", addr)
+
+	if err := server.Serve(listener); !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// call bounds a call's database work and finds the user it acts for: the
+// owner of the bearer session token in multi-user mode, the current user
+// otherwise.
+func (g *grpcServer) call(ctx context.Context) (context.Context, context.CancelFunc, database.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.s.dbTimeout)
+
+	if !g.multiUser {
+		user, err := currentUser(ctx, g.s)
+		if err != nil {
+			cancel()
+			return nil, nil, database.User{}, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return ctx, cancel, user, nil
+	}
+
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get("authorization") {
+			if t, ok := strings.CutPrefix(value, "Bearer "); ok {
+				token = strings.TrimSpace(t)
+			}
+		}
+	}
+	if token == "" {
+		cancel()
+		return nil, nil, database.User{}, status.Error(codes.Unauthenticated, "missing bearer session token")
+	}
+
+	user, err := sessionUser(ctx, g.s, token)
+	if err != nil {
+		cancel()
+		if err == errBadLogin {
+			return nil, nil, database.User{}, status.Error(codes.Unauthenticated, "invalid or expired session")
+		}
+		return nil, nil, database.User{}, status.Error(codes.Internal, err.Error())
+	}
+	return ctx, cancel, user, nil
+}
+
+func (g *grpcServer) ListFeeds(ctx context.Context, req *gatorv1.ListFeedsRequest) (*gatorv1.ListFeedsResponse, error) {
+	ctx, cancel, user, err := g.call(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	feeds, err := g.s.db.GetFeedsWithFollowers(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get feeds: %v", err)
+	}
+	follows, err := g.s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get follows: %v", err)
+	}
+	followed := make(map[uuid.UUID]bool, len(follows))
+	for _, follow := range follows {
+		followed[follow.FeedID] = true
+	}
+
+	resp := &gatorv1.ListFeedsResponse{}
+	for _, row := range feeds {
+		feed := grpcFeed(row.Feed, followed[row.Feed.ID])
+		feed.Followers = row.Followers
+		resp.Feeds = append(resp.Feeds, feed)
+	}
+	return resp, nil
+}
+
+func (g *grpcServer) FollowFeed(ctx context.Context, req *gatorv1.FollowFeedRequest) (*gatorv1.FollowFeedResponse, error) {
+	if req.GetFeed() == "" {
+		return nil, status.Error(codes.InvalidArgument, "feed is required")
+	}
+
+	ctx, cancel, user, err := g.call(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	feed, err := lookupFeed(ctx, g.s, user.ID, req.GetFeed())
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "feed %s does not exist", req.GetFeed())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get feed: %v", err)
+	}
+
+	if _, err := g.s.db.CreateFeedFollowIfMissing(ctx, database.CreateFeedFollowIfMissingParams{
+		ID:     uuid.New(),
+		UserID: user.ID,
+		FeedID: feed.ID,
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to follow feed: %v", err)
+	}
+
+	return &gatorv1.FollowFeedResponse{Feed: grpcFeed(feed, true)}, nil
+}
+
+func (g *grpcServer) UnfollowFeed(ctx context.Context, req *gatorv1.UnfollowFeedRequest) (*gatorv1.UnfollowFeedResponse, error) {
+	feedID, err := uuid.Parse(req.GetFeedId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "feed_id must be a UUID")
+	}
+
+	ctx, cancel, user, err := g.call(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	rows, err := g.s.db.DeleteFeedFollow(ctx, database.DeleteFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feedID,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to unfollow feed: %v", err)
+	}
+	if rows == 0 {
+		return nil, status.Error(codes.NotFound, "not following that feed")
+	}
+
+	return &gatorv1.UnfollowFeedResponse{}, nil
+}
+
+func (g *grpcServer) Browse(ctx context.Context, req *gatorv1.BrowseRequest) (*gatorv1.BrowseResponse, error) {
+	sortBy := req.GetSort()
+	if sortBy == "" {
+		sortBy = "published"
+	}
+	sortBy, descending, err := postOrder(sortBy)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "sort must be one of %s", strings.Join(browseSorts, ", "))
+	}
+	if req.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	ctx, cancel, user, err := g.call(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		if limit, err = browseLimit(ctx, g.s, user); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	posts, err := g.s.db.GetPostsForUser(ctx, database.GetPostsForUserParams{
+		UserID:     user.ID,
+		SortBy:     sortBy,
+		Descending: descending,
+		MaxPosts:   int32(limit),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get posts: %v", err)
+	}
+
+	resp := &gatorv1.BrowseResponse{}
+	for _, row := range posts {
+		post := &gatorv1.Post{
+			Id:          row.Post.ID.String(),
+			FeedId:      row.Post.FeedID.String(),
+			FeedName:    row.FeedName,
+			Title:       row.Post.Title,
+			Url:         row.Post.Url,
+			Description: row.Post.Description.String,
+			Author:      row.Post.Author.String,
+			CreatedAt:   timestamppb.New(row.Post.CreatedAt),
+		}
+		if row.Post.PublishedAt.Valid {
+			post.PublishedAt = timestamppb.New(row.Post.PublishedAt.Time)
+		}
+		resp.Posts = append(resp.Posts, post)
+	}
+	return resp, nil
+}
+
+func (g *grpcServer) MarkRead(ctx context.Context, req *gatorv1.MarkReadRequest) (*gatorv1.MarkReadResponse, error) {
+	postID, err := uuid.Parse(req.GetPostId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "post_id must be a UUID")
+	}
+
+	ctx, cancel, user, err := g.call(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	if _, err := g.s.db.GetPostById(ctx, postID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "post %s does not exist", postID)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get post: %v", err)
+	}
+
+	if _, err := g.s.db.MarkPostRead(ctx, database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: postID,
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to mark post as read: %v", err)
+	}
+
+	return &gatorv1.MarkReadResponse{}, nil
+}
+
+func grpcFeed(feed database.Feed, followed bool) *gatorv1.Feed {
+	f := &gatorv1.Feed{
+		Id:       feed.ID.String(),
+		Name:     feed.Name,
+		Url:      feed.Url,
+		Followed: followed,
+	}
+	if feed.LastFetchedAt.Valid {
+		f.LastFetchedAt = timestamppb.New(feed.LastFetchedAt.Time)
+	}
+	return f
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed sql/schema/*.sql
+var schemaFiles embed.FS
+
+// latestSchemaVersion returns the highest goose migration number bundled
+// with this build.
+func latestSchemaVersion() (int64, error) {
+	names, err := fs.Glob(schemaFiles, "sql/schema/*.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(strings.TrimPrefix(name, "sql/schema/"), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected migration name %s", name)
+		}
+		latest = max(latest, version)
+	}
+
+	return latest, nil
+}
+
+// handlerHealthcheck checks, in order, that the database is reachable,
+// that it is migrated to the version this build expects, and that
+// outbound HTTP works. The config has been read by the time it runs;
+// main exits with exitConfig when it can't be.
+func handlerHealthcheck(ctx context.Context, s *state, cmd command) error {
+	flags := flag.NewFlagSet("healthcheck", flag.ContinueOnError)
+	checkURL := flags.String("url", "", "URL to fetch to check outbound HTTP (defaults to a stored feed)")
+	if err := flags.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+
+	fmt.Println("config: ok")
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	if err := s.db.Ping(dbCtx); err != nil {
+		return &exitCodeError{exitDatabase, fmt.Errorf("database unreachable: %v", err)}
+	}
+	fmt.Println("database: ok")
+
+	expected, err := latestSchemaVersion()
+	if err != nil {
+		return &exitCodeError{exitSchema, fmt.Errorf("failed to read bundled migrations: %v", err)}
+	}
+
+	var current int64
+	err = s.conn.QueryRowContext(dbCtx, "SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied").Scan(&current)
+	if err != nil {
+		return &exitCodeError{exitSchema, fmt.Errorf("failed to read schema version: %v", err)}
+	}
+	if current != expected {
+		return &exitCodeError{exitSchema, fmt.Errorf("schema is at version %d, expected %d", current, expected)}
+	}
+	fmt.Printf("schema: ok (version %d)\n", current)
+
+	if *checkURL == "" {
+		feeds, err := s.db.GetFeeds(dbCtx)
+		if err != nil {
+			return &exitCodeError{exitDatabase, fmt.Errorf("failed to get feeds: %v", err)}
+		}
+		for _, feed := range feeds {
+			if !isNewsletterFeed(feed) {
+				*checkURL = feed.Url
+				break
+			}
+		}
+		if *checkURL == "" {
+			fmt.Println("http: skipped (no feeds to check against)")
+			return nil
+		}
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, s.fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(fetchCtx, http.MethodGet, *checkURL, nil)
+	if err != nil {
+		return &exitCodeError{exitNetwork, fmt.Errorf("creating request: %w", err)}
+	}
+	req.Header.Set("User-Agent", s.userAgent(nil))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return &exitCodeError{exitNetwork, fmt.Errorf("outbound HTTP failed: %v", err)}
+	}
+	resp.Body.Close()
+
+	// any response at all shows the network path works
+	fmt.Printf("http: ok (%s from %s)\n", resp.Status, *checkURL)
+
+	return nil
+}
+
+// schedulerStaleTicks is how many intervals may pass without a finished
+// pass before /healthz reports the scheduler as wedged. A pass over many
+// slow feeds can overrun a tick, so it is never less than
+// minSchedulerStale.
+const (
+	schedulerStaleTicks = 3
+	minSchedulerStale   = 10 * time.Minute
+)
+
+// schedulerHealth tracks when the scheduler last finished a pass, for
+// serve's probes. Its methods are safe on a nil schedulerHealth, which is
+// a process without a scheduler.
+type schedulerHealth struct {
+	interval time.Duration
+
+	mu       sync.Mutex
+	started  time.Time
+	lastPass time.Time
+}
+
+func newSchedulerHealth(interval time.Duration) *schedulerHealth {
+	return &schedulerHealth{interval: interval, started: time.Now()}
+}
+
+// setInterval changes how often passes are expected, after a reload.
+func (h *schedulerHealth) setInterval(interval time.Duration) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.interval = interval
+}
+
+// passed records a pass finishing at now, or being skipped for quiet
+// hours, which shows the scheduler alive just as well.
+func (h *schedulerHealth) passed(now time.Time) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastPass = now
+}
+
+// check reports how the scheduler is doing at now: alive is false once it
+// has gone too long without finishing a pass, and ready once it has
+// finished its first.
+func (h *schedulerHealth) check(now time.Time) (status string, alive, ready bool) {
+	if h == nil {
+		return "not running", true, true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stale := max(schedulerStaleTicks*h.interval, minSchedulerStale)
+	if h.lastPass.IsZero() {
+		waiting := now.Sub(h.started).Round(time.Second)
+		return fmt.Sprintf("first pass running for %s", waiting), waiting < stale, false
+	}
+	since := now.Sub(h.lastPass).Round(time.Second)
+	if since >= stale {
+		return fmt.Sprintf("no pass finished for %s", since), false, true
+	}
+	return fmt.Sprintf("ok (last pass %s ago)", since), true, true
+}
+
+// registerProbeHandlers adds /healthz and /readyz for orchestrators like
+// Kubernetes and Compose. Both fail with 503 while the database can't be
+// reached or the scheduler, when this process runs one, has wedged;
+// /readyz also waits for the scheduler's first pass. Each prints a line
+// per check, like the healthcheck command.
+func registerProbeHandlers(mux *http.ServeMux, s *state) {
+	probe := func(needReady bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), s.dbTimeout)
+			defer cancel()
+
+			healthy := true
+			var b strings.Builder
+			if err := s.db.Ping(ctx); err != nil {
+				healthy = false
+				fmt.Fprintf(&b, "database: %v\n", err)
+			} else {
+				b.WriteString("database: ok\n")
+			}
+
+			status, alive, ready := s.scheduler.check(time.Now())
+			if !alive || (needReady && !ready) {
+				healthy = false
+			}
+			fmt.Fprintf(&b, "scheduler: %s\n", status)
+
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-store")
+			if !healthy {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			fmt.Fprint(w, b.String())
+		}
+	}
+
+	mux.HandleFunc("GET /healthz", probe(false))
+	mux.HandleFunc("GET /readyz", probe(true))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// handlerHistory lists the latest attempts at fetching a feed, newest
+// first, so a feed that only fails now and then can be caught at it.
+func handlerHistory(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	limit := fs.Int("n", 20, "how many attempts to show")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		return usageErrorf("history command requires a feed name or URL")
+	}
+	if *limit <= 0 {
+		return usageErrorf("-n must be a positive number")
+	}
+
+	feed, err := findFeed(ctx, s, fs.Arg(0))
+	if err == sql.ErrNoRows {
+		return notFoundErrorf("feed %s does not exist", fs.Arg(0))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get feed: %v", err)
+	}
+
+	// findFeed may have waited on the user, so the timeout starts here
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	attempts, err := s.db.GetFetchLogForFeed(ctx, database.GetFetchLogForFeedParams{
+		FeedID: feed.ID,
+		Limit:  int32(*limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get fetch history: %v", err)
+	}
+	if len(attempts) == 0 {
+		fmt.Printf("%s has not been fetched since fetch history was kept\n", feed.Name)
+		return nil
+	}
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tSTATUS\tDURATION\tITEMS\tNEW\tERROR")
+	for _, attempt := range attempts {
+		status := "-"
+		if attempt.StatusCode.Valid {
+			status = strconv.Itoa(int(attempt.StatusCode.Int32))
+		}
+		if attempt.Error.Valid {
+			failed++
+		}
+		duration := (time.Duration(attempt.DurationMs) * time.Millisecond).String()
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n", attempt.FetchedAt.Local().Format(time.DateTime), status,
+			duration, attempt.ItemsSeen, attempt.NewPosts, attempt.Error.String)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s: %d of the last %d fetches failed\n", feed.Name, failed, len(attempts))
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// hookTimeout bounds one run of a hook script.
+const hookTimeout = 30 * time.Second
+
+type hookFeed struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	URL  string    `json:"url"`
+}
+
+type hookPost struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	Relevance   float64    `json:"relevance"`
+}
+
+// newPostEvent is what on_new_post gets.
+type newPostEvent struct {
+	Event string   `json:"event"`
+	Feed  hookFeed `json:"feed"`
+	Post  hookPost `json:"post"`
+}
+
+// feedErrorEvent is what on_feed_error gets.
+type feedErrorEvent struct {
+	Event string   `json:"event"`
+	Feed  hookFeed `json:"feed"`
+	Error string   `json:"error"`
+}
+
+// digestEvent is what on_digest gets; Digest is the Markdown itself, and
+// File where it was written, if anywhere.
+type digestEvent struct {
+	Event  string    `json:"event"`
+	Since  time.Time `json:"since"`
+	Posts  int       `json:"posts"`
+	Digest string    `json:"digest"`
+	File   string    `json:"file,omitempty"`
+}
+
+func newHookFeed(feed database.Feed) hookFeed {
+	return hookFeed{ID: feed.ID, Name: feed.Name, URL: feed.Url}
+}
+
+// hookNewPosts runs on_new_post once for each of posts, just stored
+// from feed.
+func hookNewPosts(ctx context.Context, s *state, feed database.Feed, posts []database.CreatePostParams) {
+	if s.Config.OnNewPost == "" {
+		return
+	}
+	for _, post := range posts {
+		event := newPostEvent{
+			Event: "new_post",
+			Feed:  newHookFeed(feed),
+			Post: hookPost{
+				ID:          post.ID,
+				Title:       post.Title,
+				URL:         post.Url,
+				Description: post.Description.String,
+				Author:      post.Author.String,
+				Relevance:   post.Relevance,
+			},
+		}
+		if post.PublishedAt.Valid {
+			event.Post.PublishedAt = &post.PublishedAt.Time
+		}
+		runHook(ctx, "on_new_post", s.Config.OnNewPost, event)
+	}
+}
+
+// hookFeedError runs on_feed_error for feed, which failed with err.
+func hookFeedError(ctx context.Context, s *state, feed database.Feed, err error) {
+	if s.Config.OnFeedError == "" {
+		return
+	}
+	runHook(ctx, "on_feed_error", s.Config.OnFeedError, feedErrorEvent{
+		Event: "feed_error",
+		Feed:  newHookFeed(feed),
+		Error: err.Error(),
+	})
+}
+
+// runHook runs script with payload as JSON on stdin and the hook's name
+// in GATOR_HOOK. A failing hook is reported and otherwise ignored. Hooks
+// get hookTimeout of their own rather than what is left of ctx, which
+// is often a single database operation's.
+func runHook(ctx context.Context, name, script string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Printf("Error running %s hook: %v\n", name, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, script)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(), "GATOR_HOOK="+name)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			err = fmt.Errorf("%v: %s", err, msg)
+		}
+		fmt.Printf("Error running %s hook: %v\n", name, err)
+		return
+	}
+	if len(out) > 0 {
+		debugf("%s hook: %s", name, out)
+	}
+}
@@ -0,0 +1,577 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// htmlNode is an element or a run of text in a parsed HTML page. The
+// parser behind it is deliberately forgiving rather than spec-complete:
+// it recovers from the unclosed and misnested tags real pages are full
+// of, which is all scraping needs.
+type htmlNode struct {
+	tag      string // lower-case element name, "" for text
+	attrs    map[string]string
+	text     string
+	parent   *htmlNode
+	children []*htmlNode
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"source": true, "track": true, "wbr": true,
+}
+
+// rawTextElements hold text that isn't markup, however much it looks it.
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// impliedEnds lists, for an opening tag, the open elements it closes, e.g.
+// a <li> ends the previous <li>.
+var impliedEnds = map[string][]string{
+	"li":       {"li"},
+	"dt":       {"dt", "dd"},
+	"dd":       {"dt", "dd"},
+	"tr":       {"tr", "td", "th"},
+	"td":       {"td", "th"},
+	"th":       {"td", "th"},
+	"option":   {"option"},
+	"thead":    {"tbody", "tr", "td", "th"},
+	"tbody":    {"thead", "tr", "td", "th"},
+	"tfoot":    {"tbody", "tr", "td", "th"},
+	"optgroup": {"optgroup", "option"},
+}
+
+// paragraphEnders are the block elements that end an open <p>.
+var paragraphEnders = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"details": true, "div": true, "dl": true, "dt": true, "dd": true,
+	"fieldset": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "section": true, "table": true,
+	"ul": true,
+}
+
+// parseHTML builds a tree out of src under a root node with no tag.
+func parseHTML(src string) *htmlNode {
+	root := &htmlNode{}
+	open := []*htmlNode{root}
+	current := func() *htmlNode { return open[len(open)-1] }
+
+	appendText := func(text string) {
+		if text == "" {
+			return
+		}
+		parent := current()
+		parent.children = append(parent.children, &htmlNode{text: html.UnescapeString(text), parent: parent})
+	}
+
+	for len(src) > 0 {
+		lt := strings.IndexByte(src, '<')
+		if lt < 0 {
+			appendText(src)
+			break
+		}
+		appendText(src[:lt])
+		src = src[lt:]
+
+		switch {
+		case strings.HasPrefix(src, "<!--"):
+			end := strings.Index(src[4:], "-->")
+			if end < 0 {
+				return root
+			}
+			src = src[4+end+3:]
+
+		case strings.HasPrefix(src, "<!") || strings.HasPrefix(src, "<?"):
+			end := strings.IndexByte(src, '>')
+			if end < 0 {
+				return root
+			}
+			src = src[end+1:]
+
+		case strings.HasPrefix(src, "</"):
+			end := strings.IndexByte(src, '>')
+			if end < 0 {
+				return root
+			}
+			name := strings.ToLower(strings.TrimSpace(src[2:end]))
+			src = src[end+1:]
+			// close the nearest open element of that name; a stray end
+			// tag closes nothing
+			for i := len(open) - 1; i > 0; i-- {
+				if open[i].tag == name {
+					open = open[:i]
+					break
+				}
+			}
+
+		case len(src) > 1 && isASCIILetter(src[1]):
+			node, selfClosing, rest := parseStartTag(src)
+			src = rest
+
+			for len(open) > 1 {
+				top := current().tag
+				ended := top == "p" && paragraphEnders[node.tag]
+				for _, tag := range impliedEnds[node.tag] {
+					ended = ended || top == tag
+				}
+				if !ended {
+					break
+				}
+				open = open[:len(open)-1]
+			}
+
+			parent := current()
+			node.parent = parent
+			parent.children = append(parent.children, node)
+
+			if selfClosing || voidElements[node.tag] {
+				continue
+			}
+			if rawTextElements[node.tag] {
+				end := indexFold(src, "</"+node.tag)
+				if end < 0 {
+					end = len(src)
+				}
+				text := src[:end]
+				if node.tag == "title" || node.tag == "textarea" {
+					text = html.UnescapeString(text)
+				}
+				if text != "" {
+					node.children = append(node.children, &htmlNode{text: text, parent: node})
+				}
+				src = src[end:]
+				if gt := strings.IndexByte(src, '>'); gt >= 0 {
+					src = src[gt+1:]
+				}
+				continue
+			}
+			open = append(open, node)
+
+		default:
+			// a lone '<' is just text
+			appendText("<")
+			src = src[1:]
+		}
+	}
+
+	return root
+}
+
+// parseStartTag reads the tag at the start of src, returning the element,
+// whether it was written self-closing, and what follows it.
+func parseStartTag(src string) (*htmlNode, bool, string) {
+	i := 1
+	for i < len(src) && !isHTMLSpace(src[i]) && src[i] != '>' && src[i] != '/' {
+		i++
+	}
+	node := &htmlNode{tag: strings.ToLower(src[1:i]), attrs: make(map[string]string)}
+
+	for i < len(src) {
+		for i < len(src) && isHTMLSpace(src[i]) {
+			i++
+		}
+		if i >= len(src) {
+			break
+		}
+		if src[i] == '>' {
+			return node, false, src[i+1:]
+		}
+		if strings.HasPrefix(src[i:], "/>") {
+			return node, true, src[i+2:]
+		}
+		if src[i] == '/' {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(src) && !isHTMLSpace(src[i]) && src[i] != '=' && src[i] != '>' && !strings.HasPrefix(src[i:], "/>") {
+			i++
+		}
+		name := strings.ToLower(src[start:i])
+
+		for i < len(src) && isHTMLSpace(src[i]) {
+			i++
+		}
+		value := ""
+		if i < len(src) && src[i] == '=' {
+			i++
+			for i < len(src) && isHTMLSpace(src[i]) {
+				i++
+			}
+			if i < len(src) && (src[i] == '"' || src[i] == '\'') {
+				quote := src[i]
+				end := strings.IndexByte(src[i+1:], quote)
+				if end < 0 {
+					end = len(src) - i - 1
+				}
+				value = src[i+1 : i+1+end]
+				i = min(i+end+2, len(src))
+			} else {
+				start := i
+				for i < len(src) && !isHTMLSpace(src[i]) && src[i] != '>' {
+					i++
+				}
+				value = src[start:i]
+			}
+		}
+
+		// the first of a repeated attribute wins, as in browsers
+		if _, seen := node.attrs[name]; !seen && name != "" {
+			node.attrs[name] = html.UnescapeString(value)
+		}
+	}
+
+	return node, false, ""
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// indexFold is strings.Index ignoring ASCII case in s.
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// textContent returns the text inside n with runs of whitespace collapsed,
+// leaving out scripts and styles.
+func (n *htmlNode) textContent() string {
+	var b strings.Builder
+	var walk func(*htmlNode)
+	walk = func(n *htmlNode) {
+		// text nodes have no tag; neither does the root, but it has no parent
+		if n.tag == "" && n.parent != nil {
+			b.WriteString(n.text)
+			return
+		}
+		if n.tag == "script" || n.tag == "style" {
+			return
+		}
+		// block elements and breaks separate words; inline ones don't
+		block := paragraphEnders[n.tag] || n.tag == "br" || n.tag == "td" || n.tag == "th"
+		if block {
+			b.WriteByte(' ')
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+		if block {
+			b.WriteByte(' ')
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// find returns the first element below n with the given tag, e.g. the
+// page's <title>.
+func (n *htmlNode) find(tag string) *htmlNode {
+	for _, child := range n.children {
+		if child.tag == tag {
+			return child
+		}
+		if found := child.find(tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// cssSelector is a compiled group of CSS selectors, covering the parts of
+// the syntax scrapers need: type, #id, .class and [attr] selectors with
+// the =, ~=, ^=, $= and *= operators, joined by descendant or child (>)
+// combinators, and comma-separated alternatives.
+type cssSelector []complexSelector
+
+type complexSelector struct {
+	compounds []compoundSelector
+	// combinators[i] joins compounds[i] and compounds[i+1]: ' ' or '>'
+	combinators []byte
+}
+
+type compoundSelector struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+type attrSelector struct {
+	name, op, value string
+}
+
+func compileSelector(expr string) (cssSelector, error) {
+	var group cssSelector
+	for _, part := range splitSelectorGroup(expr) {
+		complex, err := compileComplexSelector(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %v", expr, err)
+		}
+		group = append(group, complex)
+	}
+	if len(group) == 0 {
+		return nil, fmt.Errorf("empty selector")
+	}
+	return group, nil
+}
+
+// splitSelectorGroup splits on the commas that aren't inside brackets.
+func splitSelectorGroup(expr string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, expr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, expr[start:])
+}
+
+func compileComplexSelector(expr string) (complexSelector, error) {
+	var complex complexSelector
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return complex, fmt.Errorf("empty selector")
+	}
+
+	combinator := byte(0)
+	for len(expr) > 0 {
+		compound, rest, err := compileCompoundSelector(expr)
+		if err != nil {
+			return complex, err
+		}
+		if combinator != 0 {
+			complex.combinators = append(complex.combinators, combinator)
+		}
+		complex.compounds = append(complex.compounds, compound)
+
+		trimmed := strings.TrimLeft(rest, " \t\n")
+		switch {
+		case trimmed == "":
+			return complex, nil
+		case trimmed[0] == '>':
+			combinator = '>'
+			expr = strings.TrimLeft(trimmed[1:], " \t\n")
+		case len(trimmed) < len(rest):
+			combinator = ' '
+			expr = trimmed
+		default:
+			return complex, fmt.Errorf("unexpected %q", trimmed[:1])
+		}
+		if expr == "" {
+			return complex, fmt.Errorf("missing selector after combinator")
+		}
+	}
+
+	return complex, nil
+}
+
+func compileCompoundSelector(expr string) (compoundSelector, string, error) {
+	var compound compoundSelector
+	empty := true
+
+	if expr[0] == '*' {
+		expr, empty = expr[1:], false
+	} else if name, rest := cutIdent(expr); name != "" {
+		compound.tag, expr, empty = strings.ToLower(name), rest, false
+	}
+
+	for len(expr) > 0 {
+		switch expr[0] {
+		case '#':
+			name, rest := cutIdent(expr[1:])
+			if name == "" {
+				return compound, "", fmt.Errorf("missing id after #")
+			}
+			compound.id, expr = name, rest
+		case '.':
+			name, rest := cutIdent(expr[1:])
+			if name == "" {
+				return compound, "", fmt.Errorf("missing class after .")
+			}
+			compound.classes, expr = append(compound.classes, name), rest
+		case '[':
+			end := strings.IndexByte(expr, ']')
+			if end < 0 {
+				return compound, "", fmt.Errorf("unclosed [")
+			}
+			attr, err := compileAttrSelector(expr[1:end])
+			if err != nil {
+				return compound, "", err
+			}
+			compound.attrs, expr = append(compound.attrs, attr), expr[end+1:]
+		case ':':
+			return compound, "", fmt.Errorf("pseudo-classes are not supported")
+		default:
+			if empty {
+				return compound, "", fmt.Errorf("unexpected %q", expr[:1])
+			}
+			return compound, expr, nil
+		}
+		empty = false
+	}
+
+	return compound, "", nil
+}
+
+func compileAttrSelector(expr string) (attrSelector, error) {
+	for _, op := range []string{"~=", "^=", "$=", "*=", "="} {
+		name, value, found := strings.Cut(expr, op)
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			return attrSelector{}, fmt.Errorf("missing attribute name in [%s]", expr)
+		}
+		return attrSelector{name: name, op: op, value: value}, nil
+	}
+
+	name := strings.ToLower(strings.TrimSpace(expr))
+	if name == "" {
+		return attrSelector{}, fmt.Errorf("empty []")
+	}
+	return attrSelector{name: name}, nil
+}
+
+// cutIdent splits a CSS identifier off the front of s.
+func cutIdent(s string) (string, string) {
+	i := 0
+	for i < len(s) && (isASCIILetter(s[i]) || (s[i] >= '0' && s[i] <= '9') || s[i] == '-' || s[i] == '_' || s[i] >= 0x80) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func (c compoundSelector) matches(n *htmlNode) bool {
+	if n.tag == "" {
+		return false
+	}
+	if c.tag != "" && c.tag != n.tag {
+		return false
+	}
+	if c.id != "" && n.attrs["id"] != c.id {
+		return false
+	}
+	for _, class := range c.classes {
+		found := false
+		for _, have := range strings.Fields(n.attrs["class"]) {
+			found = found || have == class
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, attr := range c.attrs {
+		value, ok := n.attrs[attr.name]
+		if !ok {
+			return false
+		}
+		switch attr.op {
+		case "=":
+			ok = value == attr.value
+		case "~=":
+			ok = false
+			for _, word := range strings.Fields(value) {
+				ok = ok || word == attr.value
+			}
+		case "^=":
+			ok = attr.value != "" && strings.HasPrefix(value, attr.value)
+		case "$=":
+			ok = attr.value != "" && strings.HasSuffix(value, attr.value)
+		case "*=":
+			ok = attr.value != "" && strings.Contains(value, attr.value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAt reports whether n matches compounds[:i+1], working from the
+// right as browsers do.
+func (c complexSelector) matchesAt(n *htmlNode, i int) bool {
+	if !c.compounds[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+
+	if c.combinators[i-1] == '>' {
+		return n.parent != nil && c.matchesAt(n.parent, i-1)
+	}
+	for ancestor := n.parent; ancestor != nil; ancestor = ancestor.parent {
+		if c.matchesAt(ancestor, i-1) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sel cssSelector) matches(n *htmlNode) bool {
+	for _, complex := range sel {
+		if complex.matchesAt(n, len(complex.compounds)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryAll returns the elements below n that match sel, in document order.
+func (n *htmlNode) queryAll(sel cssSelector) []*htmlNode {
+	var found []*htmlNode
+	var walk func(*htmlNode)
+	walk = func(n *htmlNode) {
+		for _, child := range n.children {
+			if sel.matches(child) {
+				found = append(found, child)
+			}
+			walk(child)
+		}
+	}
+	walk(n)
+	return found
+}
+
+// query returns the first element below n that matches sel, or nil.
+func (n *htmlNode) query(sel cssSelector) *htmlNode {
+	for _, child := range n.children {
+		if sel.matches(child) {
+			return child
+		}
+		if found := child.query(sel); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxIMAPLiteral caps one literal the server sends, which for a fetch is a
+// whole message.
+const maxIMAPLiteral = 25 << 20
+
+var (
+	imapLiteral        = regexp.MustCompile(`\{(\d+)\}$`)
+	imapFetchUID       = regexp.MustCompile(`\bUID (\d+)`)
+	imapPermanentFlags = regexp.MustCompile(`\[PERMANENTFLAGS \(([^)]*)\)\]`)
+)
+
+// imapClient speaks just enough IMAP4rev1 (RFC 3501) to read newsletters:
+// log in, open a folder, search it, fetch whole messages and flag them.
+// Only implicit TLS is supported, which every mail provider offers.
+type imapClient struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	tag     int
+	timeout time.Duration
+}
+
+// imapResponse is one untagged response. A response can span several
+// lines when it carries literals; text holds the lines joined, and the
+// literals are pulled out in order.
+type imapResponse struct {
+	text     string
+	literals [][]byte
+}
+
+// dialIMAP connects to addr over TLS and reads the greeting. timeout
+// bounds each command from then on.
+func dialIMAP(ctx context.Context, addr string, timeout time.Duration) (*imapClient, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IMAP server %s: %v", addr, err)
+	}
+
+	dialer := tls.Dialer{Config: &tls.Config{ServerName: host}}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &imapClient{conn: conn, r: bufio.NewReader(conn), timeout: timeout}
+	conn.SetDeadline(time.Now().Add(timeout))
+	greeting, err := c.readResponse()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("reading greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting.text, "* OK") && !strings.HasPrefix(greeting.text, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("server refused connection: %s", greeting.text)
+	}
+
+	return c, nil
+}
+
+func (c *imapClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *imapClient) readResponse() (imapResponse, error) {
+	var resp imapResponse
+	var text strings.Builder
+
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return resp, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		text.WriteString(line)
+
+		match := imapLiteral.FindStringSubmatch(line)
+		if match == nil {
+			break
+		}
+		size, err := strconv.Atoi(match[1])
+		if err != nil || size > maxIMAPLiteral {
+			return resp, fmt.Errorf("literal of %s bytes is too large", match[1])
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.r, literal); err != nil {
+			return resp, err
+		}
+		resp.literals = append(resp.literals, literal)
+	}
+
+	resp.text = text.String()
+	return resp, nil
+}
+
+// command sends one tagged command and collects the untagged responses
+// until the server completes it. Anything but OK is an error.
+func (c *imapClient) command(format string, args ...any) ([]imapResponse, error) {
+	c.tag++
+	tag := "g" + strconv.Itoa(c.tag)
+	line := fmt.Sprintf(format, args...)
+	verb, _, _ := strings.Cut(line, " ")
+
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+	if _, err := io.WriteString(c.conn, tag+" "+line+"\r\n"); err != nil {
+		return nil, fmt.Errorf("IMAP %s: %w", verb, err)
+	}
+
+	var untagged []imapResponse
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, fmt.Errorf("IMAP %s: %w", verb, err)
+		}
+		if status, ok := strings.CutPrefix(resp.text, tag+" "); ok {
+			if !strings.HasPrefix(status, "OK") {
+				return untagged, fmt.Errorf("IMAP %s failed: %s", verb, status)
+			}
+			return untagged, nil
+		}
+		if strings.HasPrefix(resp.text, "+") {
+			return nil, fmt.Errorf("IMAP %s: unexpected continuation request", verb)
+		}
+		untagged = append(untagged, resp)
+	}
+}
+
+// imapQuote encodes s as an IMAP quoted string.
+func imapQuote(s string) (string, error) {
+	if strings.ContainsAny(s, "\r\n") {
+		return "", fmt.Errorf("line breaks can't be sent to an IMAP server")
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`, nil
+}
+
+func (c *imapClient) login(username, password string) error {
+	user, err := imapQuote(username)
+	if err != nil {
+		return err
+	}
+	pass, err := imapQuote(password)
+	if err != nil {
+		return err
+	}
+	_, err = c.command("LOGIN %s %s", user, pass)
+	return err
+}
+
+// selectFolder opens folder for reading and writing, and reports whether
+// the server keeps keywords of the client's choosing on its messages.
+func (c *imapClient) selectFolder(folder string) (bool, error) {
+	name, err := imapQuote(folder)
+	if err != nil {
+		return false, err
+	}
+	responses, err := c.command("SELECT %s", name)
+	if err != nil {
+		return false, err
+	}
+
+	for _, resp := range responses {
+		if match := imapPermanentFlags.FindStringSubmatch(resp.text); match != nil {
+			return strings.Contains(match[1], `\*`), nil
+		}
+	}
+	return false, nil
+}
+
+// search returns the UIDs of the messages matching criteria, which is an
+// IMAP search key such as `UNSEEN FROM "a@example.com"`.
+func (c *imapClient) search(criteria string) ([]uint32, error) {
+	responses, err := c.command("UID SEARCH %s", criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	var uids []uint32
+	for _, resp := range responses {
+		fields, ok := strings.CutPrefix(resp.text, "* SEARCH")
+		if !ok {
+			continue
+		}
+		for _, field := range strings.Fields(fields) {
+			uid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected search result %q", field)
+			}
+			uids = append(uids, uint32(uid))
+		}
+	}
+	return uids, nil
+}
+
+// fetch returns the raw message with uid, without marking it seen.
+func (c *imapClient) fetch(uid uint32) ([]byte, error) {
+	responses, err := c.command("UID FETCH %d BODY.PEEK[]", uid)
+	if err != nil {
+		return nil, err
+	}
+
+	want := strconv.FormatUint(uint64(uid), 10)
+	for _, resp := range responses {
+		if !strings.Contains(resp.text, " FETCH ") || len(resp.literals) == 0 {
+			continue
+		}
+		// servers may send unsolicited FETCH responses for other messages
+		if match := imapFetchUID.FindStringSubmatch(resp.text); match != nil && match[1] != want {
+			continue
+		}
+		return resp.literals[0], nil
+	}
+	return nil, fmt.Errorf("server returned no body for message %d", uid)
+}
+
+// addFlag sets flag, a system flag or keyword, on the message with uid.
+func (c *imapClient) addFlag(uid uint32, flag string) error {
+	_, err := c.command("UID STORE %d +FLAGS.SILENT (%s)", uid, flag)
+	return err
+}
+
+func (c *imapClient) logout() error {
+	_, err := c.command("LOGOUT")
+	return err
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// importedFeed is one subscription read from another reader's export,
+// with the folders it was in as tags.
+type importedFeed struct {
+	URL   string
+	Title string
+	Tags  []string
+}
+
+// readerCategory is how both Feedly and Inoreader describe a folder.
+type readerCategory struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+// readerSubscription covers Feedly's subscription list entries, whose id
+// is "feed/<url>", and Inoreader's, which also carry the url separately.
+type readerSubscription struct {
+	ID         string           `json:"id"`
+	Title      string           `json:"title"`
+	URL        string           `json:"url"`
+	Categories []readerCategory `json:"categories"`
+}
+
+type inoreaderExport struct {
+	Subscriptions []readerSubscription `json:"subscriptions"`
+}
+
+func handlerImport(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) > 0 && cmd.Args[0] == "all" {
+		return importAll(ctx, s, cmd.Args[1:])
+	}
+
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "auto", "export format: auto, feedly or inoreader")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("import command requires an export file (.json or .zip)")
+	}
+	if *format != "auto" && *format != "feedly" && *format != "inoreader" {
+		return fmt.Errorf("unsupported import format: %s", *format)
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", fs.Arg(0), err)
+	}
+
+	feeds, err := parseSubscriptionExport(data, *format)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %v", fs.Arg(0), err)
+	}
+	if len(feeds) == 0 {
+		return fmt.Errorf("no subscriptions found in %s", fs.Arg(0))
+	}
+
+	userCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(userCtx, s)
+	if err != nil {
+		return err
+	}
+
+	created, followed := 0, 0
+	for _, imported := range feeds {
+		isNew, isFollowed, err := importFeed(ctx, s, user, imported)
+		if err != nil {
+			fmt.Printf("Error importing %s: %v\n", imported.URL, err)
+			continue
+		}
+		if isNew {
+			created++
+		}
+		if isFollowed {
+			followed++
+		}
+	}
+
+	infof("Imported %d subscriptions: %d new feeds, %d newly followed\n", len(feeds), created, followed)
+
+	return nil
+}
+
+// importFeed stores one subscription for user: the feed if it isn't known
+// yet, the follow, and its folders as tags. It reports whether the feed
+// was created and whether the follow was new.
+func importFeed(ctx context.Context, s *state, user database.User, imported importedFeed) (bool, bool, error) {
+	feedURL, err := normalizeFeedURL(imported.URL)
+	if err != nil {
+		return false, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	created, followed := false, false
+	err = s.withTx(ctx, func(tx *state) error {
+		feed, err := findFeedByURL(ctx, tx, feedURL)
+		if err == sql.ErrNoRows {
+			name, err := unusedFeedName(ctx, tx, imported.Title, feedURL)
+			if err != nil {
+				return err
+			}
+			feed, err = tx.db.CreateFeed(ctx, database.CreateFeedParams{
+				ID:     uuid.New(),
+				UserID: user.ID,
+				Name:   name,
+				Url:    feedURL,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create feed: %v", err)
+			}
+			created = true
+		} else if err != nil {
+			return fmt.Errorf("failed to get feed: %v", err)
+		}
+
+		rows, err := tx.db.CreateFeedFollowIfMissing(ctx, database.CreateFeedFollowIfMissingParams{
+			ID:     uuid.New(),
+			UserID: user.ID,
+			FeedID: feed.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to follow feed: %v", err)
+		}
+		followed = rows > 0
+
+		for _, tag := range imported.Tags {
+			if err := tx.db.AddFeedTag(ctx, database.AddFeedTagParams{
+				UserID: user.ID,
+				FeedID: feed.ID,
+				Tag:    tag,
+			}); err != nil {
+				return fmt.Errorf("failed to tag feed: %v", err)
+			}
+		}
+
+		return nil
+	})
+
+	return created, followed, err
+}
+
+// unusedFeedName picks a name for a feed that no stored feed has: its
+// title, then the title with the host, then numbered variants.
+func unusedFeedName(ctx context.Context, s *state, title, feedURL string) (string, error) {
+	host := feedURL
+	if u, err := url.Parse(feedURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	base := strings.TrimSpace(title)
+	if base == "" {
+		base = host
+	}
+
+	for i := 1; ; i++ {
+		name := base
+		switch {
+		case i == 2:
+			name = fmt.Sprintf("%s (%s)", base, host)
+		case i > 2:
+			name = fmt.Sprintf("%s (%s) %d", base, host, i-1)
+		}
+
+		feeds, err := s.db.GetFeedsByName(ctx, name)
+		if err != nil && err != sql.ErrNoRows {
+			return "", fmt.Errorf("failed to get feed: %v", err)
+		}
+		if len(feeds) == 0 {
+			return name, nil
+		}
+	}
+}
+
+// parseSubscriptionExport reads a Feedly or Inoreader subscription export,
+// either as the JSON file itself or as a ZIP archive containing it.
+func parseSubscriptionExport(data []byte, format string) ([]importedFeed, error) {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return parseSubscriptionJSON(data, format)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	// data exports hold other JSON files too (starred items and the like),
+	// so take whatever parses as a subscription list
+	var feeds []importedFeed
+	for _, file := range archive.File {
+		if !strings.EqualFold(path.Ext(file.Name), ".json") {
+			continue
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %v", file.Name, err)
+		}
+		var buf bytes.Buffer
+		_, err = buf.ReadFrom(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", file.Name, err)
+		}
+
+		found, err := parseSubscriptionJSON(buf.Bytes(), format)
+		if err != nil {
+			continue
+		}
+		feeds = append(feeds, found...)
+	}
+
+	return feeds, nil
+}
+
+// parseSubscriptionJSON tells the formats apart by shape: Feedly exports
+// a bare array of subscriptions, Inoreader an object holding one.
+func parseSubscriptionJSON(data []byte, format string) ([]importedFeed, error) {
+	trimmed := bytes.TrimSpace(data)
+	if format == "auto" {
+		format = "inoreader"
+		if bytes.HasPrefix(trimmed, []byte("[")) {
+			format = "feedly"
+		}
+	}
+
+	var subscriptions []readerSubscription
+	if format == "feedly" {
+		if err := json.Unmarshal(trimmed, &subscriptions); err != nil {
+			return nil, err
+		}
+	} else {
+		var export inoreaderExport
+		if err := json.Unmarshal(trimmed, &export); err != nil {
+			return nil, err
+		}
+		subscriptions = export.Subscriptions
+	}
+
+	feeds := make([]importedFeed, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		feedURL := sub.URL
+		if feedURL == "" {
+			feedURL = strings.TrimPrefix(sub.ID, "feed/")
+		}
+		if feedURL == "" {
+			continue
+		}
+
+		feed := importedFeed{URL: feedURL, Title: sub.Title}
+		for _, category := range sub.Categories {
+			if tag := categoryTag(category); tag != "" {
+				feed.Tags = append(feed.Tags, tag)
+			}
+		}
+		feeds = append(feeds, feed)
+	}
+
+	return feeds, nil
+}
+
+// categoryTag turns a folder into a tag name, falling back to the last
+// part of ids like "user/123/category/Tech" or "user/-/label/Tech".
+// Feedly's built-in "global.*" categories aren't folders and are dropped.
+func categoryTag(category readerCategory) string {
+	name := path.Base(category.ID)
+	if strings.HasPrefix(name, "global.") {
+		return ""
+	}
+
+	if label := strings.TrimSpace(category.Label); label != "" {
+		return label
+	}
+	if name == "." || name == "/" {
+		return ""
+	}
+	return name
+}
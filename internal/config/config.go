@@ -2,12 +2,53 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 const configFileName = ".gatorconfig.json"
 
+const (
+	defaultHostDelay     = time.Second
+	defaultDaemonEvery   = 5 * time.Minute
+	defaultDBTimeout     = 10 * time.Second
+	defaultFetchTimeout  = 30 * time.Second
+	defaultMaxFeedItems  = 1000
+	defaultMaxFeedSizeMB = 5
+	defaultItemsPerFetch = 100
+	defaultFetchWorkers  = 4
+
+	defaultFetchLogRetention = 30 * 24 * time.Hour
+
+	defaultDBMaxOpenConns    = 10
+	defaultDBMaxIdleConns    = 5
+	defaultDBConnMaxLifetime = 30 * time.Minute
+
+	defaultLogMaxSizeMB = 10
+	defaultLogMaxAge    = 7 * 24 * time.Hour
+	defaultLogMaxFiles  = 5
+
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 10
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultTLSSessionCacheSize = 256
+
+	defaultNotifyMinScore = 1
+	defaultNtfyServer     = "https://ntfy.sh"
+
+	defaultAPIRateLimit = 5
+	defaultAPIRateBurst = 20
+
+	defaultPprofAddr = "localhost:6060"
+
+	defaultIMAPFolder = "INBOX"
+	defaultIMAPPort   = "993"
+)
+
 func getConfigFilePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -40,8 +81,554 @@ func Write(cfg Config) error {
 }
 
 type Config struct {
-	DbUrl           string `json:"db_url"`
-	CurrentUserName string `json:"current_user_name"`
+	DbUrl string `json:"db_url"`
+	// DbPasswordSource "keyring" reads the password left out of db_url
+	// from the OS keyring; PgpassFile points libpq-style lookups at a
+	// .pgpass file other than ~/.pgpass
+	DbPasswordSource string `json:"db_password_source,omitempty"`
+	PgpassFile       string `json:"pgpass_file,omitempty"`
+	CurrentUserName  string `json:"current_user_name"`
+	HostDelay        string `json:"host_delay,omitempty"`
+	UserAgent        string `json:"user_agent,omitempty"`
+	DataDir          string `json:"data_dir,omitempty"`
+	FixtureMode      string `json:"fixture_mode,omitempty"`
+	FixtureDir       string `json:"fixture_dir,omitempty"`
+	DBTimeout        string `json:"db_timeout,omitempty"`
+	FetchTimeout     string `json:"fetch_timeout,omitempty"`
+	MaxFeedItems     int    `json:"max_feed_items,omitempty"`
+	MaxFeedSizeMB    int    `json:"max_feed_size_mb,omitempty"`
+	// ItemsPerFetch is how many of the newest items agg stores from one
+	// fetch, see ItemsPerFetchOrDefault
+	ItemsPerFetch int `json:"items_per_fetch,omitempty"`
+	// FetchWorkers is how many feeds an agg pass fetches at once, see
+	// FetchWorkersOrDefault
+	FetchWorkers int `json:"fetch_workers,omitempty"`
+	// DaemonEvery is how often the daemon checks which feeds are due
+	// when it isn't given --every; daemon reload picks up changes
+	DaemonEvery string `json:"daemon_every,omitempty"`
+	// DBSchema keeps gator's tables in this Postgres schema rather than
+	// public, for sharing a database with other apps. The schema has to
+	// exist, and migrations have to run with search_path set to it
+	DBSchema string `json:"db_schema,omitempty"`
+	// AutoMigrate applies pending migrations before any command that
+	// uses the database, like --auto-migrate
+	AutoMigrate bool `json:"auto_migrate,omitempty"`
+	// BrowseTemplate is the Go template browse prints each post with when
+	// --template isn't given
+	BrowseTemplate string `json:"browse_template,omitempty"`
+	// FetchLogRetention is how long each feed's fetch history is kept
+	FetchLogRetention string `json:"fetch_log_retention,omitempty"`
+
+	// database connection pool, see the accessors below
+	DBMaxOpenConns    int    `json:"db_max_open_conns,omitempty"`
+	DBMaxIdleConns    int    `json:"db_max_idle_conns,omitempty"`
+	DBConnMaxLifetime string `json:"db_conn_max_lifetime,omitempty"`
+
+	// rotation of the daemon's log file: it is started afresh once it
+	// reaches LogMaxSizeMB or its first line is LogMaxAge old, keeping
+	// LogMaxFiles old ones
+	LogMaxSizeMB int    `json:"log_max_size_mb,omitempty"`
+	LogMaxAge    string `json:"log_max_age,omitempty"`
+	LogMaxFiles  int    `json:"log_max_files,omitempty"`
+
+	// HTTP transport tuning, see the accessors below
+	MaxIdleConns        int    `json:"max_idle_conns,omitempty"`
+	MaxIdleConnsPerHost int    `json:"max_idle_conns_per_host,omitempty"`
+	MaxConnsPerHost     int    `json:"max_conns_per_host,omitempty"`
+	IdleConnTimeout     string `json:"idle_conn_timeout,omitempty"`
+	TLSSessionCacheSize int    `json:"tls_session_cache_size,omitempty"`
+	DisableHTTP2        bool   `json:"disable_http2,omitempty"`
+
+	// APIRateLimit is how many requests per second one client may make to
+	// serve, with bursts of up to APIRateBurst; a negative rate turns
+	// limiting off
+	APIRateLimit float64 `json:"api_rate_limit,omitempty"`
+	APIRateBurst int     `json:"api_rate_burst,omitempty"`
+
+	// CORS for serve: origins whose pages may call the API, "*" for any,
+	// request headers they may send besides Authorization and
+	// Content-Type, and whether they may send cookies and basic auth,
+	// which needs the origins listed rather than "*"
+	CORSOrigins     []string `json:"cors_origins,omitempty"`
+	CORSHeaders     []string `json:"cors_headers,omitempty"`
+	CORSCredentials bool     `json:"cors_credentials,omitempty"`
+
+	// newsletter mailbox, polled by agg when imap_server is set
+	IMAPServer   string   `json:"imap_server,omitempty"`
+	IMAPUsername string   `json:"imap_username,omitempty"`
+	IMAPPassword string   `json:"imap_password,omitempty"`
+	IMAPFolder   string   `json:"imap_folder,omitempty"`
+	IMAPSenders  []string `json:"imap_senders,omitempty"`
+
+	// read-it-later services `save` can send posts to
+	PocketConsumerKey    string `json:"pocket_consumer_key,omitempty"`
+	PocketAccessToken    string `json:"pocket_access_token,omitempty"`
+	InstapaperUsername   string `json:"instapaper_username,omitempty"`
+	InstapaperPassword   string `json:"instapaper_password,omitempty"`
+	WallabagURL          string `json:"wallabag_url,omitempty"`
+	WallabagClientID     string `json:"wallabag_client_id,omitempty"`
+	WallabagClientSecret string `json:"wallabag_client_secret,omitempty"`
+	WallabagUsername     string `json:"wallabag_username,omitempty"`
+	WallabagPassword     string `json:"wallabag_password,omitempty"`
+
+	// NotifyMinScore is the relevance a new post needs for the daemon to
+	// notify about it, see NotifyMinScoreOrDefault
+	NotifyMinScore float64 `json:"notify_min_score,omitempty"`
+
+	// ntfy topic new posts are published to; NtfyFeeds narrows them to
+	// every post from those feeds instead of the relevant ones
+	NtfyServer string   `json:"ntfy_server,omitempty"`
+	NtfyTopic  string   `json:"ntfy_topic,omitempty"`
+	NtfyToken  string   `json:"ntfy_token,omitempty"`
+	NtfyFeeds  []string `json:"ntfy_feeds,omitempty"`
+
+	// Telegram bot new posts are sent through: relevant ones to
+	// TelegramChatID, and every post from the feeds a route picks to its
+	// chat
+	TelegramBotToken string          `json:"telegram_bot_token,omitempty"`
+	TelegramChatID   string          `json:"telegram_chat_id,omitempty"`
+	TelegramRoutes   []TelegramRoute `json:"telegram_routes,omitempty"`
+
+	// QuietHours is a daily window like "23:00-07:00", in the current
+	// user's timezone, during which the daemon does what QuietHoursMode
+	// says: "fetch" stops fetching, "notify" holds notifications back and
+	// "both", the default, does both
+	QuietHours     string `json:"quiet_hours,omitempty"`
+	QuietHoursMode string `json:"quiet_hours_mode,omitempty"`
+
+	// OTLPEndpoint is the OTLP/HTTP collector URL traces are sent to, e.g.
+	// "http://localhost:4318". Tracing is off unless it or one of the
+	// OTEL_EXPORTER_OTLP_*ENDPOINT variables is set
+	OTLPEndpoint string `json:"otlp_endpoint,omitempty"`
+
+	// Pprof serves net/http/pprof while the daemon runs, on PprofAddr,
+	// which defaults to localhost:6060 so profiles aren't exposed beyond
+	// the machine
+	Pprof     bool   `json:"pprof,omitempty"`
+	PprofAddr string `json:"pprof_addr,omitempty"`
+
+	// OnNewPost, OnFeedError and OnDigest are scripts run with a JSON
+	// description of the event on stdin: for every post stored, every
+	// feed that fails to fetch and every digest written
+	OnNewPost   string `json:"on_new_post,omitempty"`
+	OnFeedError string `json:"on_feed_error,omitempty"`
+	OnDigest    string `json:"on_digest,omitempty"`
+
+	// FilterScript is a Starlark file whose filter(post) sees every
+	// incoming post and may drop, tag, rewrite or rescore it
+	FilterScript string `json:"filter_script,omitempty"`
+
+	// LogLevel is quiet, normal, debug or trace, for when no -q or -v is
+	// given; the daemon rereads it on SIGHUP
+	LogLevel string `json:"log_level,omitempty"`
+
+	// DirectoryURL points discover at a JSON list of feeds, in the format
+	// of the directory gator ships with, to suggest alongside it
+	DirectoryURL string `json:"directory_url,omitempty"`
+}
+
+// TelegramRoute sends every new post from the named feeds, or from feeds
+// with any of the tags, to a Telegram chat.
+type TelegramRoute struct {
+	ChatID string   `json:"chat_id"`
+	Feeds  []string `json:"feeds,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+func parseDuration(name, value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+
+	return d, nil
+}
+
+// MaxFeedItemsOrDefault returns how many items are read from one feed
+// document; anything past that is skipped while parsing. A negative
+// max_feed_items lifts the limit.
+func (cfg *Config) MaxFeedItemsOrDefault() int {
+	switch {
+	case cfg.MaxFeedItems < 0:
+		return 0
+	case cfg.MaxFeedItems == 0:
+		return defaultMaxFeedItems
+	default:
+		return cfg.MaxFeedItems
+	}
+}
+
+// ItemsPerFetchOrDefault returns how many of a feed's newest items are
+// processed per fetch, or 0 for all of them. It applies after
+// max_feed_items, which bounds what is decoded at all, and a feed that
+// ships its whole archive is only read in full by refresh --backfill. A
+// negative items_per_fetch lifts the limit.
+func (cfg *Config) ItemsPerFetchOrDefault() int {
+	switch {
+	case cfg.ItemsPerFetch < 0:
+		return 0
+	case cfg.ItemsPerFetch == 0:
+		return defaultItemsPerFetch
+	default:
+		return cfg.ItemsPerFetch
+	}
+}
+
+// FetchWorkersOrDefault returns how many feeds an agg pass fetches at
+// once. Requests to one host stay spaced out by host_delay however many
+// run; 1 fetches one feed at a time.
+func (cfg *Config) FetchWorkersOrDefault() int {
+	if cfg.FetchWorkers <= 0 {
+		return defaultFetchWorkers
+	}
+	return cfg.FetchWorkers
+}
+
+// MaxFeedSizeOrDefault returns how many bytes are read from one feed
+// response before it is given up on, or 0 for no limit. A negative
+// max_feed_size_mb lifts the limit.
+func (cfg *Config) MaxFeedSizeOrDefault() int64 {
+	switch {
+	case cfg.MaxFeedSizeMB < 0:
+		return 0
+	case cfg.MaxFeedSizeMB == 0:
+		return defaultMaxFeedSizeMB << 20
+	default:
+		return int64(cfg.MaxFeedSizeMB) << 20
+	}
+}
+
+// DBMaxOpenConnsOrDefault returns how many connections to the database may
+// be open at once. A negative db_max_open_conns lifts the limit.
+func (cfg *Config) DBMaxOpenConnsOrDefault() int {
+	switch {
+	case cfg.DBMaxOpenConns < 0:
+		return 0
+	case cfg.DBMaxOpenConns == 0:
+		return defaultDBMaxOpenConns
+	default:
+		return cfg.DBMaxOpenConns
+	}
+}
+
+// DBMaxIdleConnsOrDefault returns how many unused database connections are
+// kept open for reuse. A negative db_max_idle_conns keeps none.
+func (cfg *Config) DBMaxIdleConnsOrDefault() int {
+	switch {
+	case cfg.DBMaxIdleConns < 0:
+		return -1
+	case cfg.DBMaxIdleConns == 0:
+		return defaultDBMaxIdleConns
+	default:
+		return cfg.DBMaxIdleConns
+	}
+}
+
+// MaxIdleConnsOrDefault returns how many idle connections are kept open
+// across all hosts for reuse.
+func (cfg *Config) MaxIdleConnsOrDefault() int {
+	if cfg.MaxIdleConns <= 0 {
+		return defaultMaxIdleConns
+	}
+	return cfg.MaxIdleConns
+}
+
+// MaxIdleConnsPerHostOrDefault returns how many idle connections are kept
+// open to a single host. Go's own default of 2 is too few when many feeds
+// live on the same host.
+func (cfg *Config) MaxIdleConnsPerHostOrDefault() int {
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		return defaultMaxIdleConnsPerHost
+	}
+	return cfg.MaxIdleConnsPerHost
+}
+
+// TLSSessionCacheSizeOrDefault returns how many TLS sessions are kept for
+// resuming connections without a full handshake.
+func (cfg *Config) TLSSessionCacheSizeOrDefault() int {
+	if cfg.TLSSessionCacheSize <= 0 {
+		return defaultTLSSessionCacheSize
+	}
+	return cfg.TLSSessionCacheSize
+}
+
+// APIRateLimitOrDefault returns the requests per second one client may
+// make to serve, or 0 when limiting is turned off.
+func (cfg *Config) APIRateLimitOrDefault() float64 {
+	switch {
+	case cfg.APIRateLimit < 0:
+		return 0
+	case cfg.APIRateLimit == 0:
+		return defaultAPIRateLimit
+	default:
+		return cfg.APIRateLimit
+	}
+}
+
+// APIRateBurstOrDefault returns how many requests one client may make at
+// once before the rate limit applies.
+func (cfg *Config) APIRateBurstOrDefault() int {
+	if cfg.APIRateBurst <= 0 {
+		return defaultAPIRateBurst
+	}
+	return cfg.APIRateBurst
+}
+
+// IMAPAddress returns the host:port of the newsletter mailbox, or "" when
+// none is configured. The port defaults to 993, IMAP over TLS.
+func (cfg *Config) IMAPAddress() string {
+	if cfg.IMAPServer == "" {
+		return ""
+	}
+	if _, _, err := net.SplitHostPort(cfg.IMAPServer); err == nil {
+		return cfg.IMAPServer
+	}
+	return net.JoinHostPort(cfg.IMAPServer, defaultIMAPPort)
+}
+
+// PprofAddress returns the address the daemon serves profiles on, or ""
+// when pprof is off.
+func (cfg *Config) PprofAddress() string {
+	switch {
+	case !cfg.Pprof:
+		return ""
+	case cfg.PprofAddr == "":
+		return defaultPprofAddr
+	default:
+		return cfg.PprofAddr
+	}
+}
+
+// IMAPFolderOrDefault returns the mailbox folder newsletters are read
+// from.
+func (cfg *Config) IMAPFolderOrDefault() string {
+	if cfg.IMAPFolder == "" {
+		return defaultIMAPFolder
+	}
+	return cfg.IMAPFolder
+}
+
+// NotifyMinScoreOrDefault returns the relevance a new post must reach to
+// be notified about. With the default of 1, that's posts matching a
+// keyword weighted 1 or more, or from a feed with a priority of 1 or more.
+func (cfg *Config) NotifyMinScoreOrDefault() float64 {
+	if cfg.NotifyMinScore <= 0 {
+		return defaultNotifyMinScore
+	}
+	return cfg.NotifyMinScore
+}
+
+// NtfyServerOrDefault returns the ntfy server notifications are published
+// to, without a trailing slash.
+func (cfg *Config) NtfyServerOrDefault() string {
+	if cfg.NtfyServer == "" {
+		return defaultNtfyServer
+	}
+	return strings.TrimRight(cfg.NtfyServer, "/")
+}
+
+// DataDirPath returns the directory gator keeps its own files in: data_dir
+// from the config, or $XDG_DATA_HOME/gator, or ~/.local/share/gator.
+func (cfg *Config) DataDirPath() (string, error) {
+	if cfg.DataDir != "" {
+		return cfg.DataDir, nil
+	}
+
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "gator"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, ".local", "share", "gator"), nil
+}
+
+// CacheDirPath returns where raw feed bodies are cached.
+func (cfg *Config) CacheDirPath() (string, error) {
+	dataDir, err := cfg.DataDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, "cache"), nil
+}
+
+// PIDFilePath returns where a running daemon records its process ID.
+func (cfg *Config) PIDFilePath() (string, error) {
+	dataDir, err := cfg.DataDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, "gator.pid"), nil
+}
+
+// LogFilePath returns where a background daemon writes its log.
+func (cfg *Config) LogFilePath() (string, error) {
+	dataDir, err := cfg.DataDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, "gator.log"), nil
+}
+
+// LogMaxSizeOrDefault returns how many bytes the daemon's log file may
+// grow to before it is rotated.
+func (cfg *Config) LogMaxSizeOrDefault() int64 {
+	if cfg.LogMaxSizeMB <= 0 {
+		return defaultLogMaxSizeMB << 20
+	}
+	return int64(cfg.LogMaxSizeMB) << 20
+}
+
+// LogMaxAgeDuration returns how long the daemon writes to one log file
+// before it is rotated.
+func (cfg *Config) LogMaxAgeDuration() (time.Duration, error) {
+	return parseDuration("log_max_age", cfg.LogMaxAge, defaultLogMaxAge)
+}
+
+// LogMaxFilesOrDefault returns how many rotated log files are kept.
+func (cfg *Config) LogMaxFilesOrDefault() int {
+	if cfg.LogMaxFiles <= 0 {
+		return defaultLogMaxFiles
+	}
+	return cfg.LogMaxFiles
+}
+
+// ListingFilePath returns where the post IDs of the last browse listing
+// are kept, so later commands can refer to posts by number.
+func (cfg *Config) ListingFilePath() (string, error) {
+	dataDir, err := cfg.DataDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, "last_listing"), nil
+}
+
+// SecretKeyFilePath returns where the key that encrypts stored feed
+// credentials is kept.
+func (cfg *Config) SecretKeyFilePath() (string, error) {
+	dataDir, err := cfg.DataDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, "secret.key"), nil
+}
+
+// FixtureDirPath returns where recorded fetch fixtures are kept: fixture_dir
+// from the config, or a fixtures directory inside the data directory.
+func (cfg *Config) FixtureDirPath() (string, error) {
+	if cfg.FixtureDir != "" {
+		return cfg.FixtureDir, nil
+	}
+
+	dataDir, err := cfg.DataDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, "fixtures"), nil
+}
+
+// HostDelayDuration returns the minimum time between two requests to the
+// same host, e.g. "500ms" or "2s". Zero disables the limit.
+func (cfg *Config) HostDelayDuration() (time.Duration, error) {
+	return parseDuration("host_delay", cfg.HostDelay, defaultHostDelay)
+}
+
+// DaemonEveryDuration returns how often the daemon checks which feeds are
+// due, 5 minutes unless daemon_every says otherwise.
+func (cfg *Config) DaemonEveryDuration() (time.Duration, error) {
+	d, err := parseDuration("daemon_every", cfg.DaemonEvery, defaultDaemonEvery)
+	if err == nil && d == 0 {
+		err = fmt.Errorf("invalid daemon_every %q", cfg.DaemonEvery)
+	}
+	return d, err
+}
+
+// DBTimeoutDuration bounds a single database operation, so an unreachable
+// database fails the command instead of hanging it.
+func (cfg *Config) DBTimeoutDuration() (time.Duration, error) {
+	return parseDuration("db_timeout", cfg.DBTimeout, defaultDBTimeout)
+}
+
+// FetchTimeoutDuration bounds fetching a single feed.
+func (cfg *Config) FetchTimeoutDuration() (time.Duration, error) {
+	return parseDuration("fetch_timeout", cfg.FetchTimeout, defaultFetchTimeout)
+}
+
+// DBConnMaxLifetimeDuration returns how long a database connection is
+// reused before it is closed, so connections don't outlive a failover or a
+// pooler's own limits.
+func (cfg *Config) DBConnMaxLifetimeDuration() (time.Duration, error) {
+	return parseDuration("db_conn_max_lifetime", cfg.DBConnMaxLifetime, defaultDBConnMaxLifetime)
+}
+
+// FetchLogRetentionDuration returns how long fetch attempts are kept in
+// the fetch log before agg deletes them.
+func (cfg *Config) FetchLogRetentionDuration() (time.Duration, error) {
+	return parseDuration("fetch_log_retention", cfg.FetchLogRetention, defaultFetchLogRetention)
+}
+
+// IdleConnTimeoutDuration returns how long an unused connection is kept
+// open before it is closed.
+func (cfg *Config) IdleConnTimeoutDuration() (time.Duration, error) {
+	return parseDuration("idle_conn_timeout", cfg.IdleConnTimeout, defaultIdleConnTimeout)
+}
+
+// QuietHoursWindow returns when quiet hours start and end, as times of
+// day. ok is false when none are configured. The window may wrap past
+// midnight.
+func (cfg *Config) QuietHoursWindow() (start, end time.Duration, ok bool, err error) {
+	if cfg.QuietHours == "" {
+		return 0, 0, false, nil
+	}
+
+	// accept the en dash people write time ranges with too
+	from, to, found := strings.Cut(strings.ReplaceAll(cfg.QuietHours, "–", "-"), "-")
+	if !found {
+		return 0, 0, false, fmt.Errorf("invalid quiet_hours %q, expected e.g. 23:00-07:00", cfg.QuietHours)
+	}
+	if start, err = parseTimeOfDay(from); err == nil {
+		end, err = parseTimeOfDay(to)
+	}
+	if err != nil || start == end {
+		return 0, 0, false, fmt.Errorf("invalid quiet_hours %q, expected e.g. 23:00-07:00", cfg.QuietHours)
+	}
+	return start, end, true, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// QuietHoursPause reports what quiet hours pause: fetching, notifications
+// or both.
+func (cfg *Config) QuietHoursPause() (fetch, notify bool, err error) {
+	switch cfg.QuietHoursMode {
+	case "", "both":
+		return true, true, nil
+	case "fetch":
+		return true, false, nil
+	case "notify":
+		return false, true, nil
+	default:
+		return false, false, fmt.Errorf("invalid quiet_hours_mode %q, expected fetch, notify or both", cfg.QuietHoursMode)
+	}
 }
 
 func (cfg *Config) Read() (Config, error) {
@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
 )
@@ -19,7 +20,7 @@ VALUES (
     $3,
     $4
 )
-RETURNING id, created_at, updated_at, name, url, user_id
+RETURNING id, created_at, updated_at, name, url, user_id, author, image_url, user_agent, schedule, last_fetched_at, poll_interval_seconds, skip_hours, skip_days, websub_hub, websub_topic, auth, scraper, priority, paused_at, tier, content_hash, shared
 `
 
 type CreateFeedParams struct {
@@ -44,12 +45,116 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Name,
 		&i.Url,
 		&i.UserID,
+		&i.Author,
+		&i.ImageUrl,
+		&i.UserAgent,
+		&i.Schedule,
+		&i.LastFetchedAt,
+		&i.PollIntervalSeconds,
+		&i.SkipHours,
+		&i.SkipDays,
+		&i.WebsubHub,
+		&i.WebsubTopic,
+		&i.Auth,
+		&i.Scraper,
+		&i.Priority,
+		&i.PausedAt,
+		&i.Tier,
+		&i.ContentHash,
+		&i.Shared,
+	)
+	return i, err
+}
+
+const deleteFeed = `-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeed, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getFeedById = `-- name: GetFeedById :one
+SELECT id, created_at, updated_at, name, url, user_id, author, image_url, user_agent, schedule, last_fetched_at, poll_interval_seconds, skip_hours, skip_days, websub_hub, websub_topic, auth, scraper, priority, paused_at, tier, content_hash, shared
+FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) GetFeedById(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedById, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.Author,
+		&i.ImageUrl,
+		&i.UserAgent,
+		&i.Schedule,
+		&i.LastFetchedAt,
+		&i.PollIntervalSeconds,
+		&i.SkipHours,
+		&i.SkipDays,
+		&i.WebsubHub,
+		&i.WebsubTopic,
+		&i.Auth,
+		&i.Scraper,
+		&i.Priority,
+		&i.PausedAt,
+		&i.Tier,
+		&i.ContentHash,
+		&i.Shared,
+	)
+	return i, err
+}
+
+const getFeedByUrl = `-- name: GetFeedByUrl :one
+SELECT id, created_at, updated_at, name, url, user_id, author, image_url, user_agent, schedule, last_fetched_at, poll_interval_seconds, skip_hours, skip_days, websub_hub, websub_topic, auth, scraper, priority, paused_at, tier, content_hash, shared
+FROM feeds
+WHERE url = $1
+`
+
+func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByUrl, url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.Author,
+		&i.ImageUrl,
+		&i.UserAgent,
+		&i.Schedule,
+		&i.LastFetchedAt,
+		&i.PollIntervalSeconds,
+		&i.SkipHours,
+		&i.SkipDays,
+		&i.WebsubHub,
+		&i.WebsubTopic,
+		&i.Auth,
+		&i.Scraper,
+		&i.Priority,
+		&i.PausedAt,
+		&i.Tier,
+		&i.ContentHash,
+		&i.Shared,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id
+SELECT id, created_at, updated_at, name, url, user_id, author, image_url, user_agent, schedule, last_fetched_at, poll_interval_seconds, skip_hours, skip_days, websub_hub, websub_topic, auth, scraper, priority, paused_at, tier, content_hash, shared
 FROM feeds
 `
 
@@ -69,6 +174,23 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Name,
 			&i.Url,
 			&i.UserID,
+			&i.Author,
+			&i.ImageUrl,
+			&i.UserAgent,
+			&i.Schedule,
+			&i.LastFetchedAt,
+			&i.PollIntervalSeconds,
+			&i.SkipHours,
+			&i.SkipDays,
+			&i.WebsubHub,
+			&i.WebsubTopic,
+			&i.Auth,
+			&i.Scraper,
+			&i.Priority,
+			&i.PausedAt,
+			&i.Tier,
+			&i.ContentHash,
+			&i.Shared,
 		); err != nil {
 			return nil, err
 		}
@@ -84,7 +206,7 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 }
 
 const getFeedsByName = `-- name: GetFeedsByName :many
-SELECT id, created_at, updated_at, name, url, user_id
+SELECT id, created_at, updated_at, name, url, user_id, author, image_url, user_agent, schedule, last_fetched_at, poll_interval_seconds, skip_hours, skip_days, websub_hub, websub_topic, auth, scraper, priority, paused_at, tier, content_hash, shared
 FROM feeds
 WHERE name = $1
 `
@@ -105,6 +227,84 @@ func (q *Queries) GetFeedsByName(ctx context.Context, name string) ([]Feed, erro
 			&i.Name,
 			&i.Url,
 			&i.UserID,
+			&i.Author,
+			&i.ImageUrl,
+			&i.UserAgent,
+			&i.Schedule,
+			&i.LastFetchedAt,
+			&i.PollIntervalSeconds,
+			&i.SkipHours,
+			&i.SkipDays,
+			&i.WebsubHub,
+			&i.WebsubTopic,
+			&i.Auth,
+			&i.Scraper,
+			&i.Priority,
+			&i.PausedAt,
+			&i.Tier,
+			&i.ContentHash,
+			&i.Shared,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsWithFollowers = `-- name: GetFeedsWithFollowers :many
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.author, feeds.image_url, feeds.user_agent, feeds.schedule, feeds.last_fetched_at, feeds.poll_interval_seconds, feeds.skip_hours, feeds.skip_days, feeds.websub_hub, feeds.websub_topic, feeds.auth, feeds.scraper, feeds.priority, feeds.paused_at, feeds.tier, feeds.content_hash, feeds.shared, COUNT(feed_follows.id) AS followers
+FROM feeds
+LEFT JOIN feed_follows ON feed_follows.feed_id = feeds.id
+GROUP BY feeds.id
+ORDER BY feeds.created_at
+`
+
+type GetFeedsWithFollowersRow struct {
+	Feed      Feed
+	Followers int64
+}
+
+func (q *Queries) GetFeedsWithFollowers(ctx context.Context) ([]GetFeedsWithFollowersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsWithFollowers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedsWithFollowersRow
+	for rows.Next() {
+		var i GetFeedsWithFollowersRow
+		if err := rows.Scan(
+			&i.Feed.ID,
+			&i.Feed.CreatedAt,
+			&i.Feed.UpdatedAt,
+			&i.Feed.Name,
+			&i.Feed.Url,
+			&i.Feed.UserID,
+			&i.Feed.Author,
+			&i.Feed.ImageUrl,
+			&i.Feed.UserAgent,
+			&i.Feed.Schedule,
+			&i.Feed.LastFetchedAt,
+			&i.Feed.PollIntervalSeconds,
+			&i.Feed.SkipHours,
+			&i.Feed.SkipDays,
+			&i.Feed.WebsubHub,
+			&i.Feed.WebsubTopic,
+			&i.Feed.Auth,
+			&i.Feed.Scraper,
+			&i.Feed.Priority,
+			&i.Feed.PausedAt,
+			&i.Feed.Tier,
+			&i.Feed.ContentHash,
+			&i.Feed.Shared,
+			&i.Followers,
 		); err != nil {
 			return nil, err
 		}
@@ -118,3 +318,308 @@ func (q *Queries) GetFeedsByName(ctx context.Context, name string) ([]Feed, erro
 	}
 	return items, nil
 }
+
+const markFeedFetched = `-- name: MarkFeedFetched :exec
+UPDATE feeds
+SET last_fetched_at = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type MarkFeedFetchedParams struct {
+	ID            uuid.UUID
+	LastFetchedAt sql.NullTime
+}
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetched,
+		arg.ID,
+		arg.LastFetchedAt,
+	)
+	return err
+}
+
+const pauseFeed = `-- name: PauseFeed :execrows
+UPDATE feeds
+SET paused_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND paused_at IS NULL
+`
+
+func (q *Queries) PauseFeed(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pauseFeed, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const resumeFeed = `-- name: ResumeFeed :execrows
+UPDATE feeds
+SET paused_at = NULL, updated_at = NOW()
+WHERE id = $1 AND paused_at IS NOT NULL
+`
+
+func (q *Queries) ResumeFeed(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, resumeFeed, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setFeedAuth = `-- name: SetFeedAuth :exec
+UPDATE feeds
+SET auth = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedAuthParams struct {
+	ID   uuid.UUID
+	Auth []byte
+}
+
+func (q *Queries) SetFeedAuth(ctx context.Context, arg SetFeedAuthParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedAuth,
+		arg.ID,
+		arg.Auth,
+	)
+	return err
+}
+
+const setFeedContentHash = `-- name: SetFeedContentHash :exec
+UPDATE feeds
+SET content_hash = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedContentHashParams struct {
+	ID          uuid.UUID
+	ContentHash sql.NullString
+}
+
+func (q *Queries) SetFeedContentHash(ctx context.Context, arg SetFeedContentHashParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedContentHash,
+		arg.ID,
+		arg.ContentHash,
+	)
+	return err
+}
+
+const setFeedOwner = `-- name: SetFeedOwner :exec
+UPDATE feeds
+SET user_id = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedOwnerParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) SetFeedOwner(ctx context.Context, arg SetFeedOwnerParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedOwner,
+		arg.ID,
+		arg.UserID,
+	)
+	return err
+}
+
+const setFeedPriority = `-- name: SetFeedPriority :exec
+UPDATE feeds
+SET priority = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedPriorityParams struct {
+	ID       uuid.UUID
+	Priority int32
+}
+
+func (q *Queries) SetFeedPriority(ctx context.Context, arg SetFeedPriorityParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedPriority,
+		arg.ID,
+		arg.Priority,
+	)
+	return err
+}
+
+const setFeedSchedule = `-- name: SetFeedSchedule :exec
+UPDATE feeds
+SET schedule = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedScheduleParams struct {
+	ID       uuid.UUID
+	Schedule sql.NullString
+}
+
+func (q *Queries) SetFeedSchedule(ctx context.Context, arg SetFeedScheduleParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedSchedule,
+		arg.ID,
+		arg.Schedule,
+	)
+	return err
+}
+
+const setFeedScraper = `-- name: SetFeedScraper :exec
+UPDATE feeds
+SET scraper = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedScraperParams struct {
+	ID      uuid.UUID
+	Scraper sql.NullString
+}
+
+func (q *Queries) SetFeedScraper(ctx context.Context, arg SetFeedScraperParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedScraper,
+		arg.ID,
+		arg.Scraper,
+	)
+	return err
+}
+
+const setFeedShared = `-- name: SetFeedShared :execrows
+UPDATE feeds
+SET shared = $2, updated_at = NOW()
+WHERE id = $1 AND shared <> $2
+`
+
+type SetFeedSharedParams struct {
+	ID     uuid.UUID
+	Shared bool
+}
+
+func (q *Queries) SetFeedShared(ctx context.Context, arg SetFeedSharedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedShared,
+		arg.ID,
+		arg.Shared,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setFeedTier = `-- name: SetFeedTier :exec
+UPDATE feeds
+SET tier = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedTierParams struct {
+	ID   uuid.UUID
+	Tier string
+}
+
+func (q *Queries) SetFeedTier(ctx context.Context, arg SetFeedTierParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedTier,
+		arg.ID,
+		arg.Tier,
+	)
+	return err
+}
+
+const setFeedUserAgent = `-- name: SetFeedUserAgent :exec
+UPDATE feeds
+SET user_agent = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedUserAgentParams struct {
+	ID        uuid.UUID
+	UserAgent sql.NullString
+}
+
+func (q *Queries) SetFeedUserAgent(ctx context.Context, arg SetFeedUserAgentParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedUserAgent,
+		arg.ID,
+		arg.UserAgent,
+	)
+	return err
+}
+
+const updateFeedFetchHints = `-- name: UpdateFeedFetchHints :exec
+UPDATE feeds
+SET poll_interval_seconds = $2, skip_hours = $3, skip_days = $4, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateFeedFetchHintsParams struct {
+	ID                  uuid.UUID
+	PollIntervalSeconds sql.NullInt32
+	SkipHours           sql.NullInt32
+	SkipDays            sql.NullInt32
+}
+
+func (q *Queries) UpdateFeedFetchHints(ctx context.Context, arg UpdateFeedFetchHintsParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedFetchHints,
+		arg.ID,
+		arg.PollIntervalSeconds,
+		arg.SkipHours,
+		arg.SkipDays,
+	)
+	return err
+}
+
+const updateFeedMetadata = `-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET author = $2, image_url = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateFeedMetadataParams struct {
+	ID       uuid.UUID
+	Author   sql.NullString
+	ImageUrl sql.NullString
+}
+
+func (q *Queries) UpdateFeedMetadata(ctx context.Context, arg UpdateFeedMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedMetadata,
+		arg.ID,
+		arg.Author,
+		arg.ImageUrl,
+	)
+	return err
+}
+
+const updateFeedUrl = `-- name: UpdateFeedUrl :exec
+UPDATE feeds
+SET url = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateFeedUrlParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) UpdateFeedUrl(ctx context.Context, arg UpdateFeedUrlParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedUrl,
+		arg.ID,
+		arg.Url,
+	)
+	return err
+}
+
+const updateFeedWebSub = `-- name: UpdateFeedWebSub :exec
+UPDATE feeds
+SET websub_hub = $2, websub_topic = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateFeedWebSubParams struct {
+	ID          uuid.UUID
+	WebsubHub   sql.NullString
+	WebsubTopic sql.NullString
+}
+
+func (q *Queries) UpdateFeedWebSub(ctx context.Context, arg UpdateFeedWebSubParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedWebSub,
+		arg.ID,
+		arg.WebsubHub,
+		arg.WebsubTopic,
+	)
+	return err
+}
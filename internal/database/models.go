@@ -5,23 +5,193 @@
 package database
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Feed struct {
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	Author              sql.NullString
+	ImageUrl            sql.NullString
+	UserAgent           sql.NullString
+	Schedule            sql.NullString
+	LastFetchedAt       sql.NullTime
+	PollIntervalSeconds sql.NullInt32
+	SkipHours           sql.NullInt32
+	SkipDays            sql.NullInt32
+	WebsubHub           sql.NullString
+	WebsubTopic         sql.NullString
+	Auth                []byte
+	Scraper             sql.NullString
+	Priority            int32
+	PausedAt            sql.NullTime
+	Tier                string
+	ContentHash         sql.NullString
+	Shared              bool
+}
+
+type FeedAlias struct {
+	UserID    uuid.UUID
+	Alias     string
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type FeedFollow struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Name      string
-	Url       string
 	UserID    uuid.UUID
+	FeedID    uuid.UUID
 }
 
-type User struct {
-	ID        uuid.UUID
+type FeedHeader struct {
+	FeedID uuid.UUID
+	Name   string
+	Value  string
+}
+
+type FeedOwnerChange struct {
+	ID        int64
+	FeedID    uuid.UUID
+	FromUser  string
+	ToUser    string
+	ChangedBy string
+	ChangedAt time.Time
+}
+
+type FeedTag struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+	Tag    string
+}
+
+type FetchLog struct {
+	ID         int64
+	FeedID     uuid.UUID
+	FetchedAt  time.Time
+	StatusCode sql.NullInt32
+	DurationMs int32
+	ItemsSeen  int32
+	NewPosts   int32
+	Error      sql.NullString
+	Bytes      sql.NullInt64
+}
+
+type Folder struct {
+	UserID    uuid.UUID
+	Name      string
+	Keywords  []string
+	Tags      []string
+	Authors   []string
+	CreatedAt time.Time
+}
+
+type KeywordWeight struct {
+	Keyword string
+	Weight  float64
+}
+
+type Post struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           string
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.UUID
+	EnclosureUrl    sql.NullString
+	EnclosureType   sql.NullString
+	EnclosureLength sql.NullInt64
+	Author          sql.NullString
+	ImageUrl        sql.NullString
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	Season          sql.NullInt32
+	ThumbnailUrl    sql.NullString
+	CanonicalUrl    sql.NullString
+	TitleHash       sql.NullString
+	Score           sql.NullInt32
+	CommentCount    sql.NullInt32
+	Relevance       float64
+	Guid            sql.NullString
+	EditedAt        sql.NullTime
+}
+
+type PostNote struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	Note      string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type PostStar struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	StarredAt time.Time
+}
+
+type PostTag struct {
+	PostID uuid.UUID
+	Tag    string
+}
+
+type SavedSearch struct {
+	UserID    uuid.UUID
 	Name      string
+	Terms     string
+	FeedID    uuid.NullUUID
+	Tag       sql.NullString
+	Starred   bool
+	Unread    bool
+	CreatedAt time.Time
+}
+
+type Session struct {
+	TokenHash string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type User struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	Timezone     sql.NullString
+	LastSeenAt   sql.NullTime
+	PasswordHash sql.NullString
+	IsAdmin      bool
+}
+
+type UserPreference struct {
+	UserID    uuid.UUID
+	Key       string
+	Value     string
+	UpdatedAt time.Time
+}
+
+type WebsubSubscription struct {
+	FeedID         uuid.UUID
+	Hub            string
+	Topic          string
+	Secret         string
+	RequestedAt    time.Time
+	LeaseExpiresAt sql.NullTime
 }
@@ -7,11 +7,24 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const countAdmins = `-- name: CountAdmins :one
+SELECT COUNT(*) FROM users
+WHERE is_admin
+`
+
+func (q *Queries) CountAdmins(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAdmins)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, created_at, updated_at, name)
 VALUES (
@@ -20,7 +33,7 @@ VALUES (
     $3,
     $4
 )
-RETURNING id, created_at, updated_at, name
+RETURNING id, created_at, updated_at, name, timezone, last_seen_at, password_hash, is_admin
 `
 
 type CreateUserParams struct {
@@ -43,10 +56,27 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.Timezone,
+		&i.LastSeenAt,
+		&i.PasswordHash,
+		&i.IsAdmin,
 	)
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteUsers = `-- name: DeleteUsers :exec
 DELETE FROM users
 `
@@ -57,7 +87,7 @@ func (q *Queries) DeleteUsers(ctx context.Context) error {
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, created_at, updated_at, name FROM users
+SELECT id, created_at, updated_at, name, timezone, last_seen_at, password_hash, is_admin FROM users
 WHERE id = $1
 `
 
@@ -69,12 +99,81 @@ func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.Timezone,
+		&i.LastSeenAt,
+		&i.PasswordHash,
+		&i.IsAdmin,
 	)
 	return i, err
 }
 
+const getUserStats = `-- name: GetUserStats :many
+SELECT
+    users.id,
+    users.name,
+    users.is_admin,
+    (SELECT COUNT(*) FROM feeds WHERE feeds.user_id = users.id) AS feed_count,
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = users.id) AS follow_count,
+    (SELECT COUNT(*) FROM posts
+        JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+        WHERE feed_follows.user_id = users.id) AS post_count,
+    (SELECT COUNT(*) FROM post_reads WHERE post_reads.user_id = users.id) AS read_count,
+    COALESCE(GREATEST(
+        users.last_seen_at,
+        (SELECT MAX(read_at) FROM post_reads WHERE post_reads.user_id = users.id),
+        (SELECT MAX(starred_at) FROM post_stars WHERE post_stars.user_id = users.id),
+        (SELECT MAX(created_at) FROM sessions WHERE sessions.user_id = users.id),
+        (SELECT MAX(created_at) FROM feed_follows WHERE feed_follows.user_id = users.id)
+    ), users.created_at)::TIMESTAMP AS last_active_at
+FROM users
+ORDER BY users.name
+`
+
+type GetUserStatsRow struct {
+	ID           uuid.UUID
+	Name         string
+	IsAdmin      bool
+	FeedCount    int64
+	FollowCount  int64
+	PostCount    int64
+	ReadCount    int64
+	LastActiveAt time.Time
+}
+
+func (q *Queries) GetUserStats(ctx context.Context) ([]GetUserStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserStats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserStatsRow
+	for rows.Next() {
+		var i GetUserStatsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.IsAdmin,
+			&i.FeedCount,
+			&i.FollowCount,
+			&i.PostCount,
+			&i.ReadCount,
+			&i.LastActiveAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUsers = `-- name: GetUsers :many
-SELECT id, created_at, updated_at, name FROM users
+SELECT id, created_at, updated_at, name, timezone, last_seen_at, password_hash, is_admin FROM users
 `
 
 func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
@@ -91,6 +190,10 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Name,
+			&i.Timezone,
+			&i.LastSeenAt,
+			&i.PasswordHash,
+			&i.IsAdmin,
 		); err != nil {
 			return nil, err
 		}
@@ -106,8 +209,8 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 }
 
 const getUsersByName = `-- name: GetUsersByName :many
-SELECT id, created_at, updated_at, name FROM users
-WHERE name = $1
+SELECT id, created_at, updated_at, name, timezone, last_seen_at, password_hash, is_admin FROM users
+WHERE LOWER(name) = LOWER($1)
 `
 
 func (q *Queries) GetUsersByName(ctx context.Context, name string) ([]User, error) {
@@ -124,6 +227,10 @@ func (q *Queries) GetUsersByName(ctx context.Context, name string) ([]User, erro
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Name,
+			&i.Timezone,
+			&i.LastSeenAt,
+			&i.PasswordHash,
+			&i.IsAdmin,
 		); err != nil {
 			return nil, err
 		}
@@ -137,3 +244,71 @@ func (q *Queries) GetUsersByName(ctx context.Context, name string) ([]User, erro
 	}
 	return items, nil
 }
+
+const markUserSeen = `-- name: MarkUserSeen :exec
+UPDATE users
+SET last_seen_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) MarkUserSeen(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markUserSeen, id)
+	return err
+}
+
+const setUserAdmin = `-- name: SetUserAdmin :exec
+UPDATE users
+SET is_admin = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserAdminParams struct {
+	ID      uuid.UUID
+	IsAdmin bool
+}
+
+func (q *Queries) SetUserAdmin(ctx context.Context, arg SetUserAdminParams) error {
+	_, err := q.db.ExecContext(ctx, setUserAdmin,
+		arg.ID,
+		arg.IsAdmin,
+	)
+	return err
+}
+
+const setUserPassword = `-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserPasswordParams struct {
+	ID           uuid.UUID
+	PasswordHash sql.NullString
+}
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPassword,
+		arg.ID,
+		arg.PasswordHash,
+	)
+	return err
+}
+
+const setUserTimezone = `-- name: SetUserTimezone :exec
+UPDATE users
+SET timezone = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserTimezoneParams struct {
+	ID       uuid.UUID
+	Timezone sql.NullString
+}
+
+func (q *Queries) SetUserTimezone(ctx context.Context, arg SetUserTimezoneParams) error {
+	_, err := q.db.ExecContext(ctx, setUserTimezone,
+		arg.ID,
+		arg.Timezone,
+	)
+	return err
+}
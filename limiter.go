@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostLimiter spaces out requests to the same host by a minimum delay so
+// many feeds on one site (Substack, Medium, ...) don't get hammered at once.
+// It is safe for concurrent use.
+type hostLimiter struct {
+	delay time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newHostLimiter(delay time.Duration) *hostLimiter {
+	return &hostLimiter{
+		delay: delay,
+		next:  make(map[string]time.Time),
+	}
+}
+
+// Wait blocks until a request to rawURL's host is allowed, or ctx is done.
+func (l *hostLimiter) Wait(ctx context.Context, rawURL string) error {
+	if l == nil {
+		return nil
+	}
+
+	host := limiterHost(rawURL)
+
+	// reserve the next slot for this host before sleeping so concurrent
+	// callers queue up behind each other instead of all waking together
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(l.delay)
+	l.mu.Unlock()
+
+	wait := time.Until(slot)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Defer holds off every request to rawURL's host until until, for hosts
+// that asked to be left alone for a while, e.g. with a 429 response.
+func (l *hostLimiter) Defer(rawURL string, until time.Time) {
+	if l == nil {
+		return
+	}
+
+	host := limiterHost(rawURL)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.next[host].Before(until) {
+		l.next[host] = until
+	}
+}
+
+func limiterHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	return rawURL
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+// defaultAggLockKey identifies the Postgres advisory lock held while
+// aggregating ("gator" in ASCII).
+const defaultAggLockKey int64 = 0x6761746f72
+
+// aggLockKey is the advisory lock for the gator whose tables are in
+// schema. Advisory locks span the whole database, so gators sharing one
+// from different schemas each get a key of their own.
+func aggLockKey(schema string) int64 {
+	if schema == "" {
+		return defaultAggLockKey
+	}
+	h := fnv.New64a()
+	h.Write([]byte("gator:" + schema))
+	return int64(h.Sum64())
+}
+
+var errAggLocked = errors.New("another aggregation run is in progress")
+
+// lockAggregation takes the aggregation advisory lock without waiting,
+// returning errAggLocked if another run holds it. The lock is held until
+// the returned release func is called.
+func lockAggregation(ctx context.Context, s *state) (func(), error) {
+	unlock, locked, err := s.db.TryLock(ctx, aggLockKey(s.Config.DBSchema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to take aggregation lock: %v", err)
+	}
+	if !locked {
+		return nil, errAggLocked
+	}
+
+	release := func() {
+		// the run may have been cancelled; unlocking must still happen
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.dbTimeout)
+		defer cancel()
+
+		if err := unlock(ctx); err != nil {
+			fmt.Printf("Error releasing aggregation lock: %v\n", err)
+		}
+	}
+
+	return release, nil
+}
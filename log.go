@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// verbosity is how much gator says besides a command's own output and
+// its errors: -q keeps only those, -v adds debug detail such as a summary
+// of every HTTP request, and -vv adds the headers too.
+type verbosity int
+
+const (
+	verbosityQuiet verbosity = iota - 1
+	verbosityNormal
+	verbosityDebug
+	verbosityTrace
+)
+
+// currentVerbosity is set from the global flags, or log_level in the
+// config without any, before a command runs; the daemon sets it again
+// when it reloads the config, so it is read through logLevel.
+var currentVerbosity atomic.Int32
+
+// levelFromFlags records that -q or -v chose the level, which then wins
+// over log_level.
+var levelFromFlags bool
+
+func logLevel() verbosity {
+	return verbosity(currentVerbosity.Load())
+}
+
+func setLogLevel(level verbosity) {
+	currentVerbosity.Store(int32(level))
+}
+
+// parseVerbosity reads log_level from the config: quiet, normal, debug
+// or trace, like no flag, -q, -v and -vv.
+func parseVerbosity(name string) (verbosity, error) {
+	switch name {
+	case "quiet":
+		return verbosityQuiet, nil
+	case "", "normal":
+		return verbosityNormal, nil
+	case "debug":
+		return verbosityDebug, nil
+	case "trace":
+		return verbosityTrace, nil
+	}
+	return verbosityNormal, fmt.Errorf("invalid log_level %q, expected quiet, normal, debug or trace", name)
+}
+
+// globalOptions are the flags given before the command.
+type globalOptions struct {
+	level verbosity
+	// levelSet is whether -q or -v gave the level
+	levelSet    bool
+	autoMigrate bool
+}
+
+// parseGlobalFlags takes -q/--quiet, -v/--verbose (repeatable, or -vv)
+// and --auto-migrate off the front of args and returns the rest,
+// starting with the command.
+func parseGlobalFlags(args []string) (globalOptions, []string, error) {
+	opts := globalOptions{level: verbosityNormal}
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		switch args[0] {
+		case "-q", "--quiet":
+			opts.level = verbosityQuiet
+		case "-v", "--verbose":
+			opts.level = max(opts.level, verbosityNormal) + 1
+		case "-vv":
+			opts.level = verbosityTrace
+		case "--auto-migrate":
+			opts.autoMigrate = true
+			args = args[1:]
+			continue
+		default:
+			return opts, nil, usageErrorf("unknown flag %s, expected -q, -v, -vv or --auto-migrate before the command", args[0])
+		}
+		opts.levelSet = true
+		args = args[1:]
+	}
+	opts.level = min(opts.level, verbosityTrace)
+	return opts, args, nil
+}
+
+// globalFlags turns a level chosen by flags back into flags, for gator
+// to pass on to the copies of itself it starts; those read log_level
+// themselves.
+func globalFlags() []string {
+	if !levelFromFlags {
+		return nil
+	}
+	switch logLevel() {
+	case verbosityQuiet:
+		return []string{"-q"}
+	case verbosityDebug:
+		return []string{"-v"}
+	case verbosityTrace:
+		return []string{"-vv"}
+	}
+	return nil
+}
+
+// infof prints informational chatter, the kind -q is for silencing.
+func infof(format string, args ...any) {
+	if logLevel() >= verbosityNormal {
+		fmt.Printf(format, args...)
+	}
+}
+
+// debugf prints debug detail to stderr with -v.
+func debugf(format string, args ...any) {
+	if logLevel() >= verbosityDebug {
+		fmt.Fprintf(os.Stderr, "debug: "+format, args...)
+	}
+}
+
+// tracef prints even more detail to stderr with -vv.
+func tracef(format string, args ...any) {
+	if logLevel() >= verbosityTrace {
+		fmt.Fprintf(os.Stderr, "trace: "+format, args...)
+	}
+}
+
+// loggingTransport summarises every request made through it with -v,
+// and with -vv the headers sent and received. It stays out of the way at
+// lower levels, which a reloaded log_level can raise.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if logLevel() < verbosityDebug {
+		return t.next.RoundTrip(req)
+	}
+	tracef("%s %s\n%s", req.Method, redactURL(req.URL), formatHeaders("> ", req.Header))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		debugf("%s %s failed after %s: %v\n", req.Method, redactURL(req.URL), elapsed, err)
+		return nil, err
+	}
+
+	size := "unknown size"
+	if resp.ContentLength >= 0 {
+		size = formatBytes(resp.ContentLength)
+	}
+	debugf("%s %s: %s in %s, %s\n", req.Method, redactURL(req.URL), resp.Status, elapsed, size)
+	tracef("%s", formatHeaders("< ", resp.Header))
+	return resp, nil
+}
+
+// redactURL hides the password in u, like url.URL.Redacted, and every
+// query value too, since feed auth can put credentials there.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	if query := redacted.Query(); len(query) > 0 {
+		for _, values := range query {
+			for i := range values {
+				values[i] = "xxxxx"
+			}
+		}
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.Redacted()
+}
+
+// formatHeaders lists headers one per line, hiding the values of the ones
+// that carry credentials.
+func formatHeaders(prefix string, header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		for _, value := range header[name] {
+			switch http.CanonicalHeaderKey(name) {
+			case "Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization":
+				value = "[redacted]"
+			}
+			fmt.Fprintf(&b, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatingLog is the daemon's log file. Once it reaches maxSize, or its
+// first line is maxAge old, it is renamed to gator.log.1, the older ones
+// move up a number, and anything past maxFiles is deleted.
+type rotatingLog struct {
+	path     string
+	maxSize  int64
+	maxAge   time.Duration
+	maxFiles int
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	started time.Time
+}
+
+func openRotatingLog(s *state) (*rotatingLog, error) {
+	path, err := s.Config.LogFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate log file: %v", err)
+	}
+	maxAge, err := s.Config.LogMaxAgeDuration()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %v", err)
+	}
+
+	l := &rotatingLog{
+		path:     path,
+		maxSize:  s.Config.LogMaxSizeOrDefault(),
+		maxAge:   maxAge,
+		maxFiles: s.Config.LogMaxFilesOrDefault(),
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open appends to the log file, working out how old it is from the time
+// on its first line.
+func (l *rotatingLog) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+
+	l.file = file
+	l.size = info.Size()
+	l.started = time.Now()
+	if l.size > 0 {
+		l.started = info.ModTime()
+		if first, err := os.Open(l.path); err == nil {
+			line, _ := bufio.NewReader(first).ReadString(' ')
+			if t, err := time.Parse(time.RFC3339, strings.TrimSpace(line)); err == nil {
+				l.started = t
+			}
+			first.Close()
+		}
+	}
+	return nil
+}
+
+func (l *rotatingLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.size > 0 && (l.size+int64(len(p)) > l.maxSize || time.Since(l.started) >= l.maxAge) {
+		if err := l.rotate(); err != nil {
+			// keep logging to the old file rather than lose lines
+			fmt.Fprintf(l.file, "%s Error rotating log: %v\n", time.Now().Format(time.RFC3339), err)
+		}
+	}
+
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+func (l *rotatingLog) rotate() error {
+	for i := l.maxFiles; i >= 1; i-- {
+		older := fmt.Sprintf("%s.%d", l.path, i)
+		if i == l.maxFiles {
+			if err := os.Remove(older); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := os.Rename(older, fmt.Sprintf("%s.%d", l.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		return err
+	}
+
+	old := l.file
+	if err := l.open(); err != nil {
+		return err
+	}
+	return old.Close()
+}
+
+func (l *rotatingLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// handlerLogs prints the end of the daemon's log and, with -f, keeps
+// printing what is added to it, across rotations, until interrupted.
+func handlerLogs(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	follow := fs.Bool("f", false, "keep printing lines as they are logged")
+	lines := fs.Int("n", 20, "how many of the last lines to print")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if *lines < 0 {
+		return usageErrorf("-n must not be negative")
+	}
+
+	path, err := s.Config.LogFilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate log file: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) && !*follow {
+		return notFoundErrorf("no log at %s, the daemon has not been started with daemon start", path)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+	if file != nil {
+		if err := printLastLines(file, *lines); err != nil {
+			return fmt.Errorf("failed to read log file: %v", err)
+		}
+	}
+
+	if !*follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if file != nil {
+			if _, err := io.Copy(os.Stdout, file); err != nil {
+				return fmt.Errorf("failed to read log file: %v", err)
+			}
+		}
+
+		// after a rotation the name points at a new file; the rest of
+		// the old one was copied above
+		current, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if file != nil {
+			if opened, err := file.Stat(); err == nil && os.SameFile(opened, current) {
+				continue
+			}
+			file.Close()
+		}
+		if file, err = os.Open(path); err != nil {
+			file = nil
+		}
+	}
+}
+
+// printLastLines prints the last n lines of file and leaves it positioned
+// at its end.
+func printLastLines(file *os.File, n int) error {
+	var last []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		if n == 0 {
+			continue
+		}
+		if len(last) == n {
+			last = last[1:]
+		}
+		last = append(last, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, line := range last {
+		fmt.Println(line)
+	}
+	// -f carries on from the end
+	_, err := file.Seek(0, io.SeekEnd)
+	return err
+}
@@ -1,14 +1,24 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
 	"html"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,9 +27,41 @@ import (
 	"github.com/necodeus/gator/internal/database"
 )
 
+const version = "0.1.0"
+
+const defaultUserAgent = "gator/" + version + " (+https://github.com/necodeus/gator)"
+
 type state struct {
-	db     *database.Queries
+	// conn is the pool db runs on, for migrations and the schema version
+	// check, which go through goose's table rather than the queries.
+	conn   *sql.DB
+	db     Store
 	Config *config.Config
+	hosts  *hostLimiter
+	client *http.Client
+	// fetcher is what feeds are fetched through; client remains for every
+	// other request.
+	fetcher FeedFetcher
+
+	// maxItems caps how many items are decoded from a single feed, and
+	// maxFeedSize how many bytes are read of it; 0 means no limit.
+	maxItems    int
+	maxFeedSize int64
+	// itemsPerFetch caps how many of the newest decoded items agg
+	// stores; 0 means all of them.
+	itemsPerFetch int
+
+	// dbTimeout and fetchTimeout bound single operations; they are
+	// applied on top of the command's context, which Ctrl-C cancels.
+	dbTimeout    time.Duration
+	fetchTimeout time.Duration
+
+	// notifier announces new posts on the configured sinks, plus the
+	// desktop with daemon run --notify; nil stays quiet.
+	notifier *notifier
+	// scheduler is the daemon's scheduler, for serve's health probes; nil
+	// when this process doesn't run one.
+	scheduler *schedulerHealth
 }
 
 type command struct {
@@ -32,46 +74,169 @@ type commands struct {
 	Register func(s *state, cmd command) error
 }
 
+// AtomLink is an <atom:link> in an RSS channel, which is where feeds
+// announce their WebSub hub (rel="hub") and canonical URL (rel="self").
+type AtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		// Declared ahead of Link so <atom:link> elements land here instead
+		// of overwriting the channel's plain <link>.
+		AtomLinks   []AtomLink   `xml:"http://www.w3.org/2005/Atom link"`
+		Title       string       `xml:"title"`
+		Link        string       `xml:"link"`
+		Description string       `xml:"description"`
+		Author      string       `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+		Image       *ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+
+		// publisher hints on how often to poll, see fetchhints.go
+		TTL             string    `xml:"ttl"`
+		SkipHours       []string  `xml:"skipHours>hour"`
+		SkipDays        []string  `xml:"skipDays>day"`
+		UpdatePeriod    string    `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string    `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+		Item            []RSSItem `xml:"item"`
 	} `xml:"channel"`
+
+	// PermanentURL is set when the feed was reached only through permanent
+	// (301/308) redirects and holds the URL it now lives at.
+	PermanentURL string `xml:"-"`
+	// ContentHash is the SHA-256 of the fetched body, hex encoded, for
+	// telling whether the next fetch brings anything new.
+	ContentHash string `xml:"-"`
+	// Size is how many bytes the fetched body had, when it was read whole.
+	Size int64 `xml:"-"`
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	// Declared ahead of Title so <itunes:title> lands here instead of
+	// overwriting the item's plain <title>.
+	ITunesTitle string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+	Title       string           `xml:"title"`
+	Link        string           `xml:"link"`
+	GUID        string           `xml:"guid"`
+	Description string           `xml:"description"`
+	PubDate     string           `xml:"pubDate"`
+	Enclosure   *RSSEnclosure    `xml:"enclosure"`
+	Author      string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	Image       *ITunesImage     `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	Duration    string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	Episode     string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+	Season      string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd season"`
+	Thumbnails  []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	Contents    []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaGroup  *MediaGroup      `xml:"http://search.yahoo.com/mrss/ group"`
+
+	// slash:comments, the comment count blogs such as WordPress publish
+	Comments string `xml:"http://purl.org/rss/1.0/modules/slash/ comments"`
+
+	// Score and CommentCount come from sources that report engagement
+	// outside the XML, such as Reddit's JSON listings.
+	Score        *int32 `xml:"-"`
+	CommentCount *int32 `xml:"-"`
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+// MediaThumbnail, MediaContent and MediaGroup cover the parts of the
+// Media RSS namespace used by photo blogs and YouTube to attach images.
+type MediaThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+type MediaContent struct {
+	URL        string           `xml:"url,attr"`
+	Type       string           `xml:"type,attr"`
+	Medium     string           `xml:"medium,attr"`
+	Thumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type MediaGroup struct {
+	Thumbnails  []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	Contents    []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	Description string           `xml:"http://search.yahoo.com/mrss/ description"`
+}
+
+// ThumbnailURL returns the best image for the item from its Media RSS
+// elements, preferring explicit thumbnails over image content.
+func (item RSSItem) ThumbnailURL() string {
+	thumbnails := item.Thumbnails
+	contents := item.Contents
+	if item.MediaGroup != nil {
+		thumbnails = append(thumbnails, item.MediaGroup.Thumbnails...)
+		contents = append(contents, item.MediaGroup.Contents...)
 	}
-	req.Header.Set("User-Agent", "gator")
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("fetching feed: %w", err)
+	for _, thumbnail := range thumbnails {
+		if thumbnail.URL != "" {
+			return thumbnail.URL
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad response status: %s", resp.Status)
+	for _, content := range contents {
+		for _, thumbnail := range content.Thumbnails {
+			if thumbnail.URL != "" {
+				return thumbnail.URL
+			}
+		}
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	for _, content := range contents {
+		if content.URL != "" && (content.Medium == "image" || strings.HasPrefix(content.Type, "image/")) {
+			return content.URL
+		}
+	}
+
+	return ""
+}
+
+// ITunesImage is the artwork reference from the iTunes podcast namespace,
+// which carries the URL in an href attribute rather than as text.
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// RSSEnclosure is a media file attached to an item, e.g. a podcast episode.
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// userAgent returns the User-Agent to send for feed: its own override if
+// set, otherwise the configured one, otherwise gator's default. A nil feed
+// gets the global value.
+func (s *state) userAgent(feed *database.Feed) string {
+	if feed != nil && feed.UserAgent.Valid && feed.UserAgent.String != "" {
+		return feed.UserAgent.String
+	}
+	if s.Config.UserAgent != "" {
+		return s.Config.UserAgent
+	}
+	return defaultUserAgent
+}
+
+// parseFeed decodes a feed as it streams in rather than buffering the
+// whole document. Only the first maxItems items are kept (0 means no
+// limit); the rest are skipped without being decoded.
+func parseFeed(r io.Reader, maxItems int) (*RSSFeed, error) {
+	tokens := &itemCapReader{decoder: xml.NewDecoder(r), maxItems: maxItems}
+	decoder := xml.NewTokenDecoder(tokens)
+
+	root, err := rootElement(decoder)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+		return nil, fmt.Errorf("unmarshalling XML: %w", err)
 	}
 
 	var feed RSSFeed
-	if err := xml.Unmarshal(data, &feed); err != nil {
+	if root.Name.Space == atomNamespace && root.Name.Local == "feed" {
+		var atom atomFeed
+		if err := decoder.DecodeElement(&atom, &root); err != nil {
+			return nil, fmt.Errorf("unmarshalling XML: %w", err)
+		}
+		feed = atom.toRSS()
+	} else if err := decoder.DecodeElement(&feed, &root); err != nil {
 		return nil, fmt.Errorf("unmarshalling XML: %w", err)
 	}
 
@@ -86,13 +251,68 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 	return &feed, nil
 }
 
-func handlerLogin(s *state, cmd command) error {
+// itemCapReader passes raw XML tokens through, dropping every <item> or
+// <entry> after the first maxItems so oversized feeds never reach the struct
+// decoder.
+type itemCapReader struct {
+	decoder  *xml.Decoder
+	maxItems int
+	items    int
+}
+
+func (r *itemCapReader) Token() (xml.Token, error) {
+	tok, err := r.decoder.RawToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// RSS items and Atom entries, both normally unprefixed
+	start, ok := tok.(xml.StartElement)
+	if !ok || (start.Name.Local != "item" && start.Name.Local != "entry") || start.Name.Space != "" || r.maxItems <= 0 {
+		return tok, nil
+	}
+
+	r.items++
+	if r.items <= r.maxItems {
+		return tok, nil
+	}
+
+	// skip to the matching end of this item, then carry on after it
+	for depth := 1; depth > 0; {
+		tok, err := r.decoder.RawToken()
+		if err != nil {
+			return nil, err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	return r.Token()
+}
+
+// currentUser loads the user named in the config, i.e. whoever last ran
+// login or register.
+func currentUser(ctx context.Context, s *state) (database.User, error) {
+	users, err := s.db.GetUsersByName(ctx, s.Config.CurrentUserName)
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to get user: %v", err)
+	}
+	if len(users) == 0 {
+		return database.User{}, notFoundErrorf("user %s does not exist, register or login first", s.Config.CurrentUserName)
+	}
+	return users[0], nil
+}
+
+func handlerLogin(ctx context.Context, s *state, cmd command) error {
 	if len(cmd.Args) == 0 {
-		return fmt.Errorf("login command requires a username")
+		return usageErrorf("login command requires a username")
 	}
 
 	// check if user is in the database
-	ctx := context.Background()
 	users, err := s.db.GetUsersByName(ctx, cmd.Args[0])
 	if err != nil {
 		if err != sql.ErrNoRows {
@@ -100,10 +320,10 @@ func handlerLogin(s *state, cmd command) error {
 		}
 	}
 	if len(users) == 0 {
-		return fmt.Errorf("user %s does not exist", cmd.Args[0])
+		return notFoundErrorf("user %s does not exist", cmd.Args[0])
 	}
 
-	fmt.Println("Logging in user...", users[0].Name)
+	infof("Logging in user... %s\n", users[0].Name)
 	s.Config.CurrentUserName = users[0].Name
 
 	// nadpisz ustawienie
@@ -114,42 +334,104 @@ func handlerLogin(s *state, cmd command) error {
 	return nil
 }
 
-func handlerRegister(s *state, cmd command) error {
+// usernameMaxLen keeps usernames short enough for listings and prompts.
+const usernameMaxLen = 32
+
+// reservedUsernames read as roles or as gator itself rather than as
+// someone, so they can't be registered.
+var reservedUsernames = []string{"admin", "administrator", "root", "system", "gator", "anonymous", "nobody"}
+
+// validateUsername checks a name for register: up to usernameMaxLen
+// letters, digits, '-', '_' and '.', starting with a letter or digit, and
+// not reserved in any case. Uniqueness ignores case as well, which the
+// database enforces.
+func validateUsername(name string) error {
+	if name == "" {
+		return usageErrorf("username must not be empty")
+	}
+	if len(name) > usernameMaxLen {
+		return usageErrorf("username %s is longer than %d characters", name, usernameMaxLen)
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return usageErrorf("username %s may only contain letters a-z, digits, '-', '_' and '.'", name)
+		}
+	}
+	if strings.ContainsRune("-_.", rune(name[0])) {
+		return usageErrorf("username %s must start with a letter or digit", name)
+	}
+	if slices.Contains(reservedUsernames, strings.ToLower(name)) {
+		return usageErrorf("username %s is reserved", name)
+	}
+	return nil
+}
+
+func handlerRegister(ctx context.Context, s *state, cmd command) error {
 	if len(cmd.Args) == 0 {
-		return fmt.Errorf("register command requires a username")
+		return usageErrorf("register command requires a username")
 	}
 
 	userToRegister := cmd.Args[0]
+	if err := validateUsername(userToRegister); err != nil {
+		return err
+	}
 
-	fmt.Println("Registering user...")
+	infof("Registering user...\n")
 
-	fmt.Printf("User to register: %s\n", userToRegister)
-	users, err := s.db.GetUsersByName(context.Background(), userToRegister)
-	if err != nil {
-		if err != sql.ErrNoRows {
-			return fmt.Errorf("failed to get user: %v", err)
+	infof("User to register: %s\n", userToRegister)
+
+	// the lookup and insert share a transaction, and the unique name
+	// constraint catches a concurrent register of the same user
+	var user database.User
+	err := s.withTx(ctx, func(tx *state) error {
+		users, err := tx.db.GetUsersByName(ctx, userToRegister)
+		if err != nil {
+			if err != sql.ErrNoRows {
+				return fmt.Errorf("failed to get user: %v", err)
+			}
 		}
-	}
 
-	if len(users) > 0 {
-		return fmt.Errorf("user %s already exists", userToRegister)
-	}
+		if len(users) > 0 {
+			return conflictErrorf("user %s already exists", users[0].Name)
+		}
 
-	ctx := context.Background()
+		data := database.CreateUserParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      userToRegister,
+		}
 
-	data := database.CreateUserParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name:      userToRegister,
-	}
+		user, err = tx.db.CreateUser(ctx, data)
+		if err != nil {
+			if uniqueViolation(err) != "" {
+				return conflictErrorf("user %s already exists", userToRegister)
+			}
+			return fmt.Errorf("failed to create user: %v", err)
+		}
 
-	user, err := s.db.CreateUser(ctx, data)
+		if _, err := tx.db.FollowSharedFeeds(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to follow shared feeds: %v", err)
+		}
+
+		// the first user administers the install
+		admins, err := tx.db.CountAdmins(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to count admins: %v", err)
+		}
+		if admins == 0 {
+			if err := tx.db.SetUserAdmin(ctx, database.SetUserAdminParams{ID: user.ID, IsAdmin: true}); err != nil {
+				return fmt.Errorf("failed to make user an admin: %v", err)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create user: %v", err)
+		return err
 	}
 
-	fmt.Println("Logging in user...", user.Name)
+	infof("Logging in user... %s\n", user.Name)
 	s.Config.CurrentUserName = user.Name
 
 	// nadpisz ustawienie
@@ -160,22 +442,24 @@ func handlerRegister(s *state, cmd command) error {
 	return nil
 }
 
-func handlerReset(s *state, cmd command) error {
-	fmt.Println("Resetting database...")
+func handlerReset(ctx context.Context, s *state, cmd command) error {
+	if err := requireAdmin(ctx, s); err != nil {
+		return err
+	}
+
+	infof("Resetting database...\n")
 
 	// Delete all users
-	ctx := context.Background()
 	if err := s.db.DeleteUsers(ctx); err != nil {
 		return fmt.Errorf("failed to delete users: %v", err)
 	}
 
-	fmt.Println("Database reset successfully.")
+	infof("Database reset successfully.\n")
 
 	return nil
 }
 
-func handlerUsers(s *state, cmd command) error {
-	ctx := context.Background()
+func handlerUsers(ctx context.Context, s *state, cmd command) error {
 	users, err := s.db.GetUsers(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get users: %v", err)
@@ -194,143 +478,569 @@ func handlerUsers(s *state, cmd command) error {
 	return nil
 }
 
-func handlerAgg(s *state, cmd command) error {
-	feed, err := fetchFeed(context.Background(), "https://www.wagslane.dev/index.xml")
-	if err != nil {
-		return fmt.Errorf("failed to fetch feed: %v", err)
+var pubDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+}
+
+// parsePubDate reads an item's publication date and normalizes it to UTC,
+// whatever offset the feed used.
+func parsePubDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range pubDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
 	}
+	return time.Time{}, fmt.Errorf("unrecognized date format: %q", value)
+}
 
-	for _, item := range feed.Channel.Item {
-		fmt.Printf("- %s\n", item.Title)
+// parseDuration converts an itunes:duration value, which may be plain
+// seconds, MM:SS or HH:MM:SS, into a number of seconds.
+func parseDuration(value string) (int32, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("empty duration")
 	}
 
-	return nil
-}
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("unrecognized duration format: %q", value)
+	}
 
-func handlerAddFeed(s *state, cmd command) error {
-	// 2 args
-	if len(cmd.Args) < 2 {
-		return fmt.Errorf("addfeed command requires a feed URL and a user ID")
+	var seconds int64
+	for _, part := range parts {
+		n, err := strconv.ParseInt(part, 10, 32)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("unrecognized duration format: %q", value)
+		}
+		seconds = seconds*60 + n
 	}
 
-	ctx := context.Background()
+	return int32(seconds), nil
+}
 
-	// rss, err := fetchFeed(ctx, cmd.Args[1])
-	// if err != nil {
-	// 	return fmt.Errorf("failed to fetch feed: %v", err)
-	// }
+func handlerAddFeed(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("addfeed", flag.ContinueOnError)
+	nameFlag := fs.String("name", "", "name to store the feed under (defaults to its title)")
+	var scrape scraperConfig
+	fs.StringVar(&scrape.Item, "scrape-item", "", "scrape an HTML page instead: CSS selector matching one element per post")
+	fs.StringVar(&scrape.Title, "scrape-title", "", "selector for a post's title within its item (default \""+defaultScrapeTitle+"\")")
+	fs.StringVar(&scrape.Link, "scrape-link", "", "selector@attr for a post's link within its item (default \""+defaultScrapeLink+"\")")
+	fs.StringVar(&scrape.Date, "scrape-date", "", "selector for a post's date within its item, e.g. time@datetime")
+	fs.StringVar(&scrape.Description, "scrape-description", "", "selector for a post's summary within its item")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
 
-	feeds, err := s.db.GetFeedsByName(ctx, cmd.Args[0])
-	if err != nil {
-		if err != sql.ErrNoRows {
-			return fmt.Errorf("failed to get feed: %v", err)
+	var sc *scraper
+	if scrape.Item != "" {
+		var err error
+		if sc, err = newScraper(scrape); err != nil {
+			return err
 		}
+	} else if scrape != (scraperConfig{}) {
+		return usageErrorf("the --scrape-* flags need --scrape-item")
 	}
 
-	if len(feeds) > 0 {
-		return fmt.Errorf("feed %s already exists", cmd.Args[0])
+	// the original form was addfeed <name> <url>
+	args := fs.Args()
+	name := strings.TrimSpace(*nameFlag)
+	switch {
+	case len(args) == 2 && name == "":
+		name, args = args[0], args[1:]
+	case len(args) != 1:
+		return usageErrorf("addfeed command requires a feed URL and optionally --name")
 	}
 
-	currentUser := s.Config.CurrentUserName
-	users, err := s.db.GetUsersByName(ctx, currentUser)
+	feedURL := args[0]
+	if redditURL, ok := redditFeedURL(feedURL); ok {
+		feedURL = redditURL
+	} else if youtubeURL, ok, err := youtubeFeedURL(ctx, s, feedURL); err != nil {
+		return err
+	} else if ok {
+		infof("Using the feed for %s: %s\n", feedURL, youtubeURL)
+		feedURL = youtubeURL
+	}
+
+	feedURL, err := normalizeFeedURL(feedURL)
 	if err != nil {
-		if err != sql.ErrNoRows {
-			return fmt.Errorf("failed to get user: %v", err)
-		}
+		return err
 	}
 
-	_, err = s.db.CreateFeed(ctx, database.CreateFeedParams{
-		ID:     uuid.New(),
-		UserID: users[0].ID,
-		Name:   cmd.Args[0],
-		Url:    cmd.Args[1],
-	})
+	// make sure there is a feed there before storing anything
+	fetchCtx, cancel := context.WithTimeout(ctx, s.fetchTimeout)
+	defer cancel()
+
+	reqOpts := requestOptions{userAgent: s.userAgent(nil)}
+	var rss *RSSFeed
+	switch {
+	case sc != nil:
+		rss, err = fetchScrapedFeed(fetchCtx, s.fetcher, feedURL, reqOpts, sc, s.maxItems)
+	case redditListingURL(feedURL) != "":
+		rss, err = fetchRedditFeed(fetchCtx, s, feedURL, reqOpts, s.maxItems)
+	default:
+		rss, err = fetchFeed(fetchCtx, s.fetcher, feedURL, reqOpts, s.maxItems)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create feed: %v", err)
+		return networkError(err, fmt.Errorf("%s is not a readable feed: %v", feedURL, err))
+	}
+	if rss.PermanentURL != "" {
+		infof("%s moved permanently, adding %s instead\n", feedURL, rss.PermanentURL)
+		feedURL = rss.PermanentURL
 	}
 
-	return nil
+	ctx, cancel = context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	// checks and inserts share a transaction; the unique name and url
+	// constraints catch anything added concurrently
+	return s.withTx(ctx, func(tx *state) error {
+		existing, err := findFeedByURL(ctx, tx, feedURL)
+		if err == nil {
+			return conflictErrorf("feed %s already exists as %s, follow it instead: gator follow %s", feedURL, existing.Name, existing.Url)
+		}
+		if err != sql.ErrNoRows {
+			return fmt.Errorf("failed to get feed: %v", err)
+		}
+
+		if name == "" {
+			// a title shared with another feed gets its host added
+			name, err = unusedFeedName(ctx, tx, rss.Channel.Title, feedURL)
+			if err != nil {
+				return err
+			}
+		} else {
+			feeds, err := tx.db.GetFeedsByName(ctx, name)
+			if err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("failed to get feed: %v", err)
+			}
+			if len(feeds) > 0 {
+				return conflictErrorf("feed %s already exists", name)
+			}
+		}
+
+		user, err := currentUser(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		feed, err := tx.db.CreateFeed(ctx, database.CreateFeedParams{
+			ID:     uuid.New(),
+			UserID: user.ID,
+			Name:   name,
+			Url:    feedURL,
+		})
+		if err != nil {
+			switch uniqueViolation(err) {
+			case "feeds_name_key":
+				return conflictErrorf("feed %s already exists", name)
+			case "feeds_url_key":
+				return conflictErrorf("feed %s already exists, follow it instead: gator follow %s", feedURL, feedURL)
+			}
+			return fmt.Errorf("failed to create feed: %v", err)
+		}
+
+		if sc != nil {
+			config, err := json.Marshal(scrape)
+			if err != nil {
+				return err
+			}
+			if err := tx.db.SetFeedScraper(ctx, database.SetFeedScraperParams{
+				ID:      feed.ID,
+				Scraper: sql.NullString{String: string(config), Valid: true},
+			}); err != nil {
+				return fmt.Errorf("failed to save scraper: %v", err)
+			}
+		}
+
+		// whoever adds a feed is following it
+		if _, err := tx.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:     uuid.New(),
+			UserID: user.ID,
+			FeedID: feed.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to follow feed: %v", err)
+		}
+
+		infof("Added %s (%s) with %d items\n", feed.Name, feed.Url, len(rss.Channel.Item))
+
+		return nil
+	})
 }
 
-func handlerFeeds(s *state, cmd command) error {
-	fmt.Println("Listing feeds...")
+// feedSorts are the orders feeds --sort accepts; added is the default.
+// Names sort A to Z and followers most followed first.
+var feedSorts = []string{"added", "name", "followers"}
 
-	ctx := context.Background()
-	feeds, err := s.db.GetFeeds(ctx)
+func handlerFeeds(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("feeds", flag.ContinueOnError)
+	table := addTableFlags(fs)
+	sortBy := fs.String("sort", "added", "order feeds by "+strings.Join(feedSorts, ", "))
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if !slices.Contains(feedSorts, *sortBy) {
+		return usageErrorf("unknown sort %s, expected one of %s", *sortBy, strings.Join(feedSorts, ", "))
+	}
+	tabular, err := table.tabular()
+	if err != nil {
+		return err
+	}
+	columns, err := selectColumns(table, feedColumns, []string{"name", "url", "user"})
+	if err != nil {
+		return err
+	}
+
+	if !tabular {
+		infof("Listing feeds...\n")
+	}
+
+	feeds, err := s.db.GetFeedsWithFollowers(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get feeds: %v", err)
 	}
+
+	rows := make([]feedRow, 0, len(feeds))
 	for _, feed := range feeds {
+		row := feedRow{Feed: feed.Feed, UserName: "Unknown", Followers: feed.Followers}
+
 		// get user by ID
-		user, err := s.db.GetUserById(ctx, feed.UserID)
+		user, err := s.db.GetUserById(ctx, feed.Feed.UserID)
 		if err != nil {
 			if err != sql.ErrNoRows {
 				return fmt.Errorf("failed to get user: %v", err)
 			}
-			fmt.Printf("- Name: %s Url: %s User: Unknown\n", feed.Name, feed.Url)
-			continue
+		} else {
+			row.UserName = user.Name
 		}
+		rows = append(rows, row)
+	}
 
-		fmt.Printf("- Name: %s Url: %s User: %s\n", feed.Name, feed.Url, user.Name)
+	switch *sortBy {
+	case "name":
+		slices.SortStableFunc(rows, func(a, b feedRow) int {
+			return strings.Compare(strings.ToLower(a.Feed.Name), strings.ToLower(b.Feed.Name))
+		})
+	case "followers":
+		slices.SortStableFunc(rows, func(a, b feedRow) int {
+			return cmp.Compare(b.Followers, a.Followers)
+		})
+	}
+
+	if tabular {
+		return writeTable(table, columns, rows)
+	}
+	for _, row := range rows {
+		notes := ""
+		if row.Feed.Tier != tierNormal {
+			notes += fmt.Sprintf(" (%s tier)", row.Feed.Tier)
+		}
+		if row.Feed.PausedAt.Valid {
+			notes += " (paused)"
+		}
+		if row.Feed.Shared {
+			notes += " (shared)"
+		}
+		fmt.Printf("- Name: %s Url: %s User: %s Followers: %d%s\n", row.Feed.Name, row.Feed.Url, row.UserName, row.Followers, notes)
 	}
 
 	return nil
 }
 
-func (c *commands) run(s *state, cmd command) error {
+// feedRow is a feed as the feeds command lists it.
+type feedRow struct {
+	Feed      database.Feed
+	UserName  string
+	Followers int64
+}
+
+var feedColumns = []tableColumn[feedRow]{
+	{"id", func(r feedRow) string { return r.Feed.ID.String() }},
+	{"name", func(r feedRow) string { return r.Feed.Name }},
+	{"url", func(r feedRow) string { return r.Feed.Url }},
+	{"user", func(r feedRow) string { return r.UserName }},
+	{"created_at", func(r feedRow) string { return tableTime(r.Feed.CreatedAt, true) }},
+	{"last_fetched_at", func(r feedRow) string { return tableTime(r.Feed.LastFetchedAt.Time, r.Feed.LastFetchedAt.Valid) }},
+	{"priority", func(r feedRow) string { return strconv.Itoa(int(r.Feed.Priority)) }},
+	{"tier", func(r feedRow) string { return r.Feed.Tier }},
+	{"paused_at", func(r feedRow) string { return tableTime(r.Feed.PausedAt.Time, r.Feed.PausedAt.Valid) }},
+	{"shared", func(r feedRow) string { return strconv.FormatBool(r.Feed.Shared) }},
+	{"followers", func(r feedRow) string { return strconv.FormatInt(r.Followers, 10) }},
+}
+
+// longRunning lists commands that do many operations over an open-ended
+// time and bound each one themselves instead of running under dbTimeout.
+var longRunning = map[string]bool{
+	"agg":         true,
+	"serve":       true,
+	"download":    true,
+	"daemon":      true,
+	"healthcheck": true,
+	"import":      true,
+	"archive":     true,
+	"preview":     true,
+	"addfeed":     true,
+	"follow":      true,
+	"unfollow":    true,
+	"feed":        true,
+	"newsletters": true,
+	"save":        true,
+	"export":      true,
+	"logs":        true,
+	"history":     true,
+	"alias":       true,
+	"search":      true,
+	"read":        true,
+	"discover":    true,
+	"open":        true,
+	"refresh":     true,
+}
+
+func (c *commands) run(ctx context.Context, s *state, cmd command) error {
+	if !longRunning[cmd.Name] {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.dbTimeout)
+		defer cancel()
+	}
+
+	var handler func(ctx context.Context, s *state, cmd command) error
 	switch cmd.Name {
 	case "login":
-		return handlerLogin(s, cmd)
+		handler = handlerLogin
 	case "register":
-		return handlerRegister(s, cmd)
+		handler = handlerRegister
 	case "reset":
-		return handlerReset(s, cmd)
+		handler = handlerReset
 	case "users":
-		return handlerUsers(s, cmd)
+		handler = handlerUsers
 	case "agg":
-		return handlerAgg(s, cmd)
+		handler = handlerAgg
 	case "addfeed":
-		return handlerAddFeed(s, cmd)
+		handler = handlerAddFeed
 	case "feeds":
-		return handlerFeeds(s, cmd)
+		handler = handlerFeeds
+	case "follow":
+		handler = handlerFollow
+	case "discover":
+		handler = handlerDiscover
+	case "following":
+		handler = handlerFollowing
+	case "unfollow":
+		handler = handlerUnfollow
+	case "stats":
+		handler = handlerStats
+	case "serve":
+		handler = handlerServe
+	case "feed":
+		handler = handlerFeed
+	case "timezone":
+		handler = handlerTimezone
+	case "browse":
+		handler = handlerBrowse
+	case "export":
+		handler = handlerExport
+	case "download":
+		handler = handlerDownload
+	case "daemon":
+		handler = handlerDaemon
+	case "healthcheck":
+		handler = handlerHealthcheck
+	case "import":
+		handler = handlerImport
+	case "whatsnew":
+		handler = handlerWhatsNew
+	case "read":
+		handler = handlerRead
+	case "open":
+		handler = handlerOpen
+	case "archive":
+		handler = handlerArchive
+	case "related":
+		handler = handlerRelated
+	case "search":
+		handler = handlerSearch
+	case "preview":
+		handler = handlerPreview
+	case "newsletters":
+		handler = handlerNewsletters
+	case "keywords":
+		handler = handlerKeywords
+	case "digest":
+		handler = handlerDigest
+	case "star":
+		handler = handlerStar
+	case "unstar":
+		handler = handlerUnstar
+	case "starred":
+		handler = handlerStarred
+	case "note":
+		handler = handlerNote
+	case "trending":
+		handler = handlerTrending
+	case "save":
+		handler = handlerSave
+	case "passwd":
+		handler = handlerPasswd
+	case "admin":
+		handler = handlerAdmin
+	case "prefs":
+		handler = handlerPrefs
+	case "dbpassword":
+		handler = handlerDBPassword
+	case "logs":
+		handler = handlerLogs
+	case "history":
+		handler = handlerHistory
+	case "alias":
+		handler = handlerAlias
+	case "folder":
+		handler = handlerFolder
+	case "refresh":
+		handler = handlerRefresh
+	case "man":
+		handler = handlerMan
 	default:
-		return fmt.Errorf("unknown command: %s", cmd.Name)
+		return usageErrorf("unknown command: %s", cmd.Name)
 	}
+
+	// a bad db_url fails here rather than inside the first query
+	debugf("running %s with db_timeout %s and fetch_timeout %s\n", cmd.Name, s.dbTimeout, s.fetchTimeout)
+	if needsDatabase(cmd) {
+		start := time.Now()
+		if err := pingDatabase(ctx, s); err != nil {
+			return &exitCodeError{exitDatabase, err}
+		}
+		debugf("database answered in %s\n", time.Since(start).Round(time.Millisecond))
+	}
+
+	return handler(ctx, s, cmd)
+}
+
+// needsDatabase reports whether cmd uses the database at all, so commands
+// that don't can run while it is unreachable. healthcheck reports on the
+// database itself.
+func needsDatabase(cmd command) bool {
+	switch cmd.Name {
+	case "preview", "dbpassword", "healthcheck", "logs", "man":
+		return false
+	case "daemon":
+		return len(cmd.Args) == 0 || (cmd.Args[0] != "stop" && cmd.Args[0] != "reload" && cmd.Args[0] != "status")
+	}
+	return true
+}
+
+// pingDatabase checks that the database in db_url can be reached and
+// logged in to.
+func pingDatabase(ctx context.Context, s *state) error {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	if err := s.db.Ping(ctx); err != nil {
+		where := "the database in db_url"
+		if u, perr := url.Parse(s.Config.DbUrl); perr == nil && u.Host != "" {
+			where = u.Redacted()
+		}
+		return fmt.Errorf("cannot connect to %s: %v (check db_url in ~/.gatorconfig.json)", where, err)
+	}
+	return nil
 }
 
 func main() {
+	globals, args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		exitWith(err)
+	}
+	setLogLevel(globals.level)
+	levelFromFlags = globals.levelSet
+
 	// Load configuration
 
 	cfg := config.Config{}
 	config, err := cfg.Read()
 	if err != nil {
-		panic(err)
+		exitWith(&exitCodeError{exitConfig, fmt.Errorf("failed to read config: %v", err)})
 	}
 	cfg = config
 
+	if !levelFromFlags {
+		level, err := parseVerbosity(cfg.LogLevel)
+		if err != nil {
+			exitWith(&exitCodeError{exitConfig, fmt.Errorf("failed to read config: %v", err)})
+		}
+		setLogLevel(level)
+	}
+
 	// fmt.Printf("Config loaded: %+v\n", config)
 
 	// Initialize database connection
 
-	db, err := sql.Open("postgres", cfg.DbUrl)
+	// the password may come from the keyring, asked for on first use
+	db := sql.OpenDB(newCredentialConnector(&config))
+
+	connMaxLifetime, err := cfg.DBConnMaxLifetimeDuration()
+	if err != nil {
+		exitWith(&exitCodeError{exitConfig, fmt.Errorf("failed to read config: %v", err)})
+	}
+	db.SetMaxOpenConns(cfg.DBMaxOpenConnsOrDefault())
+	db.SetMaxIdleConns(cfg.DBMaxIdleConnsOrDefault())
+	db.SetConnMaxLifetime(connMaxLifetime)
+
+	hostDelay, err := cfg.HostDelayDuration()
+	if err != nil {
+		exitWith(&exitCodeError{exitConfig, fmt.Errorf("failed to read config: %v", err)})
+	}
+
+	dbTimeout, err := cfg.DBTimeoutDuration()
+	if err != nil {
+		exitWith(&exitCodeError{exitConfig, fmt.Errorf("failed to read config: %v", err)})
+	}
+
+	fetchTimeout, err := cfg.FetchTimeoutDuration()
+	if err != nil {
+		exitWith(&exitCodeError{exitConfig, fmt.Errorf("failed to read config: %v", err)})
+	}
+
+	httpClient, err := newHTTPClient(&cfg)
 	if err != nil {
-		fmt.Printf("Error opening database: %v\n", err)
-		os.Exit(1)
+		exitWith(&exitCodeError{exitConfig, fmt.Errorf("failed to read config: %v", err)})
 	}
+	httpClient.Transport = &loggingTransport{next: httpClient.Transport}
 
 	// State initialization
 
 	s := &state{
 		Config: &config,
-		db:     database.New(db),
+		conn:   db,
+		db:     newPGStore(db),
+		hosts:  newHostLimiter(hostDelay),
+		client: httpClient,
+
+		fetcher: httpFetcher{client: httpClient},
+
+		maxItems:      cfg.MaxFeedItemsOrDefault(),
+		maxFeedSize:   cfg.MaxFeedSizeOrDefault(),
+		itemsPerFetch: cfg.ItemsPerFetchOrDefault(),
+
+		dbTimeout:    dbTimeout,
+		fetchTimeout: fetchTimeout,
+	}
+	s.notifier = newConfiguredNotifier(s)
+
+	shutdownTracing, err := setupTracing(&cfg)
+	if err != nil {
+		exitWith(&exitCodeError{exitConfig, err})
 	}
 
 	// Process command line arguments
 
-	args := os.Args[1:]
 	if len(args) < 1 {
-		fmt.Println("Usage: gator <command> [args]")
-		os.Exit(1)
+		exitWith(usageErrorf("usage: gator [-q | -v | -vv] [--auto-migrate] <command> [args]"))
 	}
 
 	cmd := command{
@@ -338,10 +1048,32 @@ func main() {
 		Args: args[1:],
 	}
 
+	// Ctrl-C cancels whatever database or network call is in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	c := &commands{}
-	err = c.run(s, cmd)
+	if (globals.autoMigrate || cfg.AutoMigrate) && needsDatabase(cmd) {
+		err = migrateDatabase(ctx, s)
+	}
+	if err == nil {
+		err = c.run(ctx, s, cmd)
+	}
+
+	// exitWith skips deferred calls, so spans are flushed here
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if serr := shutdownTracing(flushCtx); serr != nil {
+		debugf("failed to flush traces: %v\n", serr)
+	}
+	cancel()
+
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		// database errors reach here as text, so a failure nothing else
+		// explains is put down to the database when it has gone away
+		var exitErr *exitCodeError
+		if !errors.As(err, &exitErr) && ctx.Err() == nil && needsDatabase(cmd) && pingDatabase(ctx, s) != nil {
+			err = &exitCodeError{exitDatabase, err}
+		}
+		exitWith(err)
 	}
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/config"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// exitCode returns the code main would exit with for err.
+func exitCode(err error) int {
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
+
+func TestHandlerRegister(t *testing.T) {
+	shared := database.Feed{ID: uuid.New(), Name: "Team news", Url: "https://example.com/team.xml", Shared: true}
+	db := &fakeStore{feeds: []database.Feed{shared}}
+	s := testState(t, db)
+	ctx := context.Background()
+
+	if err := handlerRegister(ctx, s, command{Name: "register", Args: []string{"ann"}}); err != nil {
+		t.Fatalf("register ann: %v", err)
+	}
+	if err := handlerRegister(ctx, s, command{Name: "register", Args: []string{"bob"}}); err != nil {
+		t.Fatalf("register bob: %v", err)
+	}
+
+	if len(db.users) != 2 {
+		t.Fatalf("stored %d users, want 2", len(db.users))
+	}
+	if !db.users[0].IsAdmin || db.users[1].IsAdmin {
+		t.Errorf("admins are ann %v, bob %v; want only the first user", db.users[0].IsAdmin, db.users[1].IsAdmin)
+	}
+	if len(db.follows) != 2 || db.follows[0].FeedID != shared.ID || db.follows[1].FeedID != shared.ID {
+		t.Errorf("follows are %+v, want both users following the shared feed", db.follows)
+	}
+	if db.txs != 2 {
+		t.Errorf("ran %d transactions, want one per register", db.txs)
+	}
+
+	cfg, err := (&config.Config{}).Read()
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+	if cfg.CurrentUserName != "bob" {
+		t.Errorf("logged in as %q, want bob", cfg.CurrentUserName)
+	}
+
+	err = handlerRegister(ctx, s, command{Name: "register", Args: []string{"ann"}})
+	if exitCode(err) != exitConflict {
+		t.Errorf("registering ann again: got %v, want a conflict", err)
+	}
+	err = handlerRegister(ctx, s, command{Name: "register", Args: []string{"admin"}})
+	if exitCode(err) != exitUsage {
+		t.Errorf("registering admin: got %v, want a usage error", err)
+	}
+	if len(db.users) != 2 {
+		t.Errorf("stored %d users after the refused registers, want 2", len(db.users))
+	}
+}
+
+func TestHandlerAddFeed(t *testing.T) {
+	const feedURL = "https://example.com/feed.xml"
+	ann := database.User{ID: uuid.New(), Name: "ann"}
+	db := &fakeStore{users: []database.User{ann}}
+	fetcher := &fakeFetcher{bodies: map[string]string{feedURL: testFeed}}
+	s := testState(t, db)
+	s.fetcher = fetcher
+	ctx := context.Background()
+
+	if err := handlerAddFeed(ctx, s, command{Name: "addfeed", Args: []string{feedURL}}); err != nil {
+		t.Fatalf("addfeed: %v", err)
+	}
+
+	if len(db.feeds) != 1 {
+		t.Fatalf("stored %d feeds, want 1", len(db.feeds))
+	}
+	feed := db.feeds[0]
+	if feed.Name != "Example Blog" || feed.Url != feedURL || feed.UserID != ann.ID {
+		t.Errorf("stored %+v, want Example Blog at %s added by ann", feed, feedURL)
+	}
+	if len(db.follows) != 1 || db.follows[0].UserID != ann.ID || db.follows[0].FeedID != feed.ID {
+		t.Errorf("follows are %+v, want ann following the new feed", db.follows)
+	}
+
+	err := handlerAddFeed(ctx, s, command{Name: "addfeed", Args: []string{feedURL}})
+	if exitCode(err) != exitConflict {
+		t.Errorf("adding the feed again: got %v, want a conflict", err)
+	}
+
+	// nothing is stored for a URL that isn't a feed
+	err = handlerAddFeed(ctx, s, command{Name: "addfeed", Args: []string{"https://example.com/missing.xml"}})
+	if err == nil {
+		t.Error("adding a missing feed succeeded")
+	}
+	if len(db.feeds) != 1 {
+		t.Errorf("stored %d feeds, want 1", len(db.feeds))
+	}
+	if len(fetcher.fetched) != 3 {
+		t.Errorf("fetched %v, want one fetch per addfeed", fetcher.fetched)
+	}
+}
@@ -0,0 +1,560 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// commandDoc describes a command for its man page. The dispatch in
+// commands.run is what actually knows the commands; every one of them
+// needs an entry here too.
+type commandDoc struct {
+	name string
+	// synopsis is what follows the command name on the command line
+	synopsis    string
+	summary     string
+	description string
+	options     []optionDoc
+}
+
+type optionDoc struct {
+	flag string
+	text string
+}
+
+var tableOptions = []optionDoc{
+	{"--format text|csv|tsv", "print a table instead of text"},
+	{"--columns list", "comma-separated columns for csv and tsv output"},
+}
+
+var commandDocs = []commandDoc{
+	{
+		name:        "register",
+		synopsis:    "name",
+		summary:     "add a user and log in as them",
+		description: "Adds a user to the database and makes them the current user. Names are up to 32 letters, digits, '-', '_' and '.', start with a letter or digit, and are unique ignoring case; admin, root, gator and a few other names are reserved.",
+	},
+	{
+		name:        "login",
+		synopsis:    "name",
+		summary:     "switch to another user",
+		description: "Makes an existing user the current user, whose follows, reads and preferences every other command works with.",
+	},
+	{
+		name:        "users",
+		summary:     "list users",
+		description: "Lists every user, marking the current one.",
+	},
+	{
+		name:        "reset",
+		summary:     "delete every user and everything they own",
+		description: "Empties the database. Only admins may reset it once there are any.",
+	},
+	{
+		name:        "passwd",
+		summary:     "set the current user's password",
+		description: "Sets the password the current user logs in to serve with. It is read from the terminal, twice, or once from standard input.",
+	},
+	{
+		name:        "admin",
+		synopsis:    "users | grant name | revoke name | merge-user from to",
+		summary:     "manage users and admins",
+		description: "Lists users with their roles, or makes a user an admin or takes that away. merge-user moves one user's feeds, follows, reads, stars, notes, tags, aliases, saved searches and folders to another, keeping the other user's wherever both have one, then removes the first user. Until some user is an admin, anyone may act as one.",
+	},
+	{
+		name:        "addfeed",
+		synopsis:    "[options] url",
+		summary:     "add a feed and follow it",
+		description: "Fetches a feed, stores it under its title and makes the current user follow it. A plain web page can be added as a feed by giving CSS selectors for its posts.",
+		options: []optionDoc{
+			{"--name name", "name to store the feed under instead of its title"},
+			{"--scrape-item selector", "scrape an HTML page: selector matching one element per post"},
+			{"--scrape-title selector", "selector for a post's title within its item"},
+			{"--scrape-link selector@attr", "selector for a post's link within its item"},
+			{"--scrape-date selector", "selector for a post's date within its item, e.g. time@datetime"},
+			{"--scrape-description selector", "selector for a post's summary within its item"},
+		},
+	},
+	{
+		name:        "preview",
+		synopsis:    "[--items n] url",
+		summary:     "describe a feed without storing it",
+		description: "Fetches and parses a feed and shows its title and latest items, so it can be checked before addfeed or follow.",
+		options: []optionDoc{
+			{"--items n", "how many of the latest items to show"},
+		},
+	},
+	{
+		name:        "feeds",
+		synopsis:    "[--sort added|name|followers] [--format text|csv|tsv] [--columns list]",
+		summary:     "list every feed",
+		description: "Lists every stored feed with the user who added it and how many users follow it, marking paused feeds and those outside the normal tier. On an instance with many users, --sort followers shows what's worth subscribing to.",
+		options: append([]optionDoc{
+			{"--sort added|name|followers", "order feeds by when they were added (the default), name, or most followers first"},
+		}, tableOptions...),
+	},
+	{
+		name:        "feed",
+		synopsis:    "subcommand feed [value]",
+		summary:     "change how a feed is fetched",
+		description: "Subcommands: set-user-agent, set-schedule (a cron expression), set-auth and set-header (credentials and headers to fetch with; only the feed's owner or an admin may set or list them, and a secret left off the command line is read without echo), set-priority (points added to every post's score), set-tier (high, normal or low, how often the scheduler fetches it), pause and resume (stop and restart fetching), delete, chown (hand the feed to another user; only its owner or an admin may, and without a user it lists past owners), share and unshare (have every user, including ones who register later, follow the feed, each with their own read and starred posts). Leaving out the value goes back to the default. Every subcommand changes the feed for all its followers, so only the feed's owner or an admin may use it, and it takes only the feed's URL, one of the current user's aliases or its exact name, never a partial match.",
+	},
+	{
+		name:        "follow",
+		synopsis:    "feed",
+		summary:     "follow a feed",
+		description: "Follows a stored feed, named by its name, URL or one of the current user's aliases.",
+	},
+	{
+		name:        "discover",
+		synopsis:    "[--limit n] [topic]",
+		summary:     "suggest feeds to follow",
+		description: "Suggests feeds the current user doesn't follow yet: the ones most followed on this instance, then ones from the directory gator ships with and the one at directory_url, if set, each with the command that follows or adds it. A topic narrows them to feeds tagged with it or mentioning it in their name or description.",
+		options: []optionDoc{
+			{"--limit n", "maximum number of this instance's feeds to suggest"},
+		},
+	},
+	{
+		name:        "unfollow",
+		synopsis:    "[feed]",
+		summary:     "stop following a feed",
+		description: "Unfollows the named feed or, without one, whichever followed feed is picked from a list.",
+	},
+	{
+		name:        "following",
+		synopsis:    "[--format text|csv|tsv] [--columns list]",
+		summary:     "list followed feeds",
+		description: "Lists the feeds the current user follows, with their tags, then the user's folders.",
+		options:     tableOptions,
+	},
+	{
+		name:        "alias",
+		synopsis:    "[add alias feed | remove alias | list]",
+		summary:     "manage short names for feeds",
+		description: "Manages the current user's aliases, which work anywhere a feed name or URL does.",
+	},
+	{
+		name:        "folder",
+		synopsis:    "[add name [options] | remove name | list]",
+		summary:     "manage folders of posts from many feeds",
+		description: "Manages the current user's folders, virtual feeds holding the followed posts that match one of their keywords, one of their tags and one of their authors, leaving out whichever a folder has none of. Adding a folder that exists replaces its rules. browse --folder lists a folder's posts.",
+		options: []optionDoc{
+			{"--keyword text", "include posts whose titles match this, in search syntax (repeatable)"},
+			{"--tag tag", "include posts with this tag, or from feeds with it (repeatable)"},
+			{"--author name", "include posts by this author (repeatable)"},
+		},
+	},
+	{
+		name:        "agg",
+		synopsis:    "[options]",
+		summary:     "fetch feeds and store new posts",
+		description: "Runs one pass over the stored feeds, or with --every keeps running and fetches each feed when it is due. Paused feeds are skipped unless named with --feed. fetch_workers in the config sets how many feeds are fetched at once (4 by default), with requests to the same host still host_delay apart. A Starlark filter_script from the config can drop, tag, rewrite or rescore each incoming post. The on_new_post and on_feed_error hooks in the config run for every post stored and every feed that fails. With otlp_endpoint set in the config, each pass is traced over OTLP.",
+		options: []optionDoc{
+			{"--every duration", "keep running, checking which feeds are due this often"},
+			{"--feed feed", "only fetch this feed; repeatable"},
+			{"--tag tag", "only fetch feeds with this tag; repeatable"},
+			{"--dry-run", "fetch and parse feeds without storing anything"},
+			{"--keep-raw", "keep fetched feed bodies in the cache"},
+			{"--from-cache", "re-aggregate from cached feed bodies instead of fetching"},
+		},
+	},
+	{
+		name:        "refresh",
+		synopsis:    "[--backfill] feed",
+		summary:     "fetch one feed now",
+		description: "Fetches one feed right away, whatever its schedule says, and reports how many posts were new. It works while the daemon runs.",
+		options: []optionDoc{
+			{"--backfill", "store every item in the feed, ignoring max_feed_items and items_per_fetch"},
+		},
+	},
+	{
+		name:        "history",
+		synopsis:    "[-n n] feed",
+		summary:     "show a feed's latest fetches",
+		description: "Lists the latest attempts at fetching a feed, newest first, with their status, timing and new posts.",
+		options: []optionDoc{
+			{"-n n", "how many attempts to show"},
+		},
+	},
+	{
+		name:        "newsletters",
+		summary:     "read newsletters from the mailbox",
+		description: "Files every new message in the configured IMAP mailbox as a post under its sender's feed.",
+	},
+	{
+		name:        "browse",
+		synopsis:    "[options] [limit]",
+		summary:     "list posts from followed feeds",
+		description: "Lists the latest posts from the feeds the current user follows and marks them seen. Later commands can refer to posts by their number in the listing.",
+		options: append([]optionDoc{
+			{"--sort key", "order posts by " + strings.Join(browseSorts, ", ")},
+			{"--asc, --desc", "sort in ascending or descending order"},
+			{"--group-by feed|day", "list posts under headings"},
+			{"--edited", "list posts edited since they were stored"},
+			{"--folder name", "only list posts in this folder"},
+			{"--pick", "pick a post to open from a filterable list"},
+			{"--absolute", "show full dates instead of how long ago posts were published"},
+			{"--template text", "Go template each post is printed with"},
+		}, tableOptions...),
+	},
+	{
+		name:        "whatsnew",
+		summary:     "list posts stored since the last browse",
+		description: "Lists the posts stored since the current user last browsed, or in the last day.",
+	},
+	{
+		name:        "digest",
+		synopsis:    "[--since duration] [--out file]",
+		summary:     "write a Markdown digest of recent posts",
+		description: "Writes the posts stored recently, grouped by feed, as Markdown. The on_digest hook in the config, if set, then gets it as JSON on stdin.",
+		options: []optionDoc{
+			{"--since duration", "include posts stored within this long"},
+			{"--out file", "write the digest to this file instead of standard output"},
+		},
+	},
+	{
+		name:        "related",
+		synopsis:    "[--limit n] post",
+		summary:     "list posts related to a post",
+		description: "Lists followed posts whose titles share words with the given post's, best matches first.",
+		options: []optionDoc{
+			{"--limit n", "maximum number of posts to show"},
+		},
+	},
+	{
+		name:        "search",
+		synopsis:    "[options] terms | save name [options] terms | run [--limit n] name | list | delete name",
+		summary:     "search followed posts",
+		description: "Lists followed posts whose titles match the terms, best matches first. Terms take web search syntax: \"quoted phrases\", or, and -word to exclude a word. search save stores the terms and filters under a name, replacing any search saved under it, for search run to repeat; search list and search delete manage the saved searches.",
+		options: []optionDoc{
+			{"--feed feed", "only search posts from this feed"},
+			{"--tag tag", "only search posts with this tag, or from feeds with it"},
+			{"--starred", "only search starred posts"},
+			{"--unread", "only search posts not marked as read"},
+			{"--limit n", "maximum number of posts to show"},
+		},
+	},
+	{
+		name:        "read",
+		synopsis:    "[--all [--feed feed] [--older-than age]] [post]",
+		summary:     "mark posts as read",
+		description: "Marks one post as read, or with --all every followed post.",
+		options: []optionDoc{
+			{"--all", "mark every followed post as read"},
+			{"--feed feed", "with --all, only posts from this feed"},
+			{"--older-than age", "with --all, only posts older than this, e.g. 7d or 12h"},
+		},
+	},
+	{
+		name:        "open",
+		synopsis:    "[--mark-read] [post]",
+		summary:     "open a post in the browser",
+		description: "Opens a post in the browser; without one, a recent post is picked from a list.",
+		options: []optionDoc{
+			{"--mark-read", "also mark the post as read"},
+		},
+	},
+	{
+		name:        "star",
+		synopsis:    "[--note text] post",
+		summary:     "add a post to the reading list",
+		description: "Saves a post to the reading list, optionally with a note. The note is the one the note command keeps, so starring the post again with --note replaces it.",
+		options: []optionDoc{
+			{"--note text", "a note to keep with the post"},
+		},
+	},
+	{
+		name:        "note",
+		synopsis:    "post [text] | --delete post",
+		summary:     "keep a note on a post",
+		description: "Keeps a note of the current user's on a post, such as why they saved it, replacing any note it had; with only a post it prints the note. browse, whatsnew and starred show notes under their posts, the reading list export quotes them, and browse tables have a note column.",
+		options: []optionDoc{
+			{"--delete", "remove the note"},
+		},
+	},
+	{
+		name:        "unstar",
+		synopsis:    "post",
+		summary:     "remove a post from the reading list",
+		description: "Removes a post from the reading list.",
+	},
+	{
+		name:        "starred",
+		summary:     "list the reading list",
+		description: "Lists the reading list, oldest star first.",
+	},
+	{
+		name:        "trending",
+		synopsis:    "[--since age] [--limit n]",
+		summary:     "list what other users are reading",
+		description: "Ranks recent posts from every feed by how many users on this instance starred or read them, a star counting for three reads, with newer posts ranking above older ones with as many. Each post shows its star and read counts.",
+		options: []optionDoc{
+			{"--since age", "only rank posts published within this long, e.g. 2d, 1w or 36h (default 7d)"},
+			{"--limit n", "maximum number of posts to show (default 20)"},
+		},
+	},
+	{
+		name:        "save",
+		synopsis:    "[--to service] post",
+		summary:     "send a post to a read-it-later service",
+		description: "Sends a post to Pocket, Instapaper or wallabag. --to can be left out when only one is configured.",
+		options: []optionDoc{
+			{"--to service", "pocket, instapaper or wallabag"},
+		},
+	},
+	{
+		name:        "archive",
+		synopsis:    "post",
+		summary:     "save a post's page for offline reading",
+		description: "Saves a post's page and the images it shows under the data directory.",
+	},
+	{
+		name:        "download",
+		synopsis:    "post-id [dir]",
+		summary:     "download a post's enclosure",
+		description: "Downloads a podcast episode or other enclosure into dir, or the current directory.",
+	},
+	{
+		name:        "keywords",
+		synopsis:    "[set keyword weight | remove keyword]",
+		summary:     "manage keyword weights",
+		description: "Lists, sets or removes the weights posts score for matching keywords.",
+	},
+	{
+		name:        "prefs",
+		synopsis:    "[get [key] | set key [value]]",
+		summary:     "show and change preferences",
+		description: "Shows and changes the current user's preferences. Setting one without a value goes back to the default.",
+	},
+	{
+		name:        "timezone",
+		synopsis:    "[zone | local]",
+		summary:     "show or set the display timezone",
+		description: "Shows or sets the timezone dates are shown in; local uses the machine's.",
+	},
+	{
+		name:        "stats",
+		summary:     "show counts of feeds and posts",
+		description: "Shows how many feeds and posts are stored, posts per day and the most active feeds.",
+	},
+	{
+		name:        "export",
+		synopsis:    "posts | rss | reading-list | all [options]",
+		summary:     "export posts, a feed or the whole database",
+		description: "Exports the posts of followed feeds as CSV or JSON, the current user's posts as an RSS feed, the reading list as Markdown or HTML, or everything as NDJSON or JSON for import all.",
+		options: []optionDoc{
+			{"--out file", "file to write to instead of standard output"},
+			{"--format format", "output format, depending on what is exported"},
+		},
+	},
+	{
+		name:        "import",
+		synopsis:    "[--format auto|feedly|inoreader] file | all file",
+		summary:     "import subscriptions or a full export",
+		description: "Follows the feeds in an OPML, Feedly or Inoreader export, or with all restores what export all wrote.",
+		options: []optionDoc{
+			{"--format format", "export format: auto, feedly or inoreader"},
+		},
+	},
+	{
+		name:        "serve",
+		synopsis:    "[options]",
+		summary:     "serve the web UI, API and feed",
+		description: "Serves the current user's posts over HTTP: a web page, a JSON API and an RSS feed. /healthz and /readyz answer 503 while the database is unreachable or the daemon's scheduler has wedged.",
+		options: []optionDoc{
+			{"--addr address", "address to listen on"},
+			{"--public-url url", "URL this server is reachable on from the internet; enables WebSub"},
+			{"--multi-user", "let every user with a password log in"},
+			{"--grpc address", "also serve the gRPC API on this address"},
+		},
+	},
+	{
+		name:        "daemon",
+		synopsis:    "start | run | stop | reload | status [options]",
+		summary:     "run the scheduler in the background",
+		description: "Runs agg --every, and optionally serve, until stopped. start detaches; run stays in the foreground, for init systems. reload, or SIGHUP, makes the scheduler reread the config, including log_level, quiet hours, notification targets and daemon_every, how often feeds are checked when --every isn't given (5m by default). Quiet hours from the config pause fetching and notifications. With pprof set in the config, profiles are served on pprof_addr, localhost:6060 by default.",
+		options: []optionDoc{
+			{"--every duration", "how often to check which feeds are due, overriding daemon_every"},
+			{"--serve address", "also serve the feed on this address"},
+			{"--public-url url", "with --serve, URL the server is reachable on"},
+			{"--multi-user", "with --serve, let every user with a password log in"},
+			{"--grpc address", "with --serve, also serve the gRPC API on this address"},
+			{"--notify", "show a desktop notification when relevant posts arrive"},
+			{"--log-file", "log to the rotated log file in the data directory"},
+		},
+	},
+	{
+		name:        "logs",
+		synopsis:    "[-f] [-n n]",
+		summary:     "print the daemon's log",
+		description: "Prints the end of the daemon's log.",
+		options: []optionDoc{
+			{"-f", "keep printing lines as they are logged"},
+			{"-n n", "how many of the last lines to print"},
+		},
+	},
+	{
+		name:        "healthcheck",
+		synopsis:    "[--url url]",
+		summary:     "check the config, database and network",
+		description: "Checks that the database is reachable and migrated, and that outbound HTTP works, exiting non-zero when something is wrong.",
+		options: []optionDoc{
+			{"--url url", "URL to fetch to check outbound HTTP"},
+		},
+	},
+	{
+		name:        "dbpassword",
+		synopsis:    "store | forget",
+		summary:     "keep the database password in the keyring",
+		description: "Moves the database password from db_url into the OS keyring, or takes it back out.",
+	},
+	{
+		name:        "man",
+		synopsis:    "[--dir dir] [command]",
+		summary:     "write these manual pages",
+		description: "Prints the manual page for gator or one command, or with --dir writes all of them there as gator.1 and gator-command.1.",
+		options: []optionDoc{
+			{"--dir dir", "write every page into this directory"},
+		},
+	},
+}
+
+func findCommandDoc(name string) (commandDoc, bool) {
+	for _, doc := range commandDocs {
+		if doc.name == name {
+			return doc, true
+		}
+	}
+	return commandDoc{}, false
+}
+
+// handlerMan prints or writes troff man pages for gator and its commands,
+// for packaging.
+func handlerMan(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("man", flag.ContinueOnError)
+	dir := fs.String("dir", "", "write every page into this directory")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+
+	if *dir != "" {
+		if fs.NArg() > 0 {
+			return usageErrorf("man --dir writes every page and takes no command")
+		}
+		return writeManPages(*dir)
+	}
+
+	switch fs.NArg() {
+	case 0:
+		return writeMainManPage(os.Stdout)
+	case 1:
+		doc, ok := findCommandDoc(fs.Arg(0))
+		if !ok {
+			return usageErrorf("unknown command: %s", fs.Arg(0))
+		}
+		return writeCommandManPage(os.Stdout, doc)
+	default:
+		return usageErrorf("man takes at most one command")
+	}
+}
+
+func writeManPages(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", dir, err)
+	}
+
+	write := func(name string, page func(w io.Writer) error) error {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+		if err := page(f); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+		return f.Close()
+	}
+
+	if err := write("gator.1", writeMainManPage); err != nil {
+		return err
+	}
+	for _, doc := range commandDocs {
+		err := write("gator-"+doc.name+".1", func(w io.Writer) error {
+			return writeCommandManPage(w, doc)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	infof("Wrote %d man pages to %s\n", len(commandDocs)+1, dir)
+	return nil
+}
+
+func writeMainManPage(w io.Writer) error {
+	var b strings.Builder
+	manHeader(&b, "gator")
+	fmt.Fprintf(&b, ".SH NAME\ngator \\- %s\n", roff("RSS and Atom feed aggregator"))
+	b.WriteString(".SH SYNOPSIS\n.B gator\n[\\fB\\-q\\fR | \\fB\\-v\\fR | \\fB\\-vv\\fR] [\\fB\\-\\-auto\\-migrate\\fR]\n.I command\n[\\fIargs\\fR]\n")
+	fmt.Fprintf(&b, ".SH DESCRIPTION\n%s\n", roff("gator follows RSS and Atom feeds for one or more users, storing their posts in PostgreSQL to be browsed, searched, served and exported."))
+	b.WriteString(".SH OPTIONS\n")
+	manOption(&b, "-q, --quiet", "print only a command's own output and its errors")
+	manOption(&b, "-v, --verbose", "add debug detail, such as a summary of every HTTP request")
+	manOption(&b, "-vv", "add the HTTP headers too")
+	manOption(&b, "--auto-migrate", "apply pending database migrations before the command, as auto_migrate in the config does")
+	b.WriteString(".SH COMMANDS\n")
+	for _, doc := range commandDocs {
+		manOption(&b, doc.name, doc.summary+"; see gator-"+doc.name+"(1)")
+	}
+	fmt.Fprintf(&b, ".SH FILES\n.TP\n.I ~/.gatorconfig.json\n%s\n", roff("configuration, including db_url and the current user"))
+	fmt.Fprintf(&b, ".TP\n.I ~/.local/share/gator\n%s\n", roff("data directory, for the cache, archives and the daemon's log; see data_dir"))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeCommandManPage(w io.Writer, doc commandDoc) error {
+	var b strings.Builder
+	manHeader(&b, "gator-"+doc.name)
+	fmt.Fprintf(&b, ".SH NAME\ngator\\-%s \\- %s\n", roff(doc.name), roff(doc.summary))
+	fmt.Fprintf(&b, ".SH SYNOPSIS\n.B gator %s\n", roff(doc.name))
+	if doc.synopsis != "" {
+		fmt.Fprintf(&b, "%s\n", roff(doc.synopsis))
+	}
+	fmt.Fprintf(&b, ".SH DESCRIPTION\n%s\n", roff(doc.description))
+	if len(doc.options) > 0 {
+		b.WriteString(".SH OPTIONS\n")
+		for _, opt := range doc.options {
+			manOption(&b, opt.flag, opt.text)
+		}
+	}
+	b.WriteString(".SH SEE ALSO\n.BR gator (1)\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func manHeader(b *strings.Builder, title string) {
+	fmt.Fprintf(b, ".TH %s 1 \"\" \"gator %s\" \"gator manual\"\n", strings.ToUpper(roff(title)), version)
+}
+
+func manOption(b *strings.Builder, name, text string) {
+	fmt.Fprintf(b, ".TP\n.B %s\n%s\n", roff(name), roff(text))
+}
+
+// roff escapes text for a troff line: backslashes and hyphens, which
+// would otherwise be escapes and breakable hyphens, and a leading dot or
+// quote, which would start a request.
+func roff(text string) string {
+	text = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(text)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
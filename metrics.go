@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// feedMetricsWindow is how far back the per-feed metrics look in the
+// fetch log. They are gauges over that window rather than counters, so
+// they come out the same whichever process did the fetching.
+const feedMetricsWindow = 24 * time.Hour
+
+// writeMetrics serves /metrics in the Prometheus text format: the rate
+// limiter's counters when limiting is on, and per-feed fetch figures.
+func writeMetrics(s *state, limiter *rateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), s.dbTimeout)
+		defer cancel()
+
+		var b strings.Builder
+		if limiter != nil {
+			limiter.writeMetrics(&b)
+		}
+		if err := writeFeedMetrics(ctx, s, &b); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		fmt.Fprint(w, b.String())
+	}
+}
+
+// writeFeedMetrics reports, per feed, how often it was fetched over the
+// last feedMetricsWindow and how long, how much and how many new posts
+// that took, to spot slow or unusually chatty feeds.
+func writeFeedMetrics(ctx context.Context, s *state, w io.Writer) error {
+	stats, err := s.db.GetFetchStatsSince(ctx, time.Now().UTC().Add(-feedMetricsWindow))
+	if err != nil {
+		return fmt.Errorf("failed to get fetch stats: %v", err)
+	}
+
+	gauge := func(name, help string, value func(i int) string) {
+		fmt.Fprintf(w, "# HELP %s %s over the last 24 hours.\n", name, help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+		for i, stat := range stats {
+			fmt.Fprintf(w, "%s{feed=\"%s\"} %s\n", name, metricLabel(stat.FeedName), value(i))
+		}
+	}
+
+	gauge("gator_feed_fetches", "Fetches of each feed", func(i int) string {
+		return fmt.Sprint(stats[i].Fetches)
+	})
+	gauge("gator_feed_fetch_errors", "Failed fetches of each feed", func(i int) string {
+		return fmt.Sprint(stats[i].Errors)
+	})
+	gauge("gator_feed_fetch_seconds", "Time spent fetching each feed", func(i int) string {
+		return fmt.Sprint(float64(stats[i].DurationMs) / 1000)
+	})
+	gauge("gator_feed_fetch_max_seconds", "Longest fetch of each feed", func(i int) string {
+		return fmt.Sprint(float64(stats[i].MaxDurationMs) / 1000)
+	})
+	gauge("gator_feed_downloaded_bytes", "Bytes downloaded from each feed", func(i int) string {
+		return fmt.Sprint(stats[i].Bytes)
+	})
+	gauge("gator_feed_new_posts", "New posts stored from each feed", func(i int) string {
+		return fmt.Sprint(stats[i].NewPosts)
+	})
+	return nil
+}
+
+// metricLabel escapes a label value the way the Prometheus text format
+// wants.
+func metricLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
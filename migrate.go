@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// migrateDatabase applies the migrations bundled with this build that
+// the database is missing, the way goose up would. A Postgres advisory
+// lock keeps gators starting side by side, e.g. several containers, from
+// migrating at once; the ones that wait find nothing left to do.
+func migrateDatabase(ctx context.Context, s *state) error {
+	migrations, err := fs.Sub(schemaFiles, "sql/schema")
+	if err != nil {
+		return &exitCodeError{exitSchema, fmt.Errorf("failed to read bundled migrations: %v", err)}
+	}
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return fmt.Errorf("failed to set up the migration lock: %v", err)
+	}
+	provider, err := goose.NewProvider(goose.DialectPostgres, s.conn, migrations, goose.WithSessionLocker(locker))
+	if err != nil {
+		return &exitCodeError{exitSchema, fmt.Errorf("failed to read bundled migrations: %v", err)}
+	}
+
+	results, err := provider.Up(ctx)
+	if err != nil {
+		return &exitCodeError{exitSchema, fmt.Errorf("failed to migrate the database: %v", err)}
+	}
+	for _, r := range results {
+		infof("Applied migration %s\n", r.Source.Path)
+	}
+	return nil
+}
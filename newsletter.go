@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+const (
+	// newsletterScheme starts the URL of a newsletter pseudo-feed, which is
+	// mailto: its sender. Such feeds are filled from the mailbox and never
+	// fetched.
+	newsletterScheme = "mailto:"
+	// newsletterKeyword flags messages already turned into posts, on
+	// servers that allow keywords; others get \Seen instead.
+	newsletterKeyword = "$GatorProcessed"
+	// maxNewsletterParts caps how deeply nested a message's MIME parts are
+	// read.
+	maxNewsletterParts = 10
+)
+
+// "View this email in your browser", "Read online", "View on the web" ...
+var newsletterWebLink = regexp.MustCompile(`(?i)\b(view|read|open)\b.{0,20}\b(browser|online|web)\b`)
+
+func isNewsletterFeed(feed database.Feed) bool {
+	return strings.HasPrefix(feed.Url, newsletterScheme)
+}
+
+func handlerNewsletters(ctx context.Context, s *state, cmd command) error {
+	if s.Config.IMAPAddress() == "" {
+		return fmt.Errorf("no newsletter mailbox is configured, set imap_server in the config")
+	}
+
+	newPosts, err := pollNewsletters(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	infof("%d new newsletter posts\n", newPosts)
+	return nil
+}
+
+// pollNewsletters reads the configured mailbox folder and files every
+// message not processed yet, and from one of imap_senders if that's set,
+// as a post under its sender's pseudo-feed, which the current user follows.
+// It returns how many posts were new.
+func pollNewsletters(ctx context.Context, s *state) (int, error) {
+	userCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	user, err := currentUser(userCtx, s)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, s.fetchTimeout)
+	defer cancel()
+	client, err := dialIMAP(dialCtx, s.Config.IMAPAddress(), s.fetchTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to mailbox: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.login(s.Config.IMAPUsername, s.Config.IMAPPassword); err != nil {
+		return 0, err
+	}
+	keywords, err := client.selectFolder(s.Config.IMAPFolderOrDefault())
+	if err != nil {
+		return 0, err
+	}
+
+	criteria, processed := "UNSEEN", `\Seen`
+	if keywords {
+		criteria, processed = "UNKEYWORD "+newsletterKeyword, newsletterKeyword
+	}
+	senders, err := newsletterSenderCriteria(s.Config.IMAPSenders)
+	if err != nil {
+		return 0, err
+	}
+	if senders != "" {
+		criteria += " " + senders
+	}
+
+	uids, err := client.search(criteria + " NOT DELETED")
+	if err != nil {
+		return 0, err
+	}
+
+	newPosts := 0
+	for _, uid := range uids {
+		if err := ctx.Err(); err != nil {
+			return newPosts, err
+		}
+
+		raw, err := client.fetch(uid)
+		if err != nil {
+			return newPosts, err
+		}
+
+		// a message that can't be read now never will be, so it's flagged
+		// like the rest instead of failing every poll
+		letter, err := parseNewsletter(raw)
+		if err != nil {
+			fmt.Printf("Skipping message %d: %v\n", uid, err)
+		} else {
+			n, err := saveNewsletter(ctx, s, user, letter)
+			if err != nil {
+				return newPosts, err
+			}
+			newPosts += n
+		}
+
+		if err := client.addFlag(uid, processed); err != nil {
+			return newPosts, err
+		}
+	}
+
+	if err := client.logout(); err != nil {
+		fmt.Printf("Error logging out of mailbox: %v\n", err)
+	}
+
+	return newPosts, nil
+}
+
+// newsletterSenderCriteria turns imap_senders, addresses or bare domains,
+// into a search key matching mail from any of them.
+func newsletterSenderCriteria(senders []string) (string, error) {
+	var keys []string
+	for _, sender := range senders {
+		sender = strings.TrimPrefix(strings.TrimSpace(sender), "@")
+		if sender == "" {
+			continue
+		}
+		quoted, err := imapQuote(sender)
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, "FROM "+quoted)
+	}
+	if len(keys) == 0 {
+		return "", nil
+	}
+
+	// OR takes two keys, so n senders need n-1 of them in front
+	return strings.Repeat("OR ", len(keys)-1) + strings.Join(keys, " "), nil
+}
+
+type newsletter struct {
+	from      *mail.Address
+	subject   string
+	date      time.Time
+	messageID string
+	text      string
+	html      string
+}
+
+// parseNewsletter reads a raw RFC 5322 message.
+func parseNewsletter(raw []byte) (*newsletter, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %v", err)
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender: %v", err)
+	}
+	from.Address = strings.ToLower(from.Address)
+
+	letter := &newsletter{
+		from:      from,
+		messageID: strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>"),
+	}
+	if letter.subject, err = new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); err != nil {
+		letter.subject = msg.Header.Get("Subject")
+	}
+	if letter.date, err = msg.Header.Date(); err != nil {
+		letter.date = time.Now()
+	}
+	if letter.messageID == "" {
+		sum := sha256.Sum256(raw)
+		letter.messageID = hex.EncodeToString(sum[:16])
+	}
+
+	if err := letter.readPart(msg.Header, msg.Body, 0); err != nil {
+		return nil, err
+	}
+	if letter.text == "" && letter.html == "" {
+		return nil, fmt.Errorf("message from %s has no text", from.Address)
+	}
+
+	return letter, nil
+}
+
+// readPart keeps the first plain text and HTML bodies found in a MIME part,
+// descending into multipart ones and skipping attachments.
+func (n *newsletter) readPart(header interface{ Get(string) string }, body io.Reader, depth int) error {
+	if depth > maxNewsletterParts {
+		return fmt.Errorf("message parts are nested too deeply")
+	}
+
+	if disposition, _, _ := mime.ParseMediaType(header.Get("Content-Disposition")); disposition == "attachment" {
+		return nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		parts := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := parts.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid multipart message: %v", err)
+			}
+			if err := n.readPart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return nil
+	}
+	if (mediaType == "text/plain" && n.text != "") || (mediaType == "text/html" && n.html != "") {
+		return nil
+	}
+
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read message body: %v", err)
+	}
+
+	text := strings.TrimSpace(decodeCharset(data, params["charset"]))
+	if mediaType == "text/html" {
+		n.html = text
+	} else {
+		n.text = text
+	}
+	return nil
+}
+
+// decodeCharset converts a body to UTF-8. Besides UTF-8 itself, only the
+// Latin-1 family is converted; anything else keeps its valid UTF-8 runs.
+func decodeCharset(data []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252":
+		if utf8.Valid(data) {
+			return string(data)
+		}
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default:
+		return strings.ToValidUTF8(string(data), "�")
+	}
+}
+
+// webLink returns the "view in browser" link newsletters carry, if this one
+// has one.
+func (n *newsletter) webLink() string {
+	if n.html == "" {
+		return ""
+	}
+
+	sel, err := compileSelector("a[href]")
+	if err != nil {
+		return ""
+	}
+	for _, a := range parseHTML(n.html).queryAll(sel) {
+		href := strings.TrimSpace(a.attrs["href"])
+		if !strings.HasPrefix(href, "https://") && !strings.HasPrefix(href, "http://") {
+			continue
+		}
+		if newsletterWebLink.MatchString(a.textContent()) {
+			return href
+		}
+	}
+	return ""
+}
+
+// item turns the message into a feed item. Its link is the web version
+// when there is one, and otherwise a mid: URL (RFC 2392) naming the
+// message, so it's still unique.
+func (n *newsletter) item() RSSItem {
+	link := n.webLink()
+	if link == "" {
+		link = "mid:" + url.PathEscape(n.messageID)
+	}
+
+	description := n.text
+	if description == "" {
+		page := parseHTML(n.html)
+		if body := page.find("body"); body != nil {
+			page = body
+		}
+		description = page.textContent()
+	}
+
+	title := strings.TrimSpace(n.subject)
+	if title == "" {
+		title = "(no subject)"
+	}
+
+	return RSSItem{
+		Title:       title,
+		Link:        link,
+		Description: description,
+		PubDate:     n.date.UTC().Format(time.RFC3339),
+		Author:      n.from.Name,
+	}
+}
+
+// saveNewsletter stores letter under its sender's pseudo-feed, creating
+// the feed for user the first time that sender writes.
+func saveNewsletter(ctx context.Context, s *state, user database.User, letter *newsletter) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	feedURL := newsletterScheme + letter.from.Address
+	feed, err := s.db.GetFeedByUrl(ctx, feedURL)
+	if err == sql.ErrNoRows {
+		if feed, err = createNewsletterFeed(ctx, s, user, letter.from, feedURL); err != nil {
+			return 0, err
+		}
+		infof("New newsletter: %s\n", feed.Name)
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to get feed: %v", err)
+	}
+
+	return savePosts(ctx, s, feed, []RSSItem{letter.item()}), nil
+}
+
+func createNewsletterFeed(ctx context.Context, s *state, user database.User, from *mail.Address, feedURL string) (database.Feed, error) {
+	var feed database.Feed
+	err := s.withTx(ctx, func(tx *state) error {
+		title := from.Name
+		if title == "" {
+			title = from.Address
+		}
+		name, err := unusedFeedName(ctx, tx, title, from.Address)
+		if err != nil {
+			return err
+		}
+
+		feed, err = tx.db.CreateFeed(ctx, database.CreateFeedParams{
+			ID:     uuid.New(),
+			UserID: user.ID,
+			Name:   name,
+			Url:    feedURL,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create feed: %v", err)
+		}
+
+		if _, err := tx.db.CreateFeedFollowIfMissing(ctx, database.CreateFeedFollowIfMissingParams{
+			ID:     uuid.New(),
+			UserID: user.ID,
+			FeedID: feed.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to follow feed: %v", err)
+		}
+		return nil
+	})
+	return feed, err
+}
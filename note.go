@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// noteStore is what note needs of the database once the post is found.
+type noteStore interface {
+	GetPostNote(ctx context.Context, arg database.GetPostNoteParams) (database.PostNote, error)
+	SetPostNote(ctx context.Context, arg database.SetPostNoteParams) error
+	DeletePostNote(ctx context.Context, arg database.DeletePostNoteParams) (int64, error)
+}
+
+// handlerNote keeps a note of the current user's on a post, such as why
+// they saved it. With only a post it shows the note; browse, starred and
+// the reading list export show it alongside the post.
+func handlerNote(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("note", flag.ContinueOnError)
+	remove := fs.Bool("delete", false, "remove the note instead")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("note command requires a post ID or a number from the last listing")
+	}
+	text := strings.TrimSpace(strings.Join(fs.Args()[1:], " "))
+	if *remove && text != "" {
+		return usageErrorf("note --delete takes only a post")
+	}
+
+	user, post, err := postForUser(ctx, s, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return editNote(ctx, s.db, user, post, text, *remove)
+}
+
+// editNote shows user's note on post when text is empty, or replaces or
+// removes it.
+func editNote(ctx context.Context, db noteStore, user database.User, post database.Post, text string, remove bool) error {
+	switch {
+	case remove:
+		removed, err := db.DeletePostNote(ctx, database.DeletePostNoteParams{UserID: user.ID, PostID: post.ID})
+		if err != nil {
+			return fmt.Errorf("failed to remove note: %v", err)
+		}
+		if removed == 0 {
+			return notFoundErrorf("%s has no note", post.Title)
+		}
+		infof("Removed the note on %s\n", post.Title)
+
+	case text == "":
+		note, err := db.GetPostNote(ctx, database.GetPostNoteParams{UserID: user.ID, PostID: post.ID})
+		if err == sql.ErrNoRows {
+			fmt.Printf("No note on %s\n", post.Title)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get note: %v", err)
+		}
+		fmt.Println(note.Note)
+
+	default:
+		if err := db.SetPostNote(ctx, database.SetPostNoteParams{
+			UserID: user.ID,
+			PostID: post.ID,
+			Note:   text,
+		}); err != nil {
+			return fmt.Errorf("failed to save note: %v", err)
+		}
+		infof("Saved note on %s\n", post.Title)
+	}
+	return nil
+}
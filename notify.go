@@ -0,0 +1,476 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/config"
+	"github.com/necodeus/gator/internal/database"
+)
+
+const (
+	// notifyBurst notifications go out at most per notifyWindow; posts
+	// arriving past that are counted and mentioned in the next one.
+	notifyBurst  = 5
+	notifyWindow = 10 * time.Minute
+	// notifySendTimeout bounds delivering one notification.
+	notifySendTimeout = 10 * time.Second
+)
+
+// notifier announces new posts on every sink. By default a sink gets the
+// posts that score at least minScore, i.e. match weighted keywords or
+// come from priority feeds; a sink can instead pick feeds, by name or by
+// the current user's tags, whose every new post it gets. A feed that
+// brings several posts at once gets a single notification.
+type notifier struct {
+	minScore float64
+	sinks    []*notifySink
+	// feedTags looks up the tags of a feed for sinks that pick by tag
+	feedTags func(ctx context.Context, feed database.Feed) ([]string, error)
+	// follows reports whether a user follows a feed, for users' own sinks
+	follows func(ctx context.Context, userID uuid.UUID, feed database.Feed) (bool, error)
+
+	// userSinks loads the sinks users set up in their preferences; they are
+	// loaded once, with the first posts to arrive
+	userSinks     func(ctx context.Context) ([]*notifySink, error)
+	userSinksOnce sync.Once
+
+	// quiet holds notifications back while it reports true; the next one
+	// after mentions how many posts were held
+	quiet func(now time.Time) bool
+}
+
+// notification is what every sink gets; title and body are ready to
+// show, and sinks that format their own messages use feed and posts.
+type notification struct {
+	title string
+	body  string
+	url   string
+
+	feed  string
+	posts []database.CreatePostParams
+	// missed counts posts held back by the rate limit since the last one
+	missed int
+}
+
+// notifySink delivers notifications one way, e.g. to the desktop, and
+// rate limits them on its own.
+type notifySink struct {
+	name string
+	send func(ctx context.Context, n notification) error
+	// feeds and tags pick the feeds this sink wants every post from; when
+	// both are empty it gets the relevant posts from any feed
+	feeds []string
+	tags  []string
+	// userID, when set, makes this a user's own sink, which only gets the
+	// relevant posts from feeds they follow
+	userID uuid.UUID
+
+	mu         sync.Mutex
+	sent       []time.Time
+	suppressed int
+}
+
+func newNotifier(minScore float64) *notifier {
+	return &notifier{minScore: minScore}
+}
+
+// newConfiguredNotifier sets up the sinks configured for s, plus those
+// users set up with their notify preferences. The daemon may add a
+// desktop sink later.
+func newConfiguredNotifier(s *state) *notifier {
+	nt := newNotifier(s.Config.NotifyMinScoreOrDefault())
+	if s.Config.NtfyTopic != "" {
+		sink := ntfySink(s, s.Config.NtfyTopic)
+		sink.feeds = s.Config.NtfyFeeds
+		nt.add(sink)
+	}
+	if s.Config.TelegramBotToken != "" {
+		if s.Config.TelegramChatID != "" {
+			nt.add(telegramSink(s, config.TelegramRoute{ChatID: s.Config.TelegramChatID}))
+		}
+		for _, route := range s.Config.TelegramRoutes {
+			nt.add(telegramSink(s, route))
+		}
+	}
+
+	nt.userSinks = func(ctx context.Context) ([]*notifySink, error) {
+		return preferredSinks(ctx, s)
+	}
+	nt.follows = func(ctx context.Context, userID uuid.UUID, feed database.Feed) (bool, error) {
+		ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+		defer cancel()
+
+		return s.db.IsFollowingFeed(ctx, database.IsFollowingFeedParams{
+			UserID: userID,
+			FeedID: feed.ID,
+		})
+	}
+	nt.feedTags = func(ctx context.Context, feed database.Feed) ([]string, error) {
+		ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+		defer cancel()
+
+		user, err := currentUser(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		tags, err := s.db.GetFeedTagsForUser(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tags: %v", err)
+		}
+
+		var names []string
+		for _, tag := range tags {
+			if tag.FeedID == feed.ID {
+				names = append(names, tag.Tag)
+			}
+		}
+		return names, nil
+	}
+	return nt
+}
+
+// preferredSinks makes a sink for every notification target a user set in
+// their preferences.
+func preferredSinks(ctx context.Context, s *state) ([]*notifySink, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	var sinks []*notifySink
+
+	topics, err := s.db.GetPreferencesByKey(ctx, prefNtfyTopic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %v", err)
+	}
+	for _, topic := range topics {
+		sink := ntfySink(s, topic.Value)
+		sink.userID = topic.UserID
+		sinks = append(sinks, sink)
+	}
+
+	if s.Config.TelegramBotToken != "" {
+		chats, err := s.db.GetPreferencesByKey(ctx, prefTelegramChatID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get preferences: %v", err)
+		}
+		for _, chat := range chats {
+			sink := telegramSink(s, config.TelegramRoute{ChatID: chat.Value})
+			sink.userID = chat.UserID
+			sinks = append(sinks, sink)
+		}
+	}
+
+	return sinks, nil
+}
+
+func (nt *notifier) add(sink *notifySink) {
+	nt.sinks = append(nt.sinks, sink)
+}
+
+// postsArrived sends each sink a notification about the posts from feed it
+// wants. It is safe to call on a nil notifier, and from several
+// goroutines.
+func (nt *notifier) postsArrived(ctx context.Context, feed database.Feed, posts []database.CreatePostParams) {
+	if nt == nil || len(posts) == 0 {
+		return
+	}
+
+	nt.userSinksOnce.Do(func() {
+		if nt.userSinks == nil {
+			return
+		}
+		sinks, err := nt.userSinks(ctx)
+		if err != nil {
+			fmt.Printf("Error loading notification preferences: %v\n", err)
+		}
+		nt.sinks = append(nt.sinks, sinks...)
+	})
+
+	var relevant []database.CreatePostParams
+	for _, post := range posts {
+		if post.Relevance >= nt.minScore {
+			relevant = append(relevant, post)
+		}
+	}
+
+	// tags are only looked up when a sink picks by them, and only once
+	var tags []string
+	tagsLoaded := false
+	quiet := nt.quiet != nil && nt.quiet(time.Now())
+
+	ctx, cancel := context.WithTimeout(ctx, notifySendTimeout)
+	defer cancel()
+
+	for _, sink := range nt.sinks {
+		wanted := relevant
+		if sink.userID != uuid.Nil && len(relevant) > 0 {
+			following, err := nt.follows(ctx, sink.userID, feed)
+			if err != nil {
+				fmt.Printf("Error checking who follows %s for notifications: %v\n", feed.Name, err)
+			}
+			if !following {
+				continue
+			}
+		} else if len(sink.feeds) > 0 || len(sink.tags) > 0 {
+			if len(sink.tags) > 0 && !tagsLoaded && nt.feedTags != nil {
+				var err error
+				if tags, err = nt.feedTags(ctx, feed); err != nil {
+					fmt.Printf("Error loading tags of %s for notifications: %v\n", feed.Name, err)
+				}
+				tagsLoaded = true
+			}
+
+			wanted = nil
+			if sink.picks(feed, tags) {
+				wanted = posts
+			}
+		}
+		if len(wanted) == 0 {
+			continue
+		}
+		if quiet {
+			sink.hold(len(wanted))
+			continue
+		}
+
+		if err := sink.notify(ctx, feed, wanted); err != nil {
+			fmt.Printf("Error sending %s notification: %v\n", sink.name, err)
+		}
+	}
+}
+
+// picks reports whether the sink wants every post from feed, which has
+// tags.
+func (sink *notifySink) picks(feed database.Feed, tags []string) bool {
+	matches := func(want []string, name string) bool {
+		return slices.ContainsFunc(want, func(w string) bool { return strings.EqualFold(w, name) })
+	}
+
+	if matches(sink.feeds, feed.Name) {
+		return true
+	}
+	return slices.ContainsFunc(tags, func(tag string) bool { return matches(sink.tags, tag) })
+}
+
+// notify sends one notification about posts, unless the sink is over its
+// rate limit.
+func (sink *notifySink) notify(ctx context.Context, feed database.Feed, posts []database.CreatePostParams) error {
+	top := slices.MaxFunc(posts, func(a, b database.CreatePostParams) int {
+		return cmp.Compare(a.Relevance, b.Relevance)
+	})
+
+	n := notification{title: feed.Name, body: top.Title, url: top.Url, feed: feed.Name, posts: posts}
+	if len(posts) > 1 {
+		n.body = fmt.Sprintf("%d new posts, including %s", len(posts), top.Title)
+	}
+
+	missed, ok := sink.allow(time.Now(), len(posts))
+	if !ok {
+		return nil
+	}
+	if missed > 0 {
+		n.missed = missed
+		n.body += fmt.Sprintf("\n(and %d more while notifications were paused)", missed)
+	}
+
+	return sink.send(ctx, n)
+}
+
+// allow reports whether a notification about count posts may go out at
+// now, and if so how many posts were held back since the last one.
+func (sink *notifySink) allow(now time.Time, count int) (int, bool) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	sink.sent = slices.DeleteFunc(sink.sent, func(t time.Time) bool {
+		return now.Sub(t) >= notifyWindow
+	})
+	if len(sink.sent) >= notifyBurst {
+		sink.suppressed += count
+		return 0, false
+	}
+
+	sink.sent = append(sink.sent, now)
+	missed := sink.suppressed
+	sink.suppressed = 0
+	return missed, true
+}
+
+// hold counts count posts the sink isn't told about now, for the next
+// notification to mention.
+func (sink *notifySink) hold(count int) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	sink.suppressed += count
+}
+
+// desktopSink shows notifications with the desktop's own notification
+// service: notify-send (libnotify) on Linux and the BSDs, osascript on
+// macOS.
+func desktopSink() (*notifySink, error) {
+	sink := &notifySink{name: "desktop"}
+
+	switch runtime.GOOS {
+	case "darwin":
+		sink.send = func(ctx context.Context, n notification) error {
+			script := fmt.Sprintf("display notification %s with title %s", appleScriptString(n.body), appleScriptString(n.title))
+			return exec.CommandContext(ctx, "osascript", "-e", script).Run()
+		}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		notifySend, err := exec.LookPath("notify-send")
+		if err != nil {
+			return sink, fmt.Errorf("desktop notifications need notify-send, which comes with libnotify")
+		}
+		sink.send = func(ctx context.Context, n notification) error {
+			body := n.body
+			if n.url != "" {
+				body += "\n" + n.url
+			}
+			return exec.CommandContext(ctx, notifySend, "--app-name=gator", "--", n.title, body).Run()
+		}
+	default:
+		return sink, fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+
+	return sink, nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// ntfySink publishes notifications to a topic on an ntfy server, so they
+// reach the ntfy app on a phone.
+func ntfySink(s *state, topic string) *notifySink {
+	topicURL := s.Config.NtfyServerOrDefault() + "/" + url.PathEscape(topic)
+
+	return &notifySink{
+		name: "ntfy",
+		send: func(ctx context.Context, n notification) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, topicURL, strings.NewReader(n.body))
+			if err != nil {
+				return fmt.Errorf("creating request: %w", err)
+			}
+			req.Header.Set("User-Agent", s.userAgent(nil))
+			// ntfy reads headers as UTF-8 only when RFC 2047 encoded
+			req.Header.Set("Title", mime.QEncoding.Encode("utf-8", n.title))
+			if n.url != "" {
+				req.Header.Set("Click", n.url)
+			}
+			if s.Config.NtfyToken != "" {
+				req.Header.Set("Authorization", "Bearer "+s.Config.NtfyToken)
+			}
+
+			resp, err := s.client.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+				return fmt.Errorf("ntfy answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+			}
+			return nil
+		},
+	}
+}
+
+// maxTelegramPosts caps the posts listed in one Telegram message, which
+// can't be longer than 4096 characters.
+const maxTelegramPosts = 10
+
+// telegramSink sends new posts through a Telegram bot to the chat a route
+// names, formatted with their titles, feed and links.
+func telegramSink(s *state, route config.TelegramRoute) *notifySink {
+	endpoint := "https://api.telegram.org/bot" + s.Config.TelegramBotToken + "/sendMessage"
+
+	return &notifySink{
+		name:  "telegram",
+		feeds: route.Feeds,
+		tags:  route.Tags,
+		send: func(ctx context.Context, n notification) error {
+			body, err := json.Marshal(map[string]any{
+				"chat_id":                  route.ChatID,
+				"text":                     telegramMessage(n),
+				"parse_mode":               "HTML",
+				"disable_web_page_preview": len(n.posts) > 1,
+			})
+			if err != nil {
+				return err
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+			if err != nil {
+				return fmt.Errorf("creating request: %w", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", s.userAgent(nil))
+
+			resp, err := s.client.Do(req)
+			if err != nil {
+				// the URL carries the bot token
+				if urlErr, ok := err.(*url.Error); ok {
+					return urlErr.Err
+				}
+				return err
+			}
+			defer resp.Body.Close()
+
+			var result struct {
+				OK          bool   `json:"ok"`
+				Description string `json:"description"`
+			}
+			if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+				return fmt.Errorf("telegram answered %s", resp.Status)
+			}
+			if !result.OK {
+				return fmt.Errorf("telegram refused message to %s: %s", route.ChatID, result.Description)
+			}
+			return nil
+		},
+	}
+}
+
+// telegramMessage formats a notification as Telegram HTML: the feed name,
+// then each post as a linked title.
+func telegramMessage(n notification) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>%s</b>\n", html.EscapeString(n.feed))
+
+	for i, post := range n.posts {
+		if i == maxTelegramPosts {
+			fmt.Fprintf(&b, "\n…and %d more", len(n.posts)-i)
+			break
+		}
+		title := post.Title
+		if title == "" {
+			title = post.Url
+		}
+		fmt.Fprintf(&b, "\n<a href=\"%s\">%s</a>", html.EscapeString(post.Url), html.EscapeString(title))
+	}
+
+	if n.missed > 0 {
+		fmt.Fprintf(&b, "\n\n<i>and %d more while notifications were paused</i>", n.missed)
+	}
+	return b.String()
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+func handlerOpen(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("open", flag.ContinueOnError)
+	markRead := fs.Bool("mark-read", false, "also mark the post as read")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+
+	var post database.Post
+	var err error
+	switch {
+	case fs.NArg() > 0:
+		post, err = findPostRef(ctx, s, fs.Arg(0))
+	case canPick():
+		post, err = pickRecentPost(ctx, s)
+	default:
+		return usageErrorf("open command requires a post ID or a number from the last listing")
+	}
+	if err != nil {
+		return err
+	}
+
+	// the picker may have waited on the user, so the timeout starts here
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	if err := openBrowser(post.Url); err != nil {
+		return fmt.Errorf("failed to open %s: %v", post.Url, err)
+	}
+	infof("Opened %s\n", post.Url)
+
+	if *markRead {
+		user, err := currentUser(ctx, s)
+		if err != nil {
+			return err
+		}
+		if _, err := s.db.MarkPostRead(ctx, database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to mark post as read: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// findPostRef loads the post a post ID or listing number refers to.
+func findPostRef(ctx context.Context, s *state, ref string) (database.Post, error) {
+	postID, err := resolvePostRef(s, ref)
+	if err != nil {
+		return database.Post{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	post, err := s.db.GetPostById(ctx, postID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return database.Post{}, notFoundErrorf("post %s does not exist", postID)
+		}
+		return database.Post{}, fmt.Errorf("failed to get post: %v", err)
+	}
+	return post, nil
+}
+
+// pickLimit is how many of the latest posts the picker offers when no
+// post is named.
+const pickLimit = 200
+
+// pickRecentPost lets the user pick one of the latest posts from the
+// feeds they follow.
+func pickRecentPost(ctx context.Context, s *state) (database.Post, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return database.Post{}, err
+	}
+	rows, err := s.db.GetPostsForUser(ctx, database.GetPostsForUserParams{
+		UserID:     user.ID,
+		SortBy:     "published",
+		Descending: true,
+		MaxPosts:   pickLimit,
+	})
+	if err != nil {
+		return database.Post{}, fmt.Errorf("failed to get posts: %v", err)
+	}
+	if len(rows) == 0 {
+		return database.Post{}, notFoundErrorf("no posts yet, follow some feeds and run agg")
+	}
+
+	return pickPost(rows, userLocation(user))
+}
+
+// pickPost lets the user pick one of rows, each shown with its feed and
+// date.
+func pickPost(rows []database.GetPostsForUserRow, loc *time.Location) (database.Post, error) {
+	items := make([]string, 0, len(rows))
+	for _, row := range rows {
+		item := fmt.Sprintf("[%s] %s", row.FeedName, row.Post.Title)
+		if row.Post.PublishedAt.Valid {
+			item += " · " + relativeTime(row.Post.PublishedAt.Time, time.Now(), loc)
+		}
+		// notes can be typed to find the posts they're on
+		if row.Note.Valid {
+			item += " · " + strings.Join(strings.Fields(row.Note.String), " ")
+		}
+		items = append(items, item)
+	}
+
+	choice, err := pick("Open", items)
+	if err != nil {
+		return database.Post{}, err
+	}
+	return rows[choice].Post, nil
+}
+
+// resolvePostRef turns a post ID, or the number a post had in the last
+// browse or whatsnew listing, into a post ID.
+func resolvePostRef(s *state, ref string) (uuid.UUID, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		return id, nil
+	}
+
+	index, err := strconv.Atoi(ref)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%s is neither a post ID nor a listing number", ref)
+	}
+
+	ids, err := loadListing(s)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if index < 1 || index > len(ids) {
+		return uuid.Nil, fmt.Errorf("the last listing has no post %d, run browse first", index)
+	}
+
+	return ids[index-1], nil
+}
+
+func saveListing(s *state, ids []uuid.UUID) error {
+	path, err := s.Config.ListingFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, id := range ids {
+		b.WriteString(id.String())
+		b.WriteByte('\n')
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+func loadListing(s *state) ([]uuid.UUID, error) {
+	path, err := s.Config.ListingFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read last listing: %v", err)
+	}
+
+	var ids []uuid.UUID
+	for _, line := range strings.Fields(string(data)) {
+		id, err := uuid.Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("last listing is corrupt: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// openBrowser hands url to the desktop's default handler without waiting
+// for the browser to exit.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// errNothingPicked is returned by pick when the user backs out with Esc
+// or Ctrl-C.
+var errNothingPicked = errors.New("nothing picked")
+
+// pickerRows is how many matches the picker shows at once.
+const pickerRows = 10
+
+// canPick reports whether there is a terminal to show the picker on.
+// It drives the terminal with stty, which Windows doesn't have.
+func canPick() bool {
+	return runtime.GOOS != "windows" && stdinIsTerminal() && stdoutIsTerminal()
+}
+
+// pick lets the user narrow items down by typing, fzf-style, and choose
+// one with the arrow keys and Enter. It returns the chosen item's index.
+func pick(prompt string, items []string) (int, error) {
+	if len(items) == 0 {
+		return 0, errNothingPicked
+	}
+
+	restore, err := rawTerminal()
+	if err != nil {
+		return 0, fmt.Errorf("failed to set up terminal: %v", err)
+	}
+	defer restore()
+
+	p := &picker{prompt: prompt, items: items, width: terminalWidth()}
+	p.filter()
+	p.draw()
+	defer p.clear()
+
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return 0, errNothingPicked
+		}
+
+		for _, key := range splitKeys(buf[:n]) {
+			switch key {
+			case "\x03", "\x04", "\x1b":
+				// Ctrl-C, Ctrl-D or Esc
+				return 0, errNothingPicked
+			case "\r", "\n":
+				if len(p.matches) > 0 {
+					return p.matches[p.selected], nil
+				}
+			case "\x7f", "\b":
+				if p.query != "" {
+					_, size := utf8.DecodeLastRuneInString(p.query)
+					p.query = p.query[:len(p.query)-size]
+					p.filter()
+				}
+			case "\x15":
+				// Ctrl-U
+				p.query = ""
+				p.filter()
+			case "\x1b[A", "\x1bOA", "\x10":
+				// up or Ctrl-P
+				if p.selected > 0 {
+					p.selected--
+				}
+			case "\x1b[B", "\x1bOB", "\x0e":
+				// down or Ctrl-N
+				if p.selected < len(p.matches)-1 {
+					p.selected++
+				}
+			default:
+				if key[0] >= ' ' && key[0] != 0x7f {
+					p.query += key
+					p.filter()
+				}
+			}
+		}
+		p.draw()
+	}
+}
+
+// splitKeys splits what one read from the terminal returned into keys,
+// since typing fast or pasting delivers several at once. Arrow keys come
+// as escape sequences.
+func splitKeys(b []byte) []string {
+	var keys []string
+	for len(b) > 0 {
+		size := 1
+		switch {
+		case b[0] == 0x1b && len(b) >= 3 && (b[1] == '[' || b[1] == 'O'):
+			size = 3
+		case b[0] >= utf8.RuneSelf:
+			_, size = utf8.DecodeRune(b)
+		}
+		keys = append(keys, string(b[:size]))
+		b = b[size:]
+	}
+	return keys
+}
+
+type picker struct {
+	prompt string
+	items  []string
+	width  int
+
+	query    string
+	matches  []int
+	selected int
+}
+
+// filter keeps the items matching the query, best matches first.
+func (p *picker) filter() {
+	type match struct {
+		index int
+		score int
+	}
+
+	var found []match
+	for i, item := range p.items {
+		if score, ok := fuzzyScore(p.query, item); ok {
+			found = append(found, match{i, score})
+		}
+	}
+	sort.SliceStable(found, func(a, b int) bool { return found[a].score < found[b].score })
+
+	p.matches = p.matches[:0]
+	for _, m := range found {
+		p.matches = append(p.matches, m.index)
+	}
+	p.selected = 0
+}
+
+// draw redraws the prompt and, below it, the visible matches, then puts
+// the cursor back after the query.
+func (p *picker) draw() {
+	var b strings.Builder
+	line := fmt.Sprintf("%s: %s", p.prompt, p.query)
+	fmt.Fprintf(&b, "\r\x1b[J%s", line)
+
+	// scroll so the selected match stays in view
+	first := max(0, p.selected-pickerRows+1)
+	last := min(len(p.matches), first+pickerRows)
+	for i := first; i < last; i++ {
+		item := truncate(p.items[p.matches[i]], p.width-2)
+		if i == p.selected {
+			fmt.Fprintf(&b, "\r\n\x1b[7m> %s\x1b[0m", item)
+		} else {
+			fmt.Fprintf(&b, "\r\n  %s", item)
+		}
+	}
+	fmt.Fprintf(&b, "\r\n  %d/%d", len(p.matches), len(p.items))
+
+	// the terminal is raw, so the cursor has to be walked back by hand
+	fmt.Fprintf(&b, "\x1b[%dA\r", last-first+1)
+	if n := utf8.RuneCountInString(line); n > 0 {
+		fmt.Fprintf(&b, "\x1b[%dC", n)
+	}
+	fmt.Print(b.String())
+}
+
+// clear erases the picker.
+func (p *picker) clear() {
+	fmt.Print("\r\x1b[J")
+}
+
+// fuzzyScore reports whether the letters of query appear in s in order,
+// ignoring case, and how good a match that is; lower is better. Plain
+// substrings beat scattered letters, and earlier and tighter matches beat
+// later and looser ones.
+func fuzzyScore(query, s string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	query, s = strings.ToLower(query), strings.ToLower(s)
+
+	if i := strings.Index(s, query); i >= 0 {
+		return i, true
+	}
+	if !isSubsequence(query, s) {
+		return 0, false
+	}
+
+	rest := []rune(query)
+	start, end := -1, 0
+	for i, r := range []rune(s) {
+		if r != rest[0] {
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		rest = rest[1:]
+		if len(rest) == 0 {
+			end = i
+			break
+		}
+	}
+	return 1000 + (end - start), true
+}
+
+// truncate cuts s to width runes, marking the cut with an ellipsis.
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// rawTerminal puts the terminal on stdin into raw mode, so keys arrive as
+// they are pressed and aren't echoed, and returns a func that puts it back.
+func rawTerminal() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() {
+		stty(strings.TrimSpace(saved))
+	}, nil
+}
+
+// hideInput stops the terminal on stdin echoing what is typed, for
+// passwords, and returns a func that turns echo back on.
+func hideInput() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("-echo"); err != nil {
+		return nil, err
+	}
+	return func() {
+		stty(strings.TrimSpace(saved))
+	}, nil
+}
+
+// terminalWidth asks stty how many columns the terminal has, assuming 80
+// if it can't tell.
+func terminalWidth() int {
+	size, err := stty("size")
+	if err != nil {
+		return 80
+	}
+	fields := strings.Fields(size)
+	if len(fields) != 2 {
+		return 80
+	}
+	cols, err := strconv.Atoi(fields[1])
+	if err != nil || cols <= 0 {
+		return 80
+	}
+	return cols
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
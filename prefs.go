@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// preference is a per-user setting gator keeps in the database, so users
+// sharing one database each have their own.
+type preference struct {
+	key         string
+	description string
+	// normalize checks a value and returns it the way it is stored
+	normalize func(value string) (string, error)
+}
+
+const (
+	prefBrowseLimit    = "browse.limit"
+	prefTimezone       = "timezone"
+	prefDigestSince    = "digest.since"
+	prefNtfyTopic      = "notify.ntfy-topic"
+	prefTelegramChatID = "notify.telegram-chat-id"
+)
+
+var preferences = []preference{
+	{
+		key:         prefBrowseLimit,
+		description: "how many posts browse shows by default",
+		normalize: func(value string) (string, error) {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return "", fmt.Errorf("expected a positive number")
+			}
+			return strconv.Itoa(n), nil
+		},
+	},
+	{
+		key:         prefTimezone,
+		description: "IANA timezone dates are shown in, or local",
+		normalize: func(value string) (string, error) {
+			if value == "local" {
+				return value, nil
+			}
+			if _, err := time.LoadLocation(value); err != nil {
+				return "", fmt.Errorf("expected an IANA name like Europe/Warsaw")
+			}
+			return value, nil
+		},
+	},
+	{
+		key:         prefDigestSince,
+		description: "how far back digest looks by default, e.g. 24h or 168h",
+		normalize: func(value string) (string, error) {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return "", fmt.Errorf("expected a positive duration like 24h")
+			}
+			return d.String(), nil
+		},
+	},
+	{
+		key:         prefNtfyTopic,
+		description: "ntfy topic that gets relevant posts from followed feeds",
+		normalize:   nonEmptyPreference,
+	},
+	{
+		key:         prefTelegramChatID,
+		description: "Telegram chat the configured bot sends relevant posts from followed feeds to",
+		normalize:   nonEmptyPreference,
+	},
+}
+
+func nonEmptyPreference(value string) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("expected a value")
+	}
+	return value, nil
+}
+
+func findPreference(key string) (preference, error) {
+	for _, pref := range preferences {
+		if pref.key == key {
+			return pref, nil
+		}
+	}
+
+	keys := make([]string, 0, len(preferences))
+	for _, pref := range preferences {
+		keys = append(keys, pref.key)
+	}
+	return preference{}, usageErrorf("unknown preference %s, expected one of %s", key, strings.Join(keys, ", "))
+}
+
+// handlerPrefs shows and changes the current user's preferences. Setting a
+// preference without a value goes back to the default.
+func handlerPrefs(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return prefsGet(ctx, s, nil)
+	}
+
+	switch cmd.Args[0] {
+	case "get":
+		return prefsGet(ctx, s, cmd.Args[1:])
+	case "set":
+		return prefsSet(ctx, s, cmd.Args[1:])
+	default:
+		return usageErrorf("unknown prefs subcommand: %s", cmd.Args[0])
+	}
+}
+
+func prefsGet(ctx context.Context, s *state, args []string) error {
+	if len(args) > 1 {
+		return usageErrorf("prefs get takes at most one preference")
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.db.GetPreferencesForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get preferences: %v", err)
+	}
+	values := make(map[string]string, len(stored)+1)
+	for _, pref := range stored {
+		values[pref.Key] = pref.Value
+	}
+	// the timezone predates preferences and stays on the user
+	if user.Timezone.Valid {
+		values[prefTimezone] = user.Timezone.String
+	}
+
+	show := preferences
+	if len(args) == 1 {
+		pref, err := findPreference(args[0])
+		if err != nil {
+			return err
+		}
+		show = []preference{pref}
+	}
+
+	for _, pref := range show {
+		value, ok := values[pref.key]
+		if !ok {
+			value = "(default)"
+		}
+		fmt.Printf("%s = %s\n", pref.key, value)
+		if len(args) == 1 {
+			fmt.Printf("  %s\n", pref.description)
+		}
+	}
+	return nil
+}
+
+func prefsSet(ctx context.Context, s *state, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("prefs set requires a preference and optionally a value")
+	}
+
+	pref, err := findPreference(args[0])
+	if err != nil {
+		return err
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	value := strings.TrimSpace(strings.Join(args[1:], " "))
+	if value != "" {
+		if value, err = pref.normalize(value); err != nil {
+			return fmt.Errorf("invalid %s: %v", pref.key, err)
+		}
+	}
+
+	if pref.key == prefTimezone {
+		err = s.db.SetUserTimezone(ctx, database.SetUserTimezoneParams{
+			ID:       user.ID,
+			Timezone: sql.NullString{String: value, Valid: value != "" && value != "local"},
+		})
+	} else if value == "" {
+		_, err = s.db.DeletePreference(ctx, database.DeletePreferenceParams{
+			UserID: user.ID,
+			Key:    pref.key,
+		})
+	} else {
+		err = s.db.SetPreference(ctx, database.SetPreferenceParams{
+			UserID: user.ID,
+			Key:    pref.key,
+			Value:  value,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set %s: %v", pref.key, err)
+	}
+
+	if value == "" {
+		infof("%s is back to the default for %s\n", pref.key, user.Name)
+	} else {
+		infof("%s = %s for %s\n", pref.key, value, user.Name)
+	}
+	return nil
+}
+
+// userPreference returns the value user stored for key, if any.
+func userPreference(ctx context.Context, s *state, userID uuid.UUID, key string) (string, bool, error) {
+	value, err := s.db.GetPreference(ctx, database.GetPreferenceParams{
+		UserID: userID,
+		Key:    key,
+	})
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get preference %s: %v", key, err)
+	}
+	return value, true, nil
+}
+
+// browseLimit is how many posts user sees when they don't ask for a number.
+func browseLimit(ctx context.Context, s *state, user database.User) (int, error) {
+	value, ok, err := userPreference(ctx, s, user.ID, prefBrowseLimit)
+	if err != nil || !ok {
+		return defaultBrowseLimit, err
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return defaultBrowseLimit, nil
+	}
+	return n, nil
+}
+
+// flagSet reports whether the flag name was given on the command line, as
+// opposed to left at its default for a preference to fill in.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultPreviewItems = 5
+
+// handlerPreview fetches and parses a feed and describes it, without
+// storing anything, so it can be checked before addfeed or follow.
+func handlerPreview(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("preview", flag.ContinueOnError)
+	items := fs.Int("items", defaultPreviewItems, "how many of the latest items to show")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("preview command requires a feed URL")
+	}
+
+	feedURL, err := normalizeFeedURL(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, s.fetchTimeout)
+	defer cancel()
+
+	rss, err := fetchFeed(fetchCtx, s.fetcher, feedURL, requestOptions{userAgent: s.userAgent(nil), maxSize: s.maxFeedSize}, s.maxItems)
+	if err != nil {
+		return networkError(err, fmt.Errorf("failed to fetch feed: %v", err))
+	}
+
+	channel := rss.Channel
+	fmt.Printf("Title: %s\n", channel.Title)
+	if channel.Link != "" {
+		fmt.Printf("Link: %s\n", channel.Link)
+	}
+	if description := strings.TrimSpace(channel.Description); description != "" {
+		fmt.Printf("Description: %s\n", description)
+	}
+	if channel.Author != "" {
+		fmt.Printf("Author: %s\n", channel.Author)
+	}
+	if rss.PermanentURL != "" {
+		fmt.Printf("Moved permanently to: %s\n", rss.PermanentURL)
+	}
+	fmt.Printf("Items: %d\n", len(channel.Item))
+
+	latest := latestItems(channel.Item, *items)
+	if len(latest) == 0 {
+		return nil
+	}
+
+	fmt.Println("Latest items:")
+	for _, item := range latest {
+		published := "unknown date"
+		if t, err := parsePubDate(item.PubDate); err == nil {
+			published = t.Local().Format("Mon Jan 2 2006 15:04 MST")
+		}
+		fmt.Printf("- %s\n  %s | %s\n", item.Title, published, item.Link)
+	}
+
+	return nil
+}
+
+// latestItems returns up to n items, newest first. Items without a
+// readable date keep their feed order after the dated ones.
+func latestItems(items []RSSItem, n int) []RSSItem {
+	type dated struct {
+		item RSSItem
+		at   time.Time
+	}
+
+	sorted := make([]dated, 0, len(items))
+	for _, item := range items {
+		at, _ := parsePubDate(item.PubDate)
+		sorted = append(sorted, dated{item, at})
+	}
+	slices.SortStableFunc(sorted, func(a, b dated) int {
+		return cmp.Compare(b.at.Unix(), a.at.Unix())
+	})
+
+	latest := make([]RSSItem, 0, min(n, len(sorted)))
+	for _, d := range sorted[:min(n, len(sorted))] {
+		latest = append(latest, d.item)
+	}
+	return latest
+}
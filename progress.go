@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// aggProgress is the status line an agg pass keeps at the bottom of the
+// terminal: feeds done out of those due, new posts, errors and the feed
+// being fetched. Whatever else is printed meanwhile scrolls above it. A
+// nil *aggProgress does nothing, for passes without a terminal to draw on.
+type aggProgress struct {
+	terminal *os.File
+
+	mu       sync.Mutex
+	total    int
+	done     int
+	newPosts int
+	errors   int
+	current  string
+
+	restore func()
+}
+
+// startAggProgress draws the status line for a pass over total feeds when
+// stdout is a terminal, and takes over stdout so lines printed during the
+// pass go above it.
+func startAggProgress(total int) *aggProgress {
+	if logLevel() < verbosityNormal || !stdoutIsTerminal() {
+		return nil
+	}
+
+	p := &aggProgress{terminal: os.Stdout, total: total}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil
+	}
+	os.Stdout = w
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			p.mu.Lock()
+			fmt.Fprintf(p.terminal, "\r\x1b[K%s\n", scanner.Text())
+			p.draw()
+			p.mu.Unlock()
+		}
+		io.Copy(p.terminal, r)
+	}()
+
+	p.restore = func() {
+		os.Stdout = p.terminal
+		w.Close()
+		<-done
+		r.Close()
+	}
+
+	p.mu.Lock()
+	p.draw()
+	p.mu.Unlock()
+	return p
+}
+
+// draw rewrites the status line; callers hold mu.
+func (p *aggProgress) draw() {
+	current := p.current
+	if len([]rune(current)) > 40 {
+		current = string([]rune(current)[:39]) + "…"
+	}
+	fmt.Fprintf(p.terminal, "\r\x1b[K[%d/%d] %d new, %d errors  %s", p.done, p.total, p.newPosts, p.errors, current)
+}
+
+// fetching shows that the feed called name is being worked on.
+func (p *aggProgress) fetching(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = name
+	p.draw()
+}
+
+// finished counts a feed as done, with the posts it added or the error it
+// failed with.
+func (p *aggProgress) finished(newPosts int, err error) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if err != nil {
+		p.errors++
+	} else {
+		p.newPosts += newPosts
+	}
+	p.current = ""
+	p.draw()
+}
+
+// stop gives stdout back and replaces the status line with a summary.
+func (p *aggProgress) stop() {
+	if p == nil {
+		return
+	}
+	p.restore()
+	fmt.Fprintf(p.terminal, "\r\x1b[KChecked %d feeds: %d new posts, %d errors\n", p.done, p.newPosts, p.errors)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+const defaultPublishLimit = 50
+
+// outputRSS is the RSS 2.0 document gator publishes for a user's followed
+// posts, so other readers can subscribe to gator itself.
+type outputRSS struct {
+	XMLName xml.Name      `xml:"rss"`
+	Version string        `xml:"version,attr"`
+	Channel outputChannel `xml:"channel"`
+}
+
+type outputChannel struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	Description   string       `xml:"description"`
+	LastBuildDate string       `xml:"lastBuildDate"`
+	Items         []outputItem `xml:"item"`
+}
+
+type outputItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description,omitempty"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Category    string        `xml:"category,omitempty"`
+	GUID        outputGUID    `xml:"guid"`
+	Enclosure   *RSSEnclosure `xml:"enclosure,omitempty"`
+}
+
+type outputGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// followedPosts loads the newest posts from the feeds the current user
+// follows, which is what both export rss and serve publish.
+func followedPosts(ctx context.Context, s *state, limit int) (database.User, []database.GetPostsForUserRow, error) {
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return database.User{}, nil, err
+	}
+
+	posts, err := followedPostsFor(ctx, s, user, limit)
+	if err != nil {
+		return database.User{}, nil, err
+	}
+
+	return user, posts, nil
+}
+
+// followedPostsFor loads the newest posts from the feeds user follows.
+func followedPostsFor(ctx context.Context, s *state, user database.User, limit int) ([]database.GetPostsForUserRow, error) {
+	posts, err := s.db.GetPostsForUser(ctx, database.GetPostsForUserParams{
+		UserID:   user.ID,
+		MaxPosts: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %v", err)
+	}
+
+	return posts, nil
+}
+
+func writeOutputFeed(w io.Writer, user database.User, posts []database.GetPostsForUserRow) error {
+	feed := outputRSS{
+		Version: "2.0",
+		Channel: outputChannel{
+			Title:         fmt.Sprintf("gator: %s", user.Name),
+			Link:          "https://github.com/necodeus/gator",
+			Description:   fmt.Sprintf("Posts from feeds followed by %s", user.Name),
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	for _, row := range posts {
+		item := outputItem{
+			Title:       row.Post.Title,
+			Link:        row.Post.Url,
+			Description: row.Post.Description.String,
+			Category:    row.FeedName,
+			GUID:        outputGUID{IsPermaLink: "true", Value: row.Post.Url},
+		}
+		if row.Post.PublishedAt.Valid {
+			item.PubDate = row.Post.PublishedAt.Time.Format(time.RFC1123Z)
+		}
+		if row.Post.EnclosureUrl.Valid {
+			item.Enclosure = &RSSEnclosure{
+				URL:  row.Post.EnclosureUrl.String,
+				Type: row.Post.EnclosureType.String,
+			}
+			if row.Post.EnclosureLength.Valid {
+				item.Enclosure.Length = strconv.FormatInt(row.Post.EnclosureLength.Int64, 10)
+			}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// quietHours is the daily window the daemon keeps quiet in, see
+// quiet_hours in the config.
+type quietHours struct {
+	start, end time.Duration
+	loc        *time.Location
+	// fetch and notify say whether fetching and notifications pause
+	fetch, notify bool
+}
+
+// loadQuietHours reads the configured quiet hours, placing them in the
+// current user's timezone. It returns nil when none are configured.
+func loadQuietHours(ctx context.Context, s *state) (*quietHours, error) {
+	start, end, ok, err := s.Config.QuietHoursWindow()
+	if err != nil || !ok {
+		return nil, err
+	}
+	fetch, notify, err := s.Config.QuietHoursPause()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the timezone for quiet hours: %v", err)
+	}
+
+	return &quietHours{
+		start:  start,
+		end:    end,
+		loc:    userLocation(user),
+		fetch:  fetch,
+		notify: notify,
+	}, nil
+}
+
+// active reports whether now falls within quiet hours. It is false on a
+// nil quietHours.
+func (q *quietHours) active(now time.Time) bool {
+	if q == nil {
+		return false
+	}
+
+	t := now.In(q.loc)
+	since := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if q.start < q.end {
+		return since >= q.start && since < q.end
+	}
+	// the window wraps past midnight
+	return since >= q.start || since < q.end
+}
+
+func (q *quietHours) pausesFetching(now time.Time) bool {
+	return q != nil && q.fetch && q.active(now)
+}
+
+func (q *quietHours) pausesNotifications(now time.Time) bool {
+	return q != nil && q.notify && q.active(now)
+}
+
+func (q *quietHours) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+
+	paused := "fetching and notifications"
+	switch {
+	case !q.notify:
+		paused = "fetching"
+	case !q.fetch:
+		paused = "notifications"
+	}
+	return fmt.Sprintf("%s-%s %s, pausing %s", clock(q.start), clock(q.end), q.loc, paused)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// rateLimiter gives every client of serve a token bucket: it refills at
+// rate tokens a second up to burst, and each request takes one. A request
+// is charged to its address until its session or password has been
+// checked, so made-up credentials can't buy a fresh bucket, and then to
+// the user it belongs to.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	// lastSweep is when idle buckets were last dropped
+	lastSweep time.Time
+
+	allowed int64
+	limited int64
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token from key's bucket at now. When the bucket is empty
+// it returns how long until a token is available.
+func (rl *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.sweep(now)
+
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: rl.burst, last: now}
+		rl.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(rl.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		rl.limited++
+		wait := time.Duration((1 - bucket.tokens) / rl.rate * float64(time.Second))
+		return false, wait
+	}
+
+	bucket.tokens--
+	rl.allowed++
+	return true, 0
+}
+
+// refund gives back a token allow took from key's bucket.
+func (rl *rateLimiter) refund(key string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if bucket, ok := rl.buckets[key]; ok {
+		bucket.tokens = math.Min(rl.burst, bucket.tokens+1)
+	}
+	rl.allowed--
+}
+
+// sweep drops the buckets that have refilled completely, which are the
+// same as no bucket at all, once per refill period.
+func (rl *rateLimiter) sweep(now time.Time) {
+	refill := time.Duration(rl.burst / rl.rate * float64(time.Second))
+	if now.Sub(rl.lastSweep) < refill {
+		return
+	}
+	rl.lastSweep = now
+
+	for key, bucket := range rl.buckets {
+		if now.Sub(bucket.last) >= refill {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+// limit wraps next so that requests over a client's limit get 429 Too Many
+// Requests with a Retry-After. WebSub pushes come from hubs, not clients,
+// and are let through.
+func (rl *rateLimiter) limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/websub/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := rateLimitKey(r)
+		ok, wait := rl.allow(key, time.Now())
+		if !ok {
+			writeRateLimited(w, r, wait)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), rateLimitCharge{}, rateLimitCharge{rl: rl, key: key})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// rateLimitCharge is the bucket limit took a request's token from, kept
+// in the request's context so limitUser can move it.
+type rateLimitCharge struct {
+	rl  *rateLimiter
+	key string
+}
+
+// limitUser moves a request's token from its address to user once the
+// request has been authenticated as them. It writes a 429 and returns
+// false when user is over their limit.
+func limitUser(w http.ResponseWriter, r *http.Request, user database.User) bool {
+	charge, ok := r.Context().Value(rateLimitCharge{}).(rateLimitCharge)
+	if !ok {
+		return true
+	}
+
+	charge.rl.refund(charge.key)
+	ok, wait := charge.rl.allow("user:"+user.ID.String(), time.Now())
+	if !ok {
+		writeRateLimited(w, r, wait)
+	}
+	return ok
+}
+
+func writeRateLimited(w http.ResponseWriter, r *http.Request, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		writeAPIError(w, http.StatusTooManyRequests, "rate limit exceeded")
+	} else {
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+	}
+}
+
+// rateLimitKey names the address a request comes from. Credentials aren't
+// trusted here; limitUser charges the user once they've been checked.
+func rateLimitKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
+
+// writeMetrics reports the limiter's counters in the Prometheus text
+// format.
+func (rl *rateLimiter) writeMetrics(w io.Writer) {
+	rl.mu.Lock()
+	allowed, limited, clients := rl.allowed, rl.limited, len(rl.buckets)
+	rl.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP gator_api_requests_total Requests checked against the rate limit, by outcome.")
+	fmt.Fprintln(w, "# TYPE gator_api_requests_total counter")
+	fmt.Fprintf(w, "gator_api_requests_total{outcome=\"allowed\"} %d\n", allowed)
+	fmt.Fprintf(w, "gator_api_requests_total{outcome=\"limited\"} %d\n", limited)
+	fmt.Fprintln(w, "# HELP gator_api_rate_limit_clients Clients whose rate limit has not refilled yet.")
+	fmt.Fprintln(w, "# TYPE gator_api_rate_limit_clients gauge")
+	fmt.Fprintf(w, "gator_api_rate_limit_clients %d\n", clients)
+}
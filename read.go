@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// handlerRead marks one post as read, or with --all every followed post,
+// optionally narrowed to one feed and to posts older than a given age.
+func handlerRead(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("read", flag.ContinueOnError)
+	all := fs.Bool("all", false, "mark every followed post as read")
+	feedName := fs.String("feed", "", "with --all, only posts from this feed")
+	olderThan := fs.String("older-than", "", "with --all, only posts older than this, e.g. 7d or 12h")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+
+	if !*all {
+		if *feedName != "" || *olderThan != "" {
+			return usageErrorf("--feed and --older-than only apply with --all")
+		}
+		if fs.NArg() == 0 {
+			return usageErrorf("read command requires a post ID or --all")
+		}
+	}
+
+	var before sql.NullTime
+	if *olderThan != "" {
+		age, err := parseAge(*olderThan)
+		if err != nil {
+			return usageError(err)
+		}
+		before = sql.NullTime{Time: time.Now().UTC().Add(-age), Valid: true}
+	}
+	feedID, err := feedFilter(ctx, s, *feedName)
+	if err != nil {
+		return err
+	}
+
+	// findFeed may have waited on the user, so the timeout starts here
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if !*all {
+		return markPostRead(ctx, s, user, fs.Arg(0))
+	}
+
+	params := database.MarkPostsReadParams{
+		UserID: user.ID,
+		FeedID: feedID,
+		Before: before,
+	}
+
+	marked, err := s.db.MarkPostsRead(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to mark posts as read: %v", err)
+	}
+
+	infof("Marked %d posts as read\n", marked)
+
+	return nil
+}
+
+func markPostRead(ctx context.Context, s *state, user database.User, id string) error {
+	postID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid post ID %s: %v", id, err)
+	}
+
+	post, err := s.db.GetPostById(ctx, postID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundErrorf("post %s does not exist", postID)
+		}
+		return fmt.Errorf("failed to get post: %v", err)
+	}
+
+	marked, err := s.db.MarkPostRead(ctx, database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark post as read: %v", err)
+	}
+
+	if marked == 0 {
+		infof("%s was already read\n", post.Title)
+	} else {
+		infof("Marked %s as read\n", post.Title)
+	}
+
+	return nil
+}
+
+// parseAge reads an age like "7d" or "2w" as well as anything
+// time.ParseDuration accepts, such as "36h".
+func parseAge(value string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(value, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid age %q", value)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q", value)
+	}
+	return age, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+var readingListTemplate = template.Must(template.New("reading-list").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 42em; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+li { margin-bottom: 1em; }
+.meta { color: #666; font-size: 0.9em; }
+blockquote { margin: 0.3em 0 0 0; padding-left: 0.8em; border-left: 3px solid #ccc; white-space: pre-line; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<ol>
+{{- range .Items}}
+<li>
+<a href="{{.URL}}">{{.Title}}</a>
+<div class="meta">{{.Feed}}{{if .Published}} · {{.Published}}{{end}} · saved {{.Saved}}</div>
+{{- if .Note}}
+<blockquote>{{.Note}}</blockquote>
+{{- end}}
+</li>
+{{- end}}
+</ol>
+</body>
+</html>
+`))
+
+// readingListItem is a starred post as the reading list shows it, with
+// dates already formatted in the user's time zone.
+type readingListItem struct {
+	Title     string
+	URL       string
+	Feed      string
+	Published string
+	Saved     string
+	Note      string
+}
+
+// exportReadingList renders the user's starred posts, in the order they
+// were starred, as a document to share.
+func exportReadingList(ctx context.Context, s *state, args []string) error {
+	fs := flag.NewFlagSet("export reading-list", flag.ContinueOnError)
+	format := fs.String("format", "md", "output format: md or html")
+	out := fs.String("out", "", "file to write to (defaults to stdout)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	if *format != "md" && *format != "html" {
+		return fmt.Errorf("unsupported reading list format: %s", *format)
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	posts, err := s.db.GetStarredPosts(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get starred posts: %v", err)
+	}
+
+	loc := userLocation(user)
+	items := make([]readingListItem, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, newReadingListItem(post, loc))
+	}
+
+	w, err := openOutput(*out)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	title := fmt.Sprintf("Reading list of %s", user.Name)
+	if *format == "html" {
+		err = readingListTemplate.Execute(w, struct {
+			Title string
+			Items []readingListItem
+		}{title, items})
+	} else {
+		err = writeReadingListMarkdown(w, title, items)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write reading list: %v", err)
+	}
+
+	if *out != "" {
+		infof("Exported %d starred posts to %s\n", len(items), *out)
+	}
+
+	return nil
+}
+
+func newReadingListItem(post database.GetStarredPostsRow, loc *time.Location) readingListItem {
+	item := readingListItem{
+		Title: post.Post.Title,
+		URL:   post.Post.Url,
+		Feed:  post.FeedName,
+		Saved: post.StarredAt.In(loc).Format("Jan 2, 2006"),
+		Note:  post.Note.String,
+	}
+	if item.Title == "" {
+		item.Title = post.Post.Url
+	}
+	if post.Post.PublishedAt.Valid {
+		item.Published = post.Post.PublishedAt.Time.In(loc).Format("Jan 2, 2006")
+	}
+	return item
+}
+
+func writeReadingListMarkdown(w io.Writer, title string, items []readingListItem) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", markdownEscaper.Replace(title))
+
+	for i, item := range items {
+		fmt.Fprintf(&b, "\n%d. [%s](%s)  \n", i+1, markdownEscaper.Replace(item.Title), markdownURL(item.URL))
+		meta := markdownEscaper.Replace(item.Feed)
+		if item.Published != "" {
+			meta += " · " + item.Published
+		}
+		fmt.Fprintf(&b, "   *%s · saved %s*\n", meta, item.Saved)
+		if item.Note != "" {
+			for _, line := range strings.Split(item.Note, "\n") {
+				fmt.Fprintf(&b, "   > %s\n", line)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
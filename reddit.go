@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	redditBase = "https://www.reddit.com"
+	// redditListingLimit is the most posts one listing request returns.
+	redditListingLimit = 100
+	// maxRedditBackoff caps how long a rate limit holds off Reddit.
+	maxRedditBackoff = 10 * time.Minute
+	// maxRedditListingSize caps the JSON read for one listing.
+	maxRedditListingSize = 10 << 20
+)
+
+var (
+	// r/golang, /r/golang, reddit.com/r/golang/, https://old.reddit.com/r/golang/new ...
+	redditShortcut = regexp.MustCompile(`^(?:https?://)?(?:(?:www\.|old\.|new\.)?reddit\.com)?/?r/([A-Za-z0-9_]{2,21})(?:/(hot|new|top|rising))?/?$`)
+	redditFeedPath = regexp.MustCompile(`^/r/([A-Za-z0-9_]{2,21})(?:/(hot|new|top|rising))?/?\.rss$`)
+)
+
+// redditFeedURL expands a subreddit shortcut into the subreddit's RSS
+// feed, which is what gator stores. ok is false when raw isn't one.
+func redditFeedURL(raw string) (feedURL string, ok bool) {
+	match := redditShortcut.FindStringSubmatch(strings.TrimSpace(raw))
+	if match == nil {
+		return "", false
+	}
+
+	path := "/r/" + match[1]
+	if match[2] != "" {
+		path += "/" + match[2]
+	}
+	return redditBase + path + "/.rss", true
+}
+
+// redditListingURL returns the JSON listing for a stored subreddit feed,
+// or "" when feedURL isn't one.
+func redditListingURL(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if host != "reddit.com" && !strings.HasSuffix(host, ".reddit.com") {
+		return ""
+	}
+
+	match := redditFeedPath.FindStringSubmatch(u.Path)
+	if match == nil {
+		return ""
+	}
+
+	path := "/r/" + match[1]
+	if match[2] != "" {
+		path += "/" + match[2]
+	}
+	return redditBase + path + "/.json?raw_json=1"
+}
+
+type redditListing struct {
+	Data struct {
+		Children []struct {
+			Data redditPost `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+type redditPost struct {
+	Title       string  `json:"title"`
+	Permalink   string  `json:"permalink"`
+	URL         string  `json:"url"`
+	Author      string  `json:"author"`
+	Selftext    string  `json:"selftext"`
+	IsSelf      bool    `json:"is_self"`
+	Thumbnail   string  `json:"thumbnail"`
+	Score       int32   `json:"score"`
+	NumComments int32   `json:"num_comments"`
+	CreatedUTC  float64 `json:"created_utc"`
+	Stickied    bool    `json:"stickied"`
+}
+
+// fetchRedditFeed reads a subreddit through its JSON listing, which,
+// unlike the RSS feed, carries each post's score and comment count. When
+// Reddit refuses the listing for any reason other than rate limiting, the
+// RSS feed is read instead. Rate limits hold off every request to Reddit
+// for as long as it asks.
+func fetchRedditFeed(ctx context.Context, s *state, feedURL string, opts requestOptions, maxItems int) (*RSSFeed, error) {
+	listingURL := redditListingURL(feedURL)
+
+	feed, err := fetchRedditListing(ctx, s, listingURL, opts, maxItems)
+	if err == nil {
+		return feed, nil
+	}
+	if _, limited := err.(redditRateLimitError); limited {
+		return nil, err
+	}
+
+	infof("Reddit listing unavailable (%v), reading the RSS feed instead\n", err)
+	if err := s.hosts.Wait(ctx, feedURL); err != nil {
+		return nil, err
+	}
+	return fetchFeed(ctx, s.fetcher, feedURL, opts, maxItems)
+}
+
+type redditRateLimitError struct {
+	until time.Time
+}
+
+func (e redditRateLimitError) Error() string {
+	return fmt.Sprintf("rate limited by Reddit until %s", e.until.Format(time.Kitchen))
+}
+
+func fetchRedditListing(ctx context.Context, s *state, listingURL string, opts requestOptions, maxItems int) (*RSSFeed, error) {
+	limit := redditListingLimit
+	if maxItems > 0 {
+		limit = min(limit, maxItems)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listingURL+"&limit="+strconv.Itoa(limit), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	opts.apply(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if until, limited := redditBackoff(resp, time.Now()); limited {
+		s.hosts.Defer(listingURL, until)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, redditRateLimitError{until: until}
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad response status: %s", resp.Status)
+	}
+
+	var listing redditListing
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRedditListingSize)).Decode(&listing); err != nil {
+		return nil, fmt.Errorf("decoding listing: %w", err)
+	}
+
+	var feed RSSFeed
+	feed.Channel.Link = strings.TrimSuffix(listingURL, "/.json?raw_json=1")
+	feed.Channel.Title = strings.TrimPrefix(feed.Channel.Link, redditBase+"/")
+	for _, child := range listing.Data.Children {
+		post := child.Data
+		if post.Stickied || post.Permalink == "" {
+			continue
+		}
+
+		item := RSSItem{
+			Title:        post.Title,
+			Link:         redditBase + post.Permalink,
+			Author:       "/u/" + post.Author,
+			PubDate:      time.Unix(int64(post.CreatedUTC), 0).UTC().Format(time.RFC3339),
+			Score:        &post.Score,
+			CommentCount: &post.NumComments,
+		}
+		if post.IsSelf {
+			item.Description = post.Selftext
+		} else {
+			item.Description = post.URL
+		}
+		// self posts and NSFW posts have words instead of a thumbnail URL
+		if strings.HasPrefix(post.Thumbnail, "http") {
+			item.Thumbnails = []MediaThumbnail{{URL: post.Thumbnail}}
+		}
+
+		feed.Channel.Item = append(feed.Channel.Item, item)
+	}
+
+	return &feed, nil
+}
+
+// redditBackoff reads Reddit's rate limit headers and reports until when
+// requests should stop, if they should. Reddit sends X-Ratelimit-Remaining
+// and X-Ratelimit-Reset (seconds) on every response, and Retry-After on
+// some 429s.
+func redditBackoff(resp *http.Response, now time.Time) (time.Time, bool) {
+	wait := time.Duration(-1)
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	remaining, err := strconv.ParseFloat(resp.Header.Get("X-Ratelimit-Remaining"), 64)
+	exhausted := err == nil && remaining < 1
+	if exhausted || (resp.StatusCode == http.StatusTooManyRequests && wait < 0) {
+		if reset, err := strconv.ParseFloat(resp.Header.Get("X-Ratelimit-Reset"), 64); err == nil {
+			wait = max(wait, time.Duration(math.Ceil(reset))*time.Second)
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests && wait < 0 {
+		wait = time.Minute
+	}
+	if wait < 0 {
+		return time.Time{}, false
+	}
+
+	return now.Add(min(wait, maxRedditBackoff)), true
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// handlerRefresh fetches one feed right away, whatever its schedule or
+// the publisher's polling hints say, for when a post is known to have
+// just gone live. Being fetched restarts the feed's wait for its next
+// scheduled fetch, with the hints re-read from what was just fetched.
+// It doesn't take the aggregation lock, so it works while the daemon
+// runs. --backfill stores every item the feed has instead of only the
+// newest, for feeds that ship their whole archive.
+func handlerRefresh(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("refresh", flag.ContinueOnError)
+	backfill := fs.Bool("backfill", false, "store every item in the feed, ignoring max_feed_items and items_per_fetch")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("refresh command requires a feed name or URL")
+	}
+
+	ref := strings.Join(fs.Args(), " ")
+	feed, err := findFeed(ctx, s, ref)
+	if err == sql.ErrNoRows {
+		return notFoundErrorf("feed %s does not exist", ref)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get feed: %v", err)
+	}
+	if isNewsletterFeed(feed) {
+		return usageErrorf("%s is a newsletter feed, which is read from the mailbox by agg", feed.Name)
+	}
+
+	cacheDir, err := s.Config.CacheDirPath()
+	if err != nil {
+		return fmt.Errorf("failed to locate cache: %v", err)
+	}
+
+	if err := s.hosts.Wait(ctx, feed.Url); err != nil {
+		return err
+	}
+	newPosts, err := aggregateFeed(ctx, s, feed, aggOptions{
+		cache:    feedCache{dir: cacheDir},
+		backfill: *backfill,
+	})
+	markFetched(ctx, s, feed)
+	if err != nil {
+		return networkError(err, fmt.Errorf("failed to fetch %s: %v", feed.Name, err))
+	}
+
+	switch newPosts {
+	case 0:
+		fmt.Printf("%s has no new posts\n", feed.Name)
+	case 1:
+		fmt.Printf("%s has 1 new post\n", feed.Name)
+	default:
+		fmt.Printf("%s has %d new posts\n", feed.Name, newPosts)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+const defaultRelatedLimit = 10
+
+// handlerRelated lists followed posts whose titles share words with the
+// given post's, best matches first, to find follow-up coverage of a story.
+func handlerRelated(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("related", flag.ContinueOnError)
+	limit := fs.Int("limit", defaultRelatedLimit, "maximum number of posts to show")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("related command requires a post ID or a number from the last listing")
+	}
+	if *limit <= 0 {
+		return usageErrorf("limit must be a positive number")
+	}
+
+	postID, err := resolvePostRef(s, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	post, err := s.db.GetPostById(ctx, postID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundErrorf("post %s does not exist", postID)
+		}
+		return fmt.Errorf("failed to get post: %v", err)
+	}
+
+	// the query matches any of the title's words, ranked by how many
+	related, err := s.db.GetRelatedPosts(ctx, database.GetRelatedPostsParams{
+		PostID:     post.ID,
+		UserID:     user.ID,
+		MaxResults: int32(*limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find related posts: %v", err)
+	}
+
+	if len(related) == 0 {
+		fmt.Printf("No posts related to %s\n", post.Title)
+		return nil
+	}
+
+	fmt.Printf("Related to %s:\n", post.Title)
+
+	stories := make([]story, 0, len(related))
+	for _, row := range related {
+		stories = append(stories, story{Row: database.GetPostsForUserRow{Post: row.Post, FeedName: row.FeedName}})
+	}
+	printStories(s, stories, userLocation(user), false)
+
+	return nil
+}
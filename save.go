@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/necodeus/gator/internal/config"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// readLaterService sends a post to one read-it-later service.
+type readLaterService struct {
+	name       string
+	configured func(cfg *config.Config) bool
+	save       func(ctx context.Context, s *state, post database.Post) error
+}
+
+var readLaterServices = []readLaterService{
+	{
+		name: "pocket",
+		configured: func(cfg *config.Config) bool {
+			return cfg.PocketConsumerKey != "" && cfg.PocketAccessToken != ""
+		},
+		save: saveToPocket,
+	},
+	{
+		name: "instapaper",
+		configured: func(cfg *config.Config) bool {
+			return cfg.InstapaperUsername != ""
+		},
+		save: saveToInstapaper,
+	},
+	{
+		name: "wallabag",
+		configured: func(cfg *config.Config) bool {
+			return cfg.WallabagURL != "" && cfg.WallabagClientID != "" && cfg.WallabagUsername != ""
+		},
+		save: saveToWallabag,
+	},
+}
+
+// handlerSave sends a post to a read-it-later service. --to can be left
+// out when only one service is configured.
+func handlerSave(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("save", flag.ContinueOnError)
+	to := fs.String("to", "", "service to save to: pocket, instapaper or wallabag")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("save command requires a post ID or a number from the last listing")
+	}
+
+	service, err := pickReadLaterService(s.Config, *to)
+	if err != nil {
+		return err
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	_, post, err := postForUser(dbCtx, s, fs.Arg(0))
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	saveCtx, cancel := context.WithTimeout(ctx, s.fetchTimeout)
+	defer cancel()
+	if err := service.save(saveCtx, s, post); err != nil {
+		return networkError(err, fmt.Errorf("failed to save to %s: %v", service.name, err))
+	}
+
+	infof("Saved %s to %s\n", post.Title, service.name)
+	return nil
+}
+
+func pickReadLaterService(cfg *config.Config, name string) (readLaterService, error) {
+	var configured []readLaterService
+	for _, service := range readLaterServices {
+		if name != "" && service.name == name {
+			if !service.configured(cfg) {
+				return service, fmt.Errorf("%s is not configured, add its credentials to the config", name)
+			}
+			return service, nil
+		}
+		if service.configured(cfg) {
+			configured = append(configured, service)
+		}
+	}
+
+	switch {
+	case name != "":
+		return readLaterService{}, usageErrorf("unknown service %s, expected pocket, instapaper or wallabag", name)
+	case len(configured) == 1:
+		return configured[0], nil
+	case len(configured) == 0:
+		return readLaterService{}, fmt.Errorf("no read-it-later service is configured")
+	default:
+		return readLaterService{}, fmt.Errorf("several services are configured, pick one with --to")
+	}
+}
+
+// postReadLater sends a request to a service API and turns any status
+// but 2xx into an error carrying the start of the response.
+func postReadLater(s *state, req *http.Request) (*http.Response, error) {
+	req.Header.Set("User-Agent", s.userAgent(nil))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("service answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// saveToPocket uses Pocket's v3 add endpoint, with an access token
+// obtained once through Pocket's OAuth flow.
+func saveToPocket(ctx context.Context, s *state, post database.Post) error {
+	body, err := json.Marshal(map[string]string{
+		"url":          post.Url,
+		"title":        post.Title,
+		"consumer_key": s.Config.PocketConsumerKey,
+		"access_token": s.Config.PocketAccessToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://getpocket.com/v3/add", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Accept", "application/json")
+
+	resp, err := postReadLater(s, req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// saveToInstapaper uses Instapaper's simple API, which takes the account
+// password directly; accounts without one send an empty password.
+func saveToInstapaper(ctx context.Context, s *state, post database.Post) error {
+	form := url.Values{
+		"url":   {post.Url},
+		"title": {post.Title},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://www.instapaper.com/api/add", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.Config.InstapaperUsername, s.Config.InstapaperPassword)
+
+	resp, err := postReadLater(s, req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// saveToWallabag logs in to a wallabag instance with the OAuth password
+// grant and creates an entry; the instance fetches the article itself.
+func saveToWallabag(ctx context.Context, s *state, post database.Post) error {
+	base := strings.TrimRight(s.Config.WallabagURL, "/")
+
+	token, err := wallabagToken(ctx, s, base)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{
+		"url":   {post.Url},
+		"title": {post.Title},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/api/entries.json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := postReadLater(s, req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func wallabagToken(ctx context.Context, s *state, base string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"password"},
+		"client_id":     {s.Config.WallabagClientID},
+		"client_secret": {s.Config.WallabagClientSecret},
+		"username":      {s.Config.WallabagUsername},
+		"password":      {s.Config.WallabagPassword},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/oauth/v2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := postReadLater(s, req)
+	if err != nil {
+		return "", fmt.Errorf("logging in: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil {
+		return "", fmt.Errorf("decoding token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("logging in: no access token in the response")
+	}
+	return token.AccessToken, nil
+}
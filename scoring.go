@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// scoringModel rates how relevant a post is when it's stored. A keyword
+// found in the title adds its full weight and one found only in the
+// description half of it, and the feed's priority is added on top.
+// Weights can be negative to bury topics. Recency is left to the query,
+// which takes a point off per day of age, so `browse --sort score` keeps
+// a post worth 3 ahead of three days of newer ones.
+type scoringModel struct {
+	keywords []scoredKeyword
+}
+
+type scoredKeyword struct {
+	pattern *regexp.Regexp
+	weight  float64
+}
+
+// loadScoringModel reads the keyword weights. Scoring is best effort: if
+// they can't be read, posts are stored with only their feed's priority.
+func loadScoringModel(ctx context.Context, s *state) scoringModel {
+	weights, err := s.db.GetKeywordWeights(ctx)
+	if err != nil {
+		fmt.Printf("Error loading keyword weights, scoring by feed priority only: %v\n", err)
+		return scoringModel{}
+	}
+	return newScoringModel(weights)
+}
+
+func newScoringModel(weights []database.KeywordWeight) scoringModel {
+	var model scoringModel
+	for _, w := range weights {
+		model.keywords = append(model.keywords, scoredKeyword{
+			pattern: regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(w.Keyword) + `\b`),
+			weight:  w.Weight,
+		})
+	}
+	return model
+}
+
+func (m scoringModel) score(feed database.Feed, post database.CreatePostParams) float64 {
+	score := float64(feed.Priority)
+	for _, kw := range m.keywords {
+		switch {
+		case kw.pattern.MatchString(post.Title):
+			score += kw.weight
+		case kw.pattern.MatchString(post.Description.String):
+			score += kw.weight / 2
+		}
+	}
+	return score
+}
+
+func handlerKeywords(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return listKeywords(ctx, s)
+	}
+
+	switch cmd.Args[0] {
+	case "set":
+		if len(cmd.Args) != 3 {
+			return usageErrorf("keywords set requires a keyword and a weight")
+		}
+		keyword := normalizeKeyword(cmd.Args[1])
+		if keyword == "" {
+			return fmt.Errorf("keyword must not be empty")
+		}
+		weight, err := strconv.ParseFloat(cmd.Args[2], 64)
+		if err != nil {
+			return fmt.Errorf("invalid weight %q", cmd.Args[2])
+		}
+		if err := s.db.SetKeywordWeight(ctx, database.SetKeywordWeightParams{
+			Keyword: keyword,
+			Weight:  weight,
+		}); err != nil {
+			return fmt.Errorf("failed to set keyword weight: %v", err)
+		}
+		infof("%s now weighs %g; posts stored from now on are scored with it\n", keyword, weight)
+		return nil
+
+	case "remove":
+		if len(cmd.Args) != 2 {
+			return usageErrorf("keywords remove requires a keyword")
+		}
+		keyword := normalizeKeyword(cmd.Args[1])
+		rows, err := s.db.DeleteKeywordWeight(ctx, keyword)
+		if err != nil {
+			return fmt.Errorf("failed to remove keyword: %v", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("keyword %s has no weight", keyword)
+		}
+		infof("Removed %s\n", keyword)
+		return nil
+
+	default:
+		return usageErrorf("unknown keywords subcommand: %s", cmd.Args[0])
+	}
+}
+
+func listKeywords(ctx context.Context, s *state) error {
+	weights, err := s.db.GetKeywordWeights(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get keyword weights: %v", err)
+	}
+	if len(weights) == 0 {
+		fmt.Println("No keyword weights set, add one with: keywords set <keyword> <weight>")
+		return nil
+	}
+
+	for _, w := range weights {
+		fmt.Printf("%+6g  %s\n", w.Weight, w.Keyword)
+	}
+	return nil
+}
+
+// normalizeKeyword collapses whitespace and case so "Go  Lang" and "go
+// lang" are the same keyword; matching ignores case anyway.
+func normalizeKeyword(keyword string) string {
+	return strings.ToLower(strings.Join(strings.Fields(keyword), " "))
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// maxScrapedPageSize caps the HTML read for a scrape feed.
+const maxScrapedPageSize = 10 << 20
+
+// scraperConfig turns an HTML page into feed items: Item selects one
+// element per post, and the other fields are read relative to it. A field
+// is a CSS selector, optionally followed by @attr to read an attribute
+// instead of the text; a bare @attr reads the item element itself. It is
+// stored as JSON in feeds.scraper, and a feed with one is a scrape feed.
+type scraperConfig struct {
+	Item        string `json:"item"`
+	Title       string `json:"title,omitempty"`
+	Link        string `json:"link,omitempty"`
+	Date        string `json:"date,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+const (
+	defaultScrapeTitle = "a"
+	defaultScrapeLink  = "a@href"
+)
+
+// scrapeDateLayouts are the date formats found on web pages, tried after
+// the feed formats in pubDateLayouts.
+var scrapeDateLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+type scraper struct {
+	item                     cssSelector
+	title, link, date, descr scrapeField
+}
+
+// scrapeField is a compiled field from a scraperConfig.
+type scrapeField struct {
+	set  bool
+	sel  cssSelector // nil means the item element itself
+	attr string      // "" means the element's text
+}
+
+func compileScrapeField(expr string) (scrapeField, error) {
+	expr = strings.TrimSpace(expr)
+	field := scrapeField{set: expr != ""}
+
+	if at := strings.LastIndexByte(expr, '@'); at >= 0 && !strings.ContainsAny(expr[at:], "]\"'") {
+		field.attr = strings.ToLower(strings.TrimSpace(expr[at+1:]))
+		expr = strings.TrimSpace(expr[:at])
+		if field.attr == "" {
+			return field, fmt.Errorf("missing attribute name after @")
+		}
+	}
+
+	if expr != "" {
+		sel, err := compileSelector(expr)
+		if err != nil {
+			return field, err
+		}
+		field.sel = sel
+	}
+
+	return field, nil
+}
+
+// value reads the field from item, or returns "" when it isn't there.
+func (f scrapeField) value(item *htmlNode) string {
+	if !f.set {
+		return ""
+	}
+
+	node := item
+	if f.sel != nil {
+		if node = item.query(f.sel); node == nil {
+			return ""
+		}
+	}
+
+	if f.attr != "" {
+		return strings.TrimSpace(node.attrs[f.attr])
+	}
+	return node.textContent()
+}
+
+func newScraper(cfg scraperConfig) (*scraper, error) {
+	if strings.TrimSpace(cfg.Item) == "" {
+		return nil, fmt.Errorf("a scraper needs an item selector")
+	}
+	if cfg.Title == "" {
+		cfg.Title = defaultScrapeTitle
+	}
+	if cfg.Link == "" {
+		cfg.Link = defaultScrapeLink
+	}
+
+	item, err := compileSelector(cfg.Item)
+	if err != nil {
+		return nil, err
+	}
+	sc := &scraper{item: item}
+
+	fields := []struct {
+		expr  string
+		field *scrapeField
+	}{
+		{cfg.Title, &sc.title},
+		{cfg.Link, &sc.link},
+		{cfg.Date, &sc.date},
+		{cfg.Description, &sc.descr},
+	}
+	for _, f := range fields {
+		if *f.field, err = compileScrapeField(f.expr); err != nil {
+			return nil, err
+		}
+	}
+
+	return sc, nil
+}
+
+// scraperFor returns the scraper stored for feed, or nil for a regular
+// feed.
+func scraperFor(feed database.Feed) (*scraper, error) {
+	if !feed.Scraper.Valid || feed.Scraper.String == "" {
+		return nil, nil
+	}
+
+	var cfg scraperConfig
+	if err := json.Unmarshal([]byte(feed.Scraper.String), &cfg); err != nil {
+		return nil, fmt.Errorf("scraper for %s is corrupt: %v", feed.Name, err)
+	}
+	return newScraper(cfg)
+}
+
+// scrape reads the HTML page at pageURL from r and builds a feed out of
+// it, keeping at most maxItems items (0 means no limit). Items without a
+// link are dropped, since a post is identified by its URL.
+func (sc *scraper) scrape(r io.Reader, pageURL string, maxItems int) (*RSSFeed, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxScrapedPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading page: %w", err)
+	}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page URL %s: %v", pageURL, err)
+	}
+
+	page := parseHTML(string(data))
+
+	var feed RSSFeed
+	feed.Channel.Link = pageURL
+	if title := page.find("title"); title != nil {
+		feed.Channel.Title = title.textContent()
+	}
+
+	for _, node := range page.queryAll(sc.item) {
+		if maxItems > 0 && len(feed.Channel.Item) >= maxItems {
+			break
+		}
+
+		link := sc.link.value(node)
+		if link == "" {
+			continue
+		}
+		if ref, err := url.Parse(link); err == nil {
+			link = base.ResolveReference(ref).String()
+		}
+
+		item := RSSItem{
+			Title:       sc.title.value(node),
+			Link:        link,
+			Description: sc.descr.value(node),
+		}
+		if item.Title == "" {
+			item.Title = link
+		}
+		if published, err := parseScrapedDate(sc.date.value(node)); err == nil {
+			item.PubDate = published.Format(time.RFC3339)
+		}
+
+		feed.Channel.Item = append(feed.Channel.Item, item)
+	}
+
+	if len(feed.Channel.Item) == 0 {
+		return nil, fmt.Errorf("no items matched %s", pageURL)
+	}
+
+	return &feed, nil
+}
+
+// parseScrapedDate reads a date off a page, which may be in any of the
+// feed formats or in one of the looser formats pages use.
+func parseScrapedDate(value string) (time.Time, error) {
+	if t, err := parsePubDate(value); err == nil {
+		return t, nil
+	}
+
+	value = strings.TrimSpace(value)
+	for _, layout := range scrapeDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date format: %q", value)
+}
+
+// fetchScrapedFeed is fetchFeed for scrape feeds.
+func fetchScrapedFeed(ctx context.Context, fetcher FeedFetcher, pageURL string, opts requestOptions, sc *scraper, maxItems int) (*RSSFeed, error) {
+	body, permanentURL, err := fetcher.Fetch(ctx, pageURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	feed, err := sc.scrape(body, pageURL, maxItems)
+	if err != nil {
+		return nil, err
+	}
+	feed.PermanentURL = permanentURL
+
+	return feed, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/necodeus/gator/internal/database"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// postScriptSteps bounds the work filter_script may do on one post, so a
+// runaway loop can't stall aggregation.
+const postScriptSteps = 1_000_000
+
+// postScript is the Starlark file named by filter_script. It defines
+// filter(post), which gets every incoming post as a dict with feed,
+// feed_url, title, url, description, author, published, relevance and
+// tags. Changes to title, url, description, author, relevance and tags
+// are kept; returning a false value other than None drops the post.
+type postScript struct {
+	filter starlark.Callable
+}
+
+// loadPostScript reads filter_script. Like scoring, filtering is best
+// effort: a script that doesn't load is reported and posts are stored
+// unfiltered. It returns nil when there is no script.
+func loadPostScript(s *state) *postScript {
+	if s.Config.FilterScript == "" {
+		return nil
+	}
+	script, err := compilePostScript(s.Config.FilterScript)
+	if err != nil {
+		fmt.Printf("Error loading filter_script, storing posts unfiltered: %v\n", err)
+		return nil
+	}
+	return script
+}
+
+func compilePostScript(path string) (*postScript, error) {
+	opts := &syntax.FileOptions{Set: true, While: true, TopLevelControl: true, GlobalReassign: true}
+	globals, err := starlark.ExecFileOptions(opts, newScriptThread(), path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	filter, ok := globals["filter"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("%s does not define filter(post)", path)
+	}
+	return &postScript{filter: filter}, nil
+}
+
+func newScriptThread() *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: "filter_script",
+		Print: func(_ *starlark.Thread, msg string) {
+			infof("filter_script: %s\n", msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(postScriptSteps)
+	return thread
+}
+
+// apply runs the script on post, from feed, rewriting it in place. It
+// reports whether to keep the post, and the tags the script gave it. A
+// script that fails leaves the post as it was.
+func (ps *postScript) apply(feed database.Feed, post *database.CreatePostParams) (keep bool, tags []string, err error) {
+	if ps == nil {
+		return true, nil, nil
+	}
+
+	published := starlark.Value(starlark.None)
+	if post.PublishedAt.Valid {
+		published = starlark.String(post.PublishedAt.Time.Format(time.RFC3339))
+	}
+	fields := starlark.NewDict(9)
+	for _, kv := range []struct {
+		key   string
+		value starlark.Value
+	}{
+		{"feed", starlark.String(feed.Name)},
+		{"feed_url", starlark.String(feed.Url)},
+		{"title", starlark.String(post.Title)},
+		{"url", starlark.String(post.Url)},
+		{"description", starlark.String(post.Description.String)},
+		{"author", starlark.String(post.Author.String)},
+		{"published", published},
+		{"relevance", starlark.Float(post.Relevance)},
+		{"tags", starlark.NewList(nil)},
+	} {
+		fields.SetKey(starlark.String(kv.key), kv.value)
+	}
+
+	result, err := starlark.Call(newScriptThread(), ps.filter, starlark.Tuple{fields}, nil)
+	if err != nil {
+		return true, nil, err
+	}
+	if result != starlark.None && !result.Truth() {
+		return false, nil, nil
+	}
+
+	get := func(key string) (starlark.Value, error) {
+		v, found, err := fields.Get(starlark.String(key))
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("filter removed post[%q]", key)
+		}
+		return v, nil
+	}
+	str := func(key string) (string, error) {
+		v, err := get(key)
+		if err != nil {
+			return "", err
+		}
+		s, ok := starlark.AsString(v)
+		if !ok {
+			return "", fmt.Errorf("post[%q] must be a string, not %s", key, v.Type())
+		}
+		return s, nil
+	}
+
+	rewritten := *post
+	if rewritten.Title, err = str("title"); err != nil {
+		return true, nil, err
+	}
+	if rewritten.Url, err = str("url"); err != nil {
+		return true, nil, err
+	}
+	if rewritten.Description.String, err = str("description"); err != nil {
+		return true, nil, err
+	}
+	if rewritten.Author.String, err = str("author"); err != nil {
+		return true, nil, err
+	}
+	relevance, err := get("relevance")
+	if err != nil {
+		return true, nil, err
+	}
+	var ok bool
+	if rewritten.Relevance, ok = starlark.AsFloat(relevance); !ok {
+		return true, nil, fmt.Errorf(`post["relevance"] must be a number, not %s`, relevance.Type())
+	}
+	tagList, err := get("tags")
+	if err != nil {
+		return true, nil, err
+	}
+	iterable, ok := tagList.(starlark.Iterable)
+	if !ok {
+		return true, nil, fmt.Errorf(`post["tags"] must be a list, not %s`, tagList.Type())
+	}
+	var tag starlark.Value
+	iter := iterable.Iterate()
+	defer iter.Done()
+	for iter.Next(&tag) {
+		name, ok := starlark.AsString(tag)
+		if !ok {
+			return true, nil, fmt.Errorf(`post["tags"] must hold strings, not %s`, tag.Type())
+		}
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(tags, name) {
+			tags = append(tags, name)
+		}
+	}
+
+	// derived columns follow what they are derived from
+	if rewritten.Url == "" {
+		return true, nil, fmt.Errorf(`post["url"] must not be empty`)
+	}
+	if rewritten.Url != post.Url {
+		rewritten.CanonicalUrl.String, rewritten.CanonicalUrl.Valid = canonicalPostURL(rewritten.Url), true
+	}
+	if rewritten.Title != post.Title {
+		hash := titleHash(rewritten.Title)
+		rewritten.TitleHash.String, rewritten.TitleHash.Valid = hash, hash != ""
+	}
+	rewritten.Description.Valid = rewritten.Description.String != ""
+	rewritten.Author.Valid = rewritten.Author.String != ""
+
+	*post = rewritten
+	return true, tags, nil
+}
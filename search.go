@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+const defaultSearchLimit = 20
+
+// handlerSearch lists followed posts whose titles match the search terms,
+// best matches first. The terms take web search syntax: "quoted phrases",
+// or, and -excluded words. --feed, --tag, --starred and --unread narrow
+// the search down to part of what the user follows. Searches can be
+// saved under a name with their filters and run again later.
+func handlerSearch(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) > 0 {
+		switch cmd.Args[0] {
+		case "save":
+			return searchSave(ctx, s, cmd.Args[1:])
+		case "run":
+			return searchRun(ctx, s, cmd.Args[1:])
+		case "list":
+			return searchList(ctx, s, cmd.Args[1:])
+		case "delete":
+			return searchDelete(ctx, s, cmd.Args[1:])
+		}
+	}
+
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	filters := addSearchFilters(fs)
+	limit := fs.Int("limit", defaultSearchLimit, "maximum number of posts to show")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	terms := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if terms == "" {
+		return usageErrorf("search command requires search terms")
+	}
+	if *limit <= 0 {
+		return usageErrorf("limit must be a positive number")
+	}
+
+	search, err := filters.resolve(ctx, s, terms)
+	if err != nil {
+		return err
+	}
+
+	// findFeed may have waited on the user, so the timeout starts here
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+	return showSearch(ctx, s, user, search, *limit)
+}
+
+// searchFilters are the flags that narrow a search, shared by search and
+// search save.
+type searchFilters struct {
+	feed    *string
+	tag     *string
+	starred *bool
+	unread  *bool
+}
+
+func addSearchFilters(fs *flag.FlagSet) searchFilters {
+	return searchFilters{
+		feed:    fs.String("feed", "", "only search posts from this feed"),
+		tag:     fs.String("tag", "", "only search posts with this tag, or from feeds with it"),
+		starred: fs.Bool("starred", false, "only search starred posts"),
+		unread:  fs.Bool("unread", false, "only search posts not marked as read"),
+	}
+}
+
+// resolve looks up the --feed the user gave, so the search refers to the
+// feed itself rather than whatever the name matches next time.
+func (f searchFilters) resolve(ctx context.Context, s *state, terms string) (database.SavedSearch, error) {
+	search := database.SavedSearch{
+		Terms:   terms,
+		Tag:     sql.NullString{String: *f.tag, Valid: *f.tag != ""},
+		Starred: *f.starred,
+		Unread:  *f.unread,
+	}
+	feedID, err := feedFilter(ctx, s, *f.feed)
+	if err != nil {
+		return search, err
+	}
+	search.FeedID = feedID
+	return search, nil
+}
+
+func showSearch(ctx context.Context, s *state, user database.User, search database.SavedSearch, limit int) error {
+	found, err := s.db.SearchPosts(ctx, database.SearchPostsParams{
+		Terms:      search.Terms,
+		UserID:     user.ID,
+		FeedID:     search.FeedID,
+		Tag:        search.Tag,
+		Starred:    search.Starred,
+		Unread:     search.Unread,
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to search posts: %v", err)
+	}
+
+	if len(found) == 0 {
+		fmt.Printf("No posts match %s\n", search.Terms)
+		return nil
+	}
+
+	stories := make([]story, 0, len(found))
+	for _, row := range found {
+		stories = append(stories, story{Row: database.GetPostsForUserRow{Post: row.Post, FeedName: row.FeedName}})
+	}
+	printStories(s, stories, userLocation(user), false)
+
+	return nil
+}
+
+// normalizeSearchName lowercases the name of a saved search and checks it
+// is a single word, so it is typed the same way every time.
+func normalizeSearchName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", usageErrorf("search name must not be empty")
+	}
+	if strings.ContainsFunc(name, unicode.IsSpace) {
+		return "", usageErrorf("search name %s must be a single word", name)
+	}
+	return name, nil
+}
+
+// searchSave stores a search under a name, replacing any search saved
+// under it before.
+func searchSave(ctx context.Context, s *state, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("search save requires a name and search terms")
+	}
+	name, err := normalizeSearchName(args[0])
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("search save", flag.ContinueOnError)
+	filters := addSearchFilters(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return usageError(err)
+	}
+	terms := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if terms == "" {
+		return usageErrorf("search save requires search terms")
+	}
+
+	search, err := filters.resolve(ctx, s, terms)
+	if err != nil {
+		return err
+	}
+
+	// findFeed may have waited on the user, so the timeout starts here
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.SaveSearch(ctx, database.SaveSearchParams{
+		UserID:  user.ID,
+		Name:    name,
+		Terms:   search.Terms,
+		FeedID:  search.FeedID,
+		Tag:     search.Tag,
+		Starred: search.Starred,
+		Unread:  search.Unread,
+	}); err != nil {
+		return fmt.Errorf("failed to save search: %v", err)
+	}
+
+	infof("Saved search %s, run it with search run %s\n", name, name)
+	return nil
+}
+
+func searchRun(ctx context.Context, s *state, args []string) error {
+	fs := flag.NewFlagSet("search run", flag.ContinueOnError)
+	limit := fs.Int("limit", defaultSearchLimit, "maximum number of posts to show")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		return usageErrorf("search run requires the name of a saved search")
+	}
+	if *limit <= 0 {
+		return usageErrorf("limit must be a positive number")
+	}
+	name, err := normalizeSearchName(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	search, err := s.db.GetSavedSearch(ctx, database.GetSavedSearchParams{UserID: user.ID, Name: name})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundErrorf("saved search %s does not exist", name)
+		}
+		return fmt.Errorf("failed to get saved search: %v", err)
+	}
+	return showSearch(ctx, s, user, search, *limit)
+}
+
+func searchList(ctx context.Context, s *state, args []string) error {
+	if len(args) > 0 {
+		return usageErrorf("search list takes no arguments")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	searches, err := s.db.GetSavedSearchesForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get saved searches: %v", err)
+	}
+	if len(searches) == 0 {
+		fmt.Println("No saved searches, add one with search save <name> <terms>")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, row := range searches {
+		search := row.SavedSearch
+		var scope []string
+		if row.FeedName.Valid {
+			scope = append(scope, "feed "+row.FeedName.String)
+		}
+		if search.Tag.Valid {
+			scope = append(scope, "tag "+search.Tag.String)
+		}
+		if search.Starred {
+			scope = append(scope, "starred")
+		}
+		if search.Unread {
+			scope = append(scope, "unread")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", search.Name, search.Terms, strings.Join(scope, ", "))
+	}
+	return w.Flush()
+}
+
+func searchDelete(ctx context.Context, s *state, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("search delete requires the name of a saved search")
+	}
+	name, err := normalizeSearchName(args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.db.DeleteSavedSearch(ctx, database.DeleteSavedSearchParams{UserID: user.ID, Name: name})
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %v", err)
+	}
+	if deleted == 0 {
+		return notFoundErrorf("saved search %s does not exist", name)
+	}
+
+	infof("Deleted saved search %s\n", name)
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+func handlerServe(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	opts := serveOptions{}
+	fs.StringVar(&opts.addr, "addr", "localhost:8080", "address to listen on")
+	fs.StringVar(&opts.publicURL, "public-url", "", "URL this server is reachable on from the internet; enables WebSub")
+	fs.BoolVar(&opts.multiUser, "multi-user", false, "let every user with a password log in, instead of serving the current user")
+	fs.StringVar(&opts.grpcAddr, "grpc", "", "also serve the gRPC API on this address")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+
+	return serveFeed(ctx, s, opts)
+}
+
+type serveOptions struct {
+	addr      string
+	publicURL string
+	multiUser bool
+	grpcAddr  string
+}
+
+// serveFeed serves the followed-posts feed on addr until ctx is cancelled.
+// With a publicURL it also subscribes to the WebSub hubs of stored feeds
+// and takes their pushes. In multi-user mode every request is answered for
+// the user it authenticates as, and the web UI and API are served too.
+// With a grpcAddr the gRPC API runs alongside; either server failing stops
+// both.
+func serveFeed(ctx context.Context, s *state, opts serveOptions) error {
+	addr, publicURL, multiUser := opts.addr, opts.publicURL, opts.multiUser
+
+	corsHandler, err := newCORS(s.Config)
+	if err != nil {
+		return &exitCodeError{exitConfig, err}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	grpcErr := make(chan error, 1)
+	if opts.grpcAddr != "" {
+		go func() {
+			err := serveGRPC(ctx, s, opts.grpcAddr, multiUser)
+			cancel()
+			grpcErr <- err
+		}()
+	}
+
+	mux := http.NewServeMux()
+	registerProbeHandlers(mux, s)
+	registerWebSubHandlers(mux, s)
+	if multiUser {
+		registerMultiUserHandlers(mux, s)
+	}
+	mux.HandleFunc("GET /feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultPublishLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), s.dbTimeout)
+		defer cancel()
+
+		var user database.User
+		var posts []database.GetPostsForUserRow
+		var err error
+		if multiUser {
+			user, err = requestUser(ctx, s, r)
+			if err == errBadLogin {
+				w.Header().Set("WWW-Authenticate", `Basic realm="gator", charset="UTF-8"`)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			if err == nil && !limitUser(w, r, user) {
+				return
+			}
+			if err == nil {
+				posts, err = followedPostsFor(ctx, s, user, limit)
+			}
+		} else {
+			user, posts, err = followedPosts(ctx, s, limit)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		if err := writeOutputFeed(w, user, posts); err != nil {
+			fmt.Printf("Error writing feed: %v\n", err)
+		}
+	})
+
+	var handler http.Handler = mux
+	var limiter *rateLimiter
+	if rate := s.Config.APIRateLimitOrDefault(); rate > 0 {
+		limiter = newRateLimiter(rate, s.Config.APIRateBurstOrDefault())
+		handler = limiter.limit(mux)
+	}
+	mux.HandleFunc("GET /metrics", writeMetrics(s, limiter))
+	// preflights are answered before the rate limit, and 429s carry CORS
+	// headers so pages can read them
+	if corsHandler != nil {
+		handler = corsHandler.handle(handler)
+	}
+
+	server := &http.Server{
+		Addr:        addr,
+		Handler:     handler,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	infof("Serving feed on http://%s/feed.xml\n", addr)
+	if multiUser {
+		infof("Multi-user mode: log in on http://%s/login\n", addr)
+	}
+
+	if publicURL != "" {
+		go runWebSubSubscriber(ctx, s, publicURL)
+	}
+
+	err = server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	if opts.grpcAddr != "" {
+		cancel()
+		if grpcErr := <-grpcErr; err == nil {
+			err = grpcErr
+		}
+	}
+
+	return err
+}
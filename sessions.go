@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+const (
+	// passwordIterations is the PBKDF2 work factor for new passwords;
+	// stored hashes carry their own, so it can be raised later.
+	passwordIterations = 600000
+	minPasswordLength  = 8
+
+	sessionCookie = "gator_session"
+	sessionTTL    = 30 * 24 * time.Hour
+)
+
+var errBadLogin = errors.New("wrong user name or password")
+
+func handlerPasswd(ctx context.Context, s *state, cmd command) error {
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	password, err := readSecret("New password")
+	if err != nil {
+		return err
+	}
+	if stdinIsTerminal() {
+		again, err := readSecret("Repeat password")
+		if err != nil {
+			return err
+		}
+		if again != password {
+			return fmt.Errorf("passwords don't match")
+		}
+	}
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	encoded, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	err = s.withTx(ctx, func(tx *state) error {
+		if err := tx.db.SetUserPassword(ctx, database.SetUserPasswordParams{
+			ID:           user.ID,
+			PasswordHash: sql.NullString{String: encoded, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to set password: %v", err)
+		}
+		// a new password signs out every browser and client
+		if err := tx.db.DeleteUserSessions(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to end sessions: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	infof("Password set for %s\n", user.Name)
+	return nil
+}
+
+// hashPassword encodes password as "pbkdf2-sha256$<iterations>$<salt>$<key>"
+// with a fresh random salt.
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %v", err)
+	}
+
+	key := pbkdf2(sha256.New, []byte(password), salt, passwordIterations, sha256.Size)
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// checkPassword reports whether password matches a hashPassword result.
+func checkPassword(encoded, password string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	got := pbkdf2(sha256.New, []byte(password), salt, iterations, len(want))
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// pbkdf2 derives a key as in RFC 8018, section 5.2.
+func pbkdf2(newHash func() hash.Hash, password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(newHash, password)
+	size := prf.Size()
+
+	var key []byte
+	block := make([]byte, 4)
+	for i := 1; len(key) < keyLen; i++ {
+		binary.BigEndian.PutUint32(block, uint32(i))
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(block)
+		u := prf.Sum(nil)
+
+		t := make([]byte, size)
+		copy(t, u)
+		for n := 1; n < iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+
+	return key[:keyLen]
+}
+
+// authenticate checks a user name and password. Users who never set a
+// password can't log in.
+func authenticate(ctx context.Context, s *state, name, password string) (database.User, error) {
+	users, err := s.db.GetUsersByName(ctx, name)
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to get user: %v", err)
+	}
+	if len(users) == 0 || !users[0].PasswordHash.Valid || !checkPassword(users[0].PasswordHash.String, password) {
+		return database.User{}, errBadLogin
+	}
+	return users[0], nil
+}
+
+// newSession starts a session for user and returns its token, which is
+// only ever stored hashed.
+func newSession(ctx context.Context, s *state, user database.User) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate session token: %v", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	expires := time.Now().UTC().Add(sessionTTL)
+
+	if _, err := s.db.DeleteExpiredSessions(ctx); err != nil {
+		fmt.Printf("Error deleting expired sessions: %v\n", err)
+	}
+	if err := s.db.CreateSession(ctx, database.CreateSessionParams{
+		TokenHash: hashSessionToken(token),
+		UserID:    user.ID,
+		ExpiresAt: expires,
+	}); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create session: %v", err)
+	}
+
+	return token, expires, nil
+}
+
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionToken returns the session token a request carries, in the
+// session cookie or as a bearer token.
+func sessionToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// sessionUser returns the owner of the session token, or errBadLogin when
+// the session doesn't exist or has expired.
+func sessionUser(ctx context.Context, s *state, token string) (database.User, error) {
+	user, err := s.db.GetSessionUser(ctx, hashSessionToken(token))
+	if err == sql.ErrNoRows {
+		return database.User{}, errBadLogin
+	}
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to get session: %v", err)
+	}
+	return user, nil
+}
+
+// requestUser returns who made a request: the owner of its session, or,
+// for feed readers that can't keep one, the user named by HTTP basic
+// auth. It returns errBadLogin when the request isn't authenticated.
+func requestUser(ctx context.Context, s *state, r *http.Request) (database.User, error) {
+	if token := sessionToken(r); token != "" {
+		return sessionUser(ctx, s, token)
+	}
+
+	if name, password, ok := r.BasicAuth(); ok {
+		return authenticate(ctx, s, name, password)
+	}
+
+	return database.User{}, errBadLogin
+}
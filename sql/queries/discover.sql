@@ -0,0 +1,18 @@
+-- name: GetPopularFeeds :many
+SELECT sqlc.embed(feeds), COUNT(feed_follows.id) AS followers
+FROM feeds
+LEFT JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE NOT EXISTS (
+        SELECT 1 FROM feed_follows AS mine
+        WHERE mine.feed_id = feeds.id AND mine.user_id = sqlc.arg(user_id)
+    )
+    -- a topic matches a feed's name or any user's tag for it
+    AND (sqlc.narg(topic)::text IS NULL
+        OR feeds.name ILIKE '%' || sqlc.narg(topic) || '%'
+        OR EXISTS (
+            SELECT 1 FROM feed_tags
+            WHERE feed_tags.feed_id = feeds.id AND LOWER(feed_tags.tag) = LOWER(sqlc.narg(topic))
+        ))
+GROUP BY feeds.id
+ORDER BY followers DESC, feeds.name
+LIMIT sqlc.arg(max_feeds);
@@ -0,0 +1,119 @@
+-- name: DumpFeedFollows :many
+SELECT * FROM feed_follows
+ORDER BY created_at;
+
+-- name: DumpFeedTags :many
+SELECT * FROM feed_tags;
+
+-- name: DumpPostTags :many
+SELECT * FROM post_tags;
+
+-- name: DumpPosts :many
+SELECT * FROM posts
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: DumpPostReads :many
+SELECT * FROM post_reads
+WHERE (user_id, post_id) > (sqlc.arg(after_user_id)::uuid, sqlc.arg(after_post_id)::uuid)
+ORDER BY user_id, post_id
+LIMIT sqlc.arg(max_rows);
+
+-- name: DumpPostStars :many
+SELECT * FROM post_stars;
+
+-- name: DumpPostNotes :many
+SELECT * FROM post_notes;
+
+-- name: DumpPreferences :many
+SELECT * FROM user_preferences;
+
+-- name: RestoreUser :exec
+INSERT INTO users (id, created_at, updated_at, name, timezone, last_seen_at, password_hash, is_admin)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: RestoreFeed :exec
+INSERT INTO feeds (
+    id, created_at, updated_at, name, url, user_id, author, image_url, user_agent, schedule,
+    last_fetched_at, poll_interval_seconds, skip_hours, skip_days, scraper, priority, paused_at, tier,
+    shared
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19);
+
+-- name: RestoreFeedFollow :exec
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: RestorePost :exec
+INSERT INTO posts (
+    id, created_at, updated_at, title, url, description, published_at, feed_id,
+    enclosure_url, enclosure_type, enclosure_length, author, image_url,
+    duration_seconds, episode, season, thumbnail_url, canonical_url, title_hash,
+    score, comment_count, relevance, guid, edited_at
+)
+VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+    $18, $19, $20, $21, $22, $23, $24
+);
+
+-- name: RestorePostRead :exec
+INSERT INTO post_reads (user_id, post_id, read_at)
+VALUES ($1, $2, $3);
+
+-- name: RestorePostStar :exec
+INSERT INTO post_stars (user_id, post_id, starred_at)
+VALUES ($1, $2, $3);
+
+-- name: RestorePostNote :exec
+INSERT INTO post_notes (user_id, post_id, note, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: RestorePreference :exec
+INSERT INTO user_preferences (user_id, key, value, updated_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: DumpFeedAliases :many
+SELECT * FROM feed_aliases;
+
+-- name: RestoreFeedAlias :exec
+INSERT INTO feed_aliases (user_id, alias, feed_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DumpSavedSearches :many
+SELECT * FROM saved_searches;
+
+-- name: RestoreSavedSearch :exec
+INSERT INTO saved_searches (user_id, name, terms, feed_id, tag, starred, unread, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DumpFolders :many
+SELECT * FROM folders;
+
+-- name: RestoreFolder :exec
+INSERT INTO folders (user_id, name, keywords, tags, authors, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+ON CONFLICT DO NOTHING;
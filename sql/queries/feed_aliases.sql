@@ -0,0 +1,25 @@
+-- name: AddFeedAlias :execrows
+INSERT INTO feed_aliases (user_id, alias, feed_id)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteFeedAlias :execrows
+DELETE FROM feed_aliases
+WHERE user_id = $1 AND alias = $2;
+
+-- name: GetFeedAliasesForUser :many
+SELECT feed_aliases.alias, feeds.name AS feed_name, feeds.url AS feed_url
+FROM feed_aliases
+JOIN feeds ON feeds.id = feed_aliases.feed_id
+WHERE feed_aliases.user_id = $1
+ORDER BY feed_aliases.alias;
+
+-- name: GetFeedByAlias :one
+SELECT feeds.*
+FROM feed_aliases
+JOIN feeds ON feeds.id = feed_aliases.feed_id
+WHERE feed_aliases.user_id = $1 AND feed_aliases.alias = $2;
@@ -0,0 +1,47 @@
+-- name: CreateFeedFollow :one
+INSERT INTO feed_follows (id, user_id, feed_id)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+RETURNING *;
+
+-- name: GetFeedFollowsForUser :many
+SELECT feed_follows.*, feeds.name AS feed_name, feeds.url AS feed_url
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name;
+
+-- name: DeleteFeedFollow :execrows
+DELETE FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: CreateFeedFollowIfMissing :execrows
+INSERT INTO feed_follows (id, user_id, feed_id)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id, feed_id) DO NOTHING;
+
+-- name: IsFollowingFeed :one
+SELECT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE user_id = $1 AND feed_id = $2
+);
+
+-- name: FollowFeedForAllUsers :execrows
+INSERT INTO feed_follows (user_id, feed_id)
+SELECT users.id, $1
+FROM users
+ON CONFLICT (user_id, feed_id) DO NOTHING;
+
+-- name: FollowSharedFeeds :execrows
+INSERT INTO feed_follows (user_id, feed_id)
+SELECT $1, feeds.id
+FROM feeds
+WHERE feeds.shared
+ON CONFLICT (user_id, feed_id) DO NOTHING;
@@ -0,0 +1,19 @@
+-- name: SetFeedHeader :exec
+INSERT INTO feed_headers (feed_id, name, value)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (feed_id, name) DO UPDATE
+SET value = EXCLUDED.value;
+
+-- name: DeleteFeedHeader :execrows
+DELETE FROM feed_headers
+WHERE feed_id = $1 AND name = $2;
+
+-- name: GetFeedHeaders :many
+SELECT *
+FROM feed_headers
+WHERE feed_id = $1
+ORDER BY name;
@@ -0,0 +1,19 @@
+-- name: RecordFeedOwnerChange :exec
+INSERT INTO feed_owner_changes (feed_id, from_user, to_user, changed_by)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+);
+
+-- name: RecordUserFeedsOwnerChange :exec
+INSERT INTO feed_owner_changes (feed_id, from_user, to_user, changed_by)
+SELECT feeds.id, sqlc.arg(from_user)::text, sqlc.arg(to_user)::text, sqlc.arg(changed_by)::text
+FROM feeds
+WHERE feeds.user_id = sqlc.arg(from_user_id);
+
+-- name: GetFeedOwnerChanges :many
+SELECT * FROM feed_owner_changes
+WHERE feed_id = $1
+ORDER BY changed_at, id;
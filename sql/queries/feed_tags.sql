@@ -0,0 +1,14 @@
+-- name: AddFeedTag :exec
+INSERT INTO feed_tags (user_id, feed_id, tag)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT DO NOTHING;
+
+-- name: GetFeedTagsForUser :many
+SELECT *
+FROM feed_tags
+WHERE user_id = $1
+ORDER BY tag;
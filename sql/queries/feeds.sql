@@ -16,3 +16,104 @@ WHERE name = $1;
 -- name: GetFeeds :many
 SELECT *
 FROM feeds;
+
+-- name: GetFeedsWithFollowers :many
+SELECT sqlc.embed(feeds), COUNT(feed_follows.id) AS followers
+FROM feeds
+LEFT JOIN feed_follows ON feed_follows.feed_id = feeds.id
+GROUP BY feeds.id
+ORDER BY feeds.created_at;
+
+-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET author = $2, image_url = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetFeedByUrl :one
+SELECT *
+FROM feeds
+WHERE url = $1;
+
+-- name: SetFeedUserAgent :exec
+UPDATE feeds
+SET user_agent = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateFeedUrl :exec
+UPDATE feeds
+SET url = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedSchedule :exec
+UPDATE feeds
+SET schedule = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkFeedFetched :exec
+UPDATE feeds
+SET last_fetched_at = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedContentHash :exec
+UPDATE feeds
+SET content_hash = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateFeedFetchHints :exec
+UPDATE feeds
+SET poll_interval_seconds = $2, skip_hours = $3, skip_days = $4, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetFeedById :one
+SELECT *
+FROM feeds
+WHERE id = $1;
+
+-- name: UpdateFeedWebSub :exec
+UPDATE feeds
+SET websub_hub = $2, websub_topic = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedAuth :exec
+UPDATE feeds
+SET auth = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedScraper :exec
+UPDATE feeds
+SET scraper = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedPriority :exec
+UPDATE feeds
+SET priority = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedTier :exec
+UPDATE feeds
+SET tier = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: PauseFeed :execrows
+UPDATE feeds
+SET paused_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND paused_at IS NULL;
+
+-- name: ResumeFeed :execrows
+UPDATE feeds
+SET paused_at = NULL, updated_at = NOW()
+WHERE id = $1 AND paused_at IS NOT NULL;
+
+-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE id = $1;
+
+-- name: SetFeedOwner :exec
+UPDATE feeds
+SET user_id = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedShared :execrows
+UPDATE feeds
+SET shared = $2, updated_at = NOW()
+WHERE id = $1 AND shared <> $2;
@@ -0,0 +1,35 @@
+-- name: RecordFetch :exec
+INSERT INTO fetch_log (feed_id, status_code, duration_ms, items_seen, new_posts, error, bytes)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+);
+
+-- name: GetFetchLogForFeed :many
+SELECT * FROM fetch_log
+WHERE feed_id = $1
+ORDER BY fetched_at DESC
+LIMIT $2;
+
+-- name: DeleteFetchLogBefore :execrows
+DELETE FROM fetch_log
+WHERE fetched_at < $1;
+
+-- name: GetFetchStatsSince :many
+SELECT feeds.name AS feed_name,
+    COUNT(*) AS fetches,
+    COUNT(*) FILTER (WHERE fetch_log.error IS NOT NULL) AS errors,
+    COALESCE(SUM(fetch_log.duration_ms), 0)::bigint AS duration_ms,
+    COALESCE(MAX(fetch_log.duration_ms), 0)::int AS max_duration_ms,
+    COALESCE(SUM(fetch_log.bytes), 0)::bigint AS bytes,
+    COALESCE(SUM(fetch_log.new_posts), 0)::bigint AS new_posts
+FROM fetch_log
+JOIN feeds ON feeds.id = fetch_log.feed_id
+WHERE fetch_log.fetched_at >= sqlc.arg(since)
+GROUP BY feeds.id, feeds.name
+ORDER BY feeds.name;
@@ -0,0 +1,26 @@
+-- name: SaveFolder :exec
+INSERT INTO folders (user_id, name, keywords, tags, authors)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (user_id, name) DO UPDATE
+SET keywords = EXCLUDED.keywords,
+    tags = EXCLUDED.tags,
+    authors = EXCLUDED.authors;
+
+-- name: GetFoldersForUser :many
+SELECT * FROM folders
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: GetFolder :one
+SELECT * FROM folders
+WHERE user_id = $1 AND name = $2;
+
+-- name: DeleteFolder :execrows
+DELETE FROM folders
+WHERE user_id = $1 AND name = $2;
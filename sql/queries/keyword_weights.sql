@@ -0,0 +1,17 @@
+-- name: SetKeywordWeight :exec
+INSERT INTO keyword_weights (keyword, weight)
+VALUES (
+    $1,
+    $2
+)
+ON CONFLICT (keyword) DO UPDATE
+SET weight = EXCLUDED.weight;
+
+-- name: DeleteKeywordWeight :execrows
+DELETE FROM keyword_weights
+WHERE keyword = $1;
+
+-- name: GetKeywordWeights :many
+SELECT *
+FROM keyword_weights
+ORDER BY weight DESC, keyword;
@@ -0,0 +1,78 @@
+-- name: MoveUserFeeds :execrows
+UPDATE feeds
+SET user_id = sqlc.arg(to_user_id), updated_at = NOW()
+WHERE user_id = sqlc.arg(from_user_id);
+
+-- name: MoveUserFollows :execrows
+UPDATE feed_follows
+SET user_id = sqlc.arg(to_user_id), updated_at = NOW()
+WHERE feed_follows.user_id = sqlc.arg(from_user_id)
+    AND NOT EXISTS (
+        SELECT 1 FROM feed_follows AS kept
+        WHERE kept.user_id = sqlc.arg(to_user_id) AND kept.feed_id = feed_follows.feed_id
+    );
+
+-- name: MoveUserReads :execrows
+UPDATE post_reads
+SET user_id = sqlc.arg(to_user_id)
+WHERE post_reads.user_id = sqlc.arg(from_user_id)
+    AND NOT EXISTS (
+        SELECT 1 FROM post_reads AS kept
+        WHERE kept.user_id = sqlc.arg(to_user_id) AND kept.post_id = post_reads.post_id
+    );
+
+-- name: MoveUserStars :execrows
+UPDATE post_stars
+SET user_id = sqlc.arg(to_user_id)
+WHERE post_stars.user_id = sqlc.arg(from_user_id)
+    AND NOT EXISTS (
+        SELECT 1 FROM post_stars AS kept
+        WHERE kept.user_id = sqlc.arg(to_user_id) AND kept.post_id = post_stars.post_id
+    );
+
+-- name: MoveUserNotes :execrows
+UPDATE post_notes
+SET user_id = sqlc.arg(to_user_id)
+WHERE post_notes.user_id = sqlc.arg(from_user_id)
+    AND NOT EXISTS (
+        SELECT 1 FROM post_notes AS kept
+        WHERE kept.user_id = sqlc.arg(to_user_id) AND kept.post_id = post_notes.post_id
+    );
+
+-- name: MoveUserFeedTags :execrows
+UPDATE feed_tags
+SET user_id = sqlc.arg(to_user_id)
+WHERE feed_tags.user_id = sqlc.arg(from_user_id)
+    AND NOT EXISTS (
+        SELECT 1 FROM feed_tags AS kept
+        WHERE kept.user_id = sqlc.arg(to_user_id)
+            AND kept.feed_id = feed_tags.feed_id
+            AND kept.tag = feed_tags.tag
+    );
+
+-- name: MoveUserFeedAliases :execrows
+UPDATE feed_aliases
+SET user_id = sqlc.arg(to_user_id)
+WHERE feed_aliases.user_id = sqlc.arg(from_user_id)
+    AND NOT EXISTS (
+        SELECT 1 FROM feed_aliases AS kept
+        WHERE kept.user_id = sqlc.arg(to_user_id) AND kept.alias = feed_aliases.alias
+    );
+
+-- name: MoveUserSavedSearches :execrows
+UPDATE saved_searches
+SET user_id = sqlc.arg(to_user_id)
+WHERE saved_searches.user_id = sqlc.arg(from_user_id)
+    AND NOT EXISTS (
+        SELECT 1 FROM saved_searches AS kept
+        WHERE kept.user_id = sqlc.arg(to_user_id) AND kept.name = saved_searches.name
+    );
+
+-- name: MoveUserFolders :execrows
+UPDATE folders
+SET user_id = sqlc.arg(to_user_id)
+WHERE folders.user_id = sqlc.arg(from_user_id)
+    AND NOT EXISTS (
+        SELECT 1 FROM folders AS kept
+        WHERE kept.user_id = sqlc.arg(to_user_id) AND kept.name = folders.name
+    );
@@ -0,0 +1,17 @@
+-- name: SetPostNote :exec
+INSERT INTO post_notes (user_id, post_id, note)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET note = EXCLUDED.note, updated_at = NOW();
+
+-- name: GetPostNote :one
+SELECT * FROM post_notes
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: DeletePostNote :execrows
+DELETE FROM post_notes
+WHERE user_id = $1 AND post_id = $2;
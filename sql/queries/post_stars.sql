@@ -0,0 +1,20 @@
+-- name: StarPost :exec
+INSERT INTO post_stars (user_id, post_id)
+VALUES (
+    $1,
+    $2
+)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: UnstarPost :execrows
+DELETE FROM post_stars
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetStarredPosts :many
+SELECT sqlc.embed(posts), feeds.name AS feed_name, post_notes.note, post_stars.starred_at
+FROM post_stars
+JOIN posts ON posts.id = post_stars.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_notes ON post_notes.user_id = post_stars.user_id AND post_notes.post_id = post_stars.post_id
+WHERE post_stars.user_id = $1
+ORDER BY post_stars.starred_at;
@@ -0,0 +1,10 @@
+-- name: AddPostTags :exec
+INSERT INTO post_tags (post_id, tag)
+SELECT sqlc.arg(post_id)::uuid, unnest(sqlc.arg(tags)::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: GetPostTags :many
+SELECT *
+FROM post_tags
+WHERE post_id = ANY(sqlc.arg(post_ids)::uuid[])
+ORDER BY post_id, tag;
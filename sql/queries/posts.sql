@@ -0,0 +1,250 @@
+-- name: CreatePost :one
+INSERT INTO posts (id, feed_id, title, url, description, published_at, enclosure_url, enclosure_type, enclosure_length, author, image_url, duration_seconds, episode, season, thumbnail_url, canonical_url, title_hash, score, comment_count, relevance, guid)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12,
+    $13,
+    $14,
+    $15,
+    $16,
+    $17,
+    $18,
+    $19,
+    $20,
+    $21
+)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: GetPostById :one
+SELECT * FROM posts
+WHERE id = $1;
+
+-- name: GetPostsForUser :many
+SELECT sqlc.embed(posts), feeds.name AS feed_name, post_notes.note
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+LEFT JOIN post_notes ON post_notes.user_id = feed_follows.user_id AND post_notes.post_id = posts.id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    -- a folder holds the posts that match one of its keywords, one of its
+    -- tags and one of its authors, leaving out whichever it has none of
+    AND (sqlc.narg(folder)::text IS NULL OR EXISTS (
+        SELECT 1 FROM folders
+        WHERE folders.user_id = feed_follows.user_id
+            AND folders.name = sqlc.narg(folder)
+            AND (cardinality(folders.keywords) = 0 OR EXISTS (
+                SELECT 1 FROM unnest(folders.keywords) AS keyword
+                WHERE to_tsvector('english', posts.title) @@ websearch_to_tsquery('english', keyword)
+            ))
+            AND (cardinality(folders.tags) = 0
+                OR EXISTS (
+                    SELECT 1 FROM feed_tags
+                    WHERE feed_tags.user_id = folders.user_id
+                        AND feed_tags.feed_id = posts.feed_id
+                        AND feed_tags.tag = ANY(folders.tags)
+                )
+                OR EXISTS (
+                    SELECT 1 FROM post_tags
+                    WHERE post_tags.post_id = posts.id AND post_tags.tag = ANY(folders.tags)
+                ))
+            AND (cardinality(folders.authors) = 0 OR LOWER(posts.author) = ANY(folders.authors))
+    ))
+-- each sort key is spelled out once per direction, so only these columns
+-- can ever be ordered by; a key not listed leaves every CASE NULL
+ORDER BY
+    CASE WHEN sqlc.arg(descending)::bool THEN
+        CASE sqlc.arg(sort_by)::text
+            WHEN 'points' THEN posts.score::DOUBLE PRECISION
+            WHEN 'comments' THEN posts.comment_count::DOUBLE PRECISION
+            WHEN 'score' THEN posts.relevance - EXTRACT(EPOCH FROM NOW() - COALESCE(posts.published_at, posts.created_at)) / 86400
+        END
+    END DESC NULLS LAST,
+    CASE WHEN NOT sqlc.arg(descending)::bool THEN
+        CASE sqlc.arg(sort_by)::text
+            WHEN 'points' THEN posts.score::DOUBLE PRECISION
+            WHEN 'comments' THEN posts.comment_count::DOUBLE PRECISION
+            WHEN 'score' THEN posts.relevance - EXTRACT(EPOCH FROM NOW() - COALESCE(posts.published_at, posts.created_at)) / 86400
+        END
+    END ASC NULLS LAST,
+    CASE WHEN sqlc.arg(descending)::bool THEN
+        CASE sqlc.arg(sort_by)::text
+            WHEN 'published' THEN posts.published_at
+            WHEN 'added' THEN posts.created_at
+        END
+    END DESC NULLS LAST,
+    CASE WHEN NOT sqlc.arg(descending)::bool THEN
+        CASE sqlc.arg(sort_by)::text
+            WHEN 'published' THEN posts.published_at
+            WHEN 'added' THEN posts.created_at
+        END
+    END ASC NULLS LAST,
+    CASE WHEN sqlc.arg(descending)::bool THEN
+        CASE sqlc.arg(sort_by)::text
+            WHEN 'feed' THEN LOWER(feeds.name)
+            WHEN 'title' THEN LOWER(posts.title)
+        END
+    END DESC NULLS LAST,
+    CASE WHEN NOT sqlc.arg(descending)::bool THEN
+        CASE sqlc.arg(sort_by)::text
+            WHEN 'feed' THEN LOWER(feeds.name)
+            WHEN 'title' THEN LOWER(posts.title)
+        END
+    END ASC NULLS LAST,
+    -- ties go to the feed's tier, high first
+    CASE feeds.tier WHEN 'high' THEN 0 WHEN 'normal' THEN 1 ELSE 2 END,
+    posts.published_at DESC NULLS LAST,
+    posts.id
+LIMIT sqlc.arg(max_posts);
+
+-- name: GetEditedPostsForUser :many
+SELECT sqlc.embed(posts), feeds.name AS feed_name
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND posts.edited_at IS NOT NULL
+ORDER BY posts.edited_at DESC
+LIMIT sqlc.arg(max_posts);
+
+-- name: GetPostsForExport :many
+SELECT sqlc.embed(posts), feeds.name AS feed_name
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+ORDER BY posts.published_at DESC NULLS LAST;
+
+-- name: GetExistingPostUrls :many
+SELECT url FROM posts
+WHERE feed_id = sqlc.arg(feed_id) AND url = ANY(sqlc.arg(urls)::text[]);
+
+-- name: CreatePosts :many
+INSERT INTO posts (id, feed_id, title, url, description, published_at, enclosure_url, enclosure_type, enclosure_length, author, image_url, duration_seconds, episode, season, thumbnail_url, canonical_url, title_hash, score, comment_count, relevance, guid)
+SELECT id, feed_id, title, url, description, published_at, enclosure_url, enclosure_type, enclosure_length, author, image_url, duration_seconds, episode, season, thumbnail_url, canonical_url, title_hash, score, comment_count, relevance, guid
+FROM json_to_recordset(sqlc.arg(posts)::json) AS p(
+    id UUID,
+    feed_id UUID,
+    title TEXT,
+    url TEXT,
+    description TEXT,
+    published_at TIMESTAMP,
+    enclosure_url TEXT,
+    enclosure_type TEXT,
+    enclosure_length BIGINT,
+    author TEXT,
+    image_url TEXT,
+    duration_seconds INTEGER,
+    episode INTEGER,
+    season INTEGER,
+    thumbnail_url TEXT,
+    canonical_url TEXT,
+    title_hash TEXT,
+    score INTEGER,
+    comment_count INTEGER,
+    relevance DOUBLE PRECISION,
+    guid TEXT
+)
+ON CONFLICT DO NOTHING
+RETURNING id;
+
+-- name: UpdateEditedPosts :many
+UPDATE posts
+SET title = p.title, description = p.description, title_hash = p.title_hash,
+    guid = COALESCE(posts.guid, p.guid), edited_at = NOW(), updated_at = NOW()
+FROM json_to_recordset(sqlc.arg(posts)::json) AS p(
+    guid TEXT,
+    url TEXT,
+    title TEXT,
+    description TEXT,
+    title_hash TEXT
+)
+WHERE posts.feed_id = sqlc.arg(feed_id)
+    -- posts stored before guids were kept, or from items without one, match by URL
+    AND (posts.guid = p.guid OR (posts.url = p.url AND (posts.guid IS NULL OR p.guid IS NULL)))
+    AND (posts.title IS DISTINCT FROM p.title OR posts.description IS DISTINCT FROM p.description)
+RETURNING posts.id;
+
+-- name: UpdatePostEngagement :exec
+UPDATE posts
+SET score = p.score, comment_count = p.comment_count, updated_at = NOW()
+FROM json_to_recordset(sqlc.arg(posts)::json) AS p(
+    url TEXT,
+    score INTEGER,
+    comment_count INTEGER
+)
+WHERE posts.feed_id = sqlc.arg(feed_id)
+    AND posts.url = p.url
+    AND (posts.score IS DISTINCT FROM p.score OR posts.comment_count IS DISTINCT FROM p.comment_count);
+
+-- name: GetPostsForUserSince :many
+SELECT sqlc.embed(posts), feeds.name AS feed_name, post_notes.note
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+LEFT JOIN post_notes ON post_notes.user_id = feed_follows.user_id AND post_notes.post_id = posts.id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND posts.created_at > COALESCE(sqlc.narg(since)::timestamp, NOW() - INTERVAL '1 day')
+ORDER BY posts.created_at;
+
+-- name: GetRelatedPosts :many
+WITH source AS (
+    SELECT id, replace(plainto_tsquery('english', title)::text, '&', '|')::tsquery AS query
+    FROM posts
+    WHERE posts.id = sqlc.arg(post_id)
+)
+SELECT sqlc.embed(posts), feeds.name AS feed_name,
+    ts_rank(to_tsvector('english', posts.title), source.query)::real AS rank
+FROM source
+JOIN posts ON posts.id <> source.id
+    AND to_tsvector('english', posts.title) @@ source.query
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg(max_results);
+
+-- name: SearchPosts :many
+SELECT sqlc.embed(posts), feeds.name AS feed_name,
+    ts_rank(to_tsvector('english', posts.title), query)::real AS rank
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+CROSS JOIN websearch_to_tsquery('english', sqlc.arg(terms)) AS query
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND to_tsvector('english', posts.title) @@ query
+    AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+    -- a tag matches whether the user gave it to the feed or filter_script
+    -- gave it to the post
+    AND (sqlc.narg(tag)::text IS NULL
+        OR EXISTS (
+            SELECT 1 FROM feed_tags
+            WHERE feed_tags.user_id = feed_follows.user_id
+                AND feed_tags.feed_id = posts.feed_id
+                AND feed_tags.tag = sqlc.narg(tag)
+        )
+        OR EXISTS (
+            SELECT 1 FROM post_tags
+            WHERE post_tags.post_id = posts.id AND post_tags.tag = sqlc.narg(tag)
+        ))
+    AND (NOT sqlc.arg(starred)::bool OR EXISTS (
+        SELECT 1 FROM post_stars
+        WHERE post_stars.user_id = feed_follows.user_id AND post_stars.post_id = posts.id
+    ))
+    AND (NOT sqlc.arg(unread)::bool OR NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.user_id = feed_follows.user_id AND post_reads.post_id = posts.id
+    ))
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg(max_results);
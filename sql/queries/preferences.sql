@@ -0,0 +1,22 @@
+-- name: GetPreferencesForUser :many
+SELECT * FROM user_preferences
+WHERE user_id = $1
+ORDER BY key;
+
+-- name: GetPreference :one
+SELECT value FROM user_preferences
+WHERE user_id = $1 AND key = $2;
+
+-- name: GetPreferencesByKey :many
+SELECT * FROM user_preferences
+WHERE key = $1;
+
+-- name: SetPreference :exec
+INSERT INTO user_preferences (user_id, key, value)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, key) DO UPDATE
+SET value = EXCLUDED.value, updated_at = NOW();
+
+-- name: DeletePreference :execrows
+DELETE FROM user_preferences
+WHERE user_id = $1 AND key = $2;
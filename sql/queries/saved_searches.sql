@@ -0,0 +1,32 @@
+-- name: SaveSearch :exec
+INSERT INTO saved_searches (user_id, name, terms, feed_id, tag, starred, unread)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+ON CONFLICT (user_id, name) DO UPDATE
+SET terms = EXCLUDED.terms,
+    feed_id = EXCLUDED.feed_id,
+    tag = EXCLUDED.tag,
+    starred = EXCLUDED.starred,
+    unread = EXCLUDED.unread;
+
+-- name: GetSavedSearch :one
+SELECT * FROM saved_searches
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetSavedSearchesForUser :many
+SELECT sqlc.embed(saved_searches), feeds.name AS feed_name
+FROM saved_searches
+LEFT JOIN feeds ON feeds.id = saved_searches.feed_id
+WHERE saved_searches.user_id = $1
+ORDER BY saved_searches.name;
+
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_searches
+WHERE user_id = $1 AND name = $2;
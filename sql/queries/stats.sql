@@ -0,0 +1,35 @@
+-- name: CountFeeds :one
+SELECT COUNT(*) FROM feeds;
+
+-- name: CountPosts :one
+SELECT COUNT(*) FROM posts;
+
+-- name: GetPostsPerDay :many
+SELECT
+    date_trunc('day', COALESCE(published_at, created_at))::date AS day,
+    COUNT(*) AS post_count
+FROM posts
+WHERE COALESCE(published_at, created_at) >= sqlc.arg(since)::timestamp
+GROUP BY day
+ORDER BY day;
+
+-- name: GetFeedActivity :many
+SELECT feeds.name, COUNT(posts.id) AS post_count
+FROM feeds
+LEFT JOIN posts ON posts.feed_id = feeds.id
+    AND COALESCE(posts.published_at, posts.created_at) >= sqlc.arg(since)::timestamp
+GROUP BY feeds.id, feeds.name
+ORDER BY post_count DESC, feeds.name;
+
+-- name: GetUnreadCountsForUser :many
+SELECT feeds.name, COUNT(posts.id) AS unread_count
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+LEFT JOIN posts ON posts.feed_id = feeds.id
+    AND NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+    )
+WHERE feed_follows.user_id = $1
+GROUP BY feeds.id, feeds.name
+ORDER BY unread_count DESC, feeds.name;
@@ -0,0 +1,18 @@
+-- name: GetTrendingPosts :many
+SELECT sqlc.embed(posts), feeds.name AS feed_name, stars.starred_by, reads.read_by
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+CROSS JOIN LATERAL (
+    SELECT COUNT(*) AS starred_by FROM post_stars WHERE post_stars.post_id = posts.id
+) AS stars
+CROSS JOIN LATERAL (
+    SELECT COUNT(*) AS read_by FROM post_reads WHERE post_reads.post_id = posts.id
+) AS reads
+WHERE COALESCE(posts.published_at, posts.created_at) >= sqlc.arg(since)
+    AND (stars.starred_by > 0 OR reads.read_by > 0)
+-- a star counts for three reads, and the total decays with the post's age
+-- in hours the way Hacker News ranks stories
+ORDER BY (3 * stars.starred_by + reads.read_by)
+        / POWER(GREATEST(EXTRACT(EPOCH FROM NOW() - COALESCE(posts.published_at, posts.created_at)) / 3600, 0) + 2, 1.5) DESC,
+    posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg(max_results);
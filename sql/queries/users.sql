@@ -10,7 +10,7 @@ RETURNING *;
 
 -- name: GetUsersByName :many
 SELECT * FROM users
-WHERE name = $1;
+WHERE LOWER(name) = LOWER($1);
 
 -- name: GetUserById :one
 SELECT * FROM users
@@ -21,3 +21,52 @@ SELECT * FROM users;
 
 -- name: DeleteUsers :exec
 DELETE FROM users;
+
+-- name: SetUserTimezone :exec
+UPDATE users
+SET timezone = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkUserSeen :exec
+UPDATE users
+SET last_seen_at = NOW()
+WHERE id = $1;
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetUserAdmin :exec
+UPDATE users
+SET is_admin = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CountAdmins :one
+SELECT COUNT(*) FROM users
+WHERE is_admin;
+
+-- name: GetUserStats :many
+SELECT
+    users.id,
+    users.name,
+    users.is_admin,
+    (SELECT COUNT(*) FROM feeds WHERE feeds.user_id = users.id) AS feed_count,
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = users.id) AS follow_count,
+    (SELECT COUNT(*) FROM posts
+        JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+        WHERE feed_follows.user_id = users.id) AS post_count,
+    (SELECT COUNT(*) FROM post_reads WHERE post_reads.user_id = users.id) AS read_count,
+    COALESCE(GREATEST(
+        users.last_seen_at,
+        (SELECT MAX(read_at) FROM post_reads WHERE post_reads.user_id = users.id),
+        (SELECT MAX(starred_at) FROM post_stars WHERE post_stars.user_id = users.id),
+        (SELECT MAX(created_at) FROM sessions WHERE sessions.user_id = users.id),
+        (SELECT MAX(created_at) FROM feed_follows WHERE feed_follows.user_id = users.id)
+    ), users.created_at)::TIMESTAMP AS last_active_at
+FROM users
+ORDER BY users.name;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
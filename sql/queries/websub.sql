@@ -0,0 +1,37 @@
+-- name: GetWebSubCandidates :many
+SELECT feeds.id, feeds.name, feeds.websub_hub, feeds.websub_topic, websub_subscriptions.secret
+FROM feeds
+LEFT JOIN websub_subscriptions ON websub_subscriptions.feed_id = feeds.id
+WHERE feeds.websub_hub IS NOT NULL
+    AND (
+        websub_subscriptions.feed_id IS NULL
+        OR websub_subscriptions.hub <> feeds.websub_hub
+        OR websub_subscriptions.lease_expires_at < sqlc.arg(renew_before)::timestamp
+        OR (websub_subscriptions.lease_expires_at IS NULL AND websub_subscriptions.requested_at < sqlc.arg(retry_before)::timestamp)
+    );
+
+-- name: UpsertWebSubSubscription :exec
+INSERT INTO websub_subscriptions (feed_id, hub, topic, secret, requested_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (feed_id) DO UPDATE
+SET hub = EXCLUDED.hub, topic = EXCLUDED.topic, secret = EXCLUDED.secret, requested_at = EXCLUDED.requested_at;
+
+-- name: GetWebSubSubscription :one
+SELECT *
+FROM websub_subscriptions
+WHERE feed_id = $1;
+
+-- name: SetWebSubLease :exec
+UPDATE websub_subscriptions
+SET lease_expires_at = $2
+WHERE feed_id = $1;
+
+-- name: DeleteWebSubSubscription :exec
+DELETE FROM websub_subscriptions
+WHERE feed_id = $1;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE posts
+    ADD COLUMN canonical_url TEXT,
+    ADD COLUMN title_hash TEXT;
+
+CREATE INDEX posts_canonical_url_idx ON posts (canonical_url);
+CREATE INDEX posts_title_hash_idx ON posts (title_hash);
+
+-- +goose Down
+DROP INDEX posts_title_hash_idx;
+DROP INDEX posts_canonical_url_idx;
+
+ALTER TABLE posts
+    DROP COLUMN title_hash,
+    DROP COLUMN canonical_url;
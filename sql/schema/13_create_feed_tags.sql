@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE feed_tags (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (user_id, feed_id, tag)
+);
+
+-- +goose Down
+DROP TABLE feed_tags;
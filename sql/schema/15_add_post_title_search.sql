@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX posts_title_tsv_idx ON posts USING GIN (to_tsvector('english', title));
+
+-- +goose Down
+DROP INDEX posts_title_tsv_idx;
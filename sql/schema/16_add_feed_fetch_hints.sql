@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE feeds
+    ADD COLUMN poll_interval_seconds INTEGER,
+    ADD COLUMN skip_hours INTEGER,
+    ADD COLUMN skip_days INTEGER;
+
+-- +goose Down
+ALTER TABLE feeds
+    DROP COLUMN skip_days,
+    DROP COLUMN skip_hours,
+    DROP COLUMN poll_interval_seconds;
@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE feeds
+    ADD COLUMN websub_hub TEXT,
+    ADD COLUMN websub_topic TEXT;
+
+CREATE TABLE websub_subscriptions (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    hub TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    requested_at TIMESTAMP NOT NULL,
+    lease_expires_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE websub_subscriptions;
+
+ALTER TABLE feeds
+    DROP COLUMN websub_topic,
+    DROP COLUMN websub_hub;
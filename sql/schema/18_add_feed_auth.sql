@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN auth BYTEA;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN auth;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE feed_headers (
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    value TEXT NOT NULL,
+    PRIMARY KEY (feed_id, name)
+);
+
+-- +goose Down
+DROP TABLE feed_headers;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN scraper TEXT;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN scraper;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE posts
+    ADD COLUMN score INTEGER,
+    ADD COLUMN comment_count INTEGER;
+
+-- +goose Down
+ALTER TABLE posts
+    DROP COLUMN comment_count,
+    DROP COLUMN score;
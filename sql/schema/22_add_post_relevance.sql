@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE feeds
+    ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE posts
+    ADD COLUMN relevance DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE TABLE keyword_weights (
+    keyword TEXT PRIMARY KEY,
+    weight DOUBLE PRECISION NOT NULL
+);
+
+-- +goose Down
+DROP TABLE keyword_weights;
+
+ALTER TABLE posts
+    DROP COLUMN relevance;
+
+ALTER TABLE feeds
+    DROP COLUMN priority;
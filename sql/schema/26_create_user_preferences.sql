@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE user_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX user_preferences_key_idx ON user_preferences (key);
+
+-- +goose Down
+DROP TABLE user_preferences;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE fetch_log (
+    id BIGSERIAL PRIMARY KEY,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    fetched_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    status_code INTEGER,
+    duration_ms INTEGER NOT NULL,
+    items_seen INTEGER NOT NULL DEFAULT 0,
+    new_posts INTEGER NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+CREATE INDEX fetch_log_feed_id_fetched_at_idx ON fetch_log (feed_id, fetched_at DESC);
+
+-- +goose Down
+DROP TABLE fetch_log;
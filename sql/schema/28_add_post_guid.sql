@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN guid TEXT;
+ALTER TABLE posts ADD COLUMN edited_at TIMESTAMP;
+
+CREATE UNIQUE INDEX posts_feed_id_guid_key ON posts (feed_id, guid);
+CREATE INDEX posts_edited_at_idx ON posts (edited_at) WHERE edited_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX posts_edited_at_idx;
+DROP INDEX posts_feed_id_guid_key;
+ALTER TABLE posts DROP COLUMN edited_at;
+ALTER TABLE posts DROP COLUMN guid;
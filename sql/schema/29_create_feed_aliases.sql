@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE feed_aliases (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    alias TEXT NOT NULL,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, alias)
+);
+
+-- +goose Down
+DROP TABLE feed_aliases;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN tier TEXT NOT NULL DEFAULT 'normal'
+    CHECK (tier IN ('high', 'normal', 'low'));
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN tier;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN content_hash TEXT;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN content_hash;
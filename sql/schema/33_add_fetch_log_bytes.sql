@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE fetch_log ADD COLUMN bytes BIGINT;
+
+-- +goose Down
+ALTER TABLE fetch_log DROP COLUMN bytes;
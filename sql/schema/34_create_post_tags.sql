@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE post_tags (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (post_id, tag)
+);
+
+-- +goose Down
+DROP TABLE post_tags;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE saved_searches (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    terms TEXT NOT NULL,
+    feed_id UUID REFERENCES feeds(id) ON DELETE CASCADE,
+    tag TEXT,
+    starred BOOLEAN NOT NULL DEFAULT FALSE,
+    unread BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, name)
+);
+
+-- +goose Down
+DROP TABLE saved_searches;
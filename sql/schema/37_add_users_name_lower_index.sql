@@ -0,0 +1,8 @@
+-- +goose Up
+-- names are unique ignoring case, so "Lane" and "lane" are one user; this
+-- fails on a database already holding names that differ only in case,
+-- which have to be renamed first
+CREATE UNIQUE INDEX users_name_lower_idx ON users (LOWER(name));
+
+-- +goose Down
+DROP INDEX users_name_lower_idx;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- users are recorded by name, so the trail outlives the users in it
+CREATE TABLE feed_owner_changes (
+    id BIGSERIAL PRIMARY KEY,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    from_user TEXT NOT NULL,
+    to_user TEXT NOT NULL,
+    changed_by TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX feed_owner_changes_feed_id_idx ON feed_owner_changes (feed_id, changed_at);
+
+-- +goose Down
+DROP TABLE feed_owner_changes;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE posts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    title TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    description TEXT,
+    published_at TIMESTAMP,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    enclosure_url TEXT,
+    enclosure_type TEXT,
+    enclosure_length BIGINT
+);
+
+-- +goose Down
+DROP TABLE posts;
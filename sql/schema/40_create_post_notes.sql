@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE post_notes (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    note TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- notes kept with stars move here, so a post has one note whether or not
+-- it is starred
+INSERT INTO post_notes (user_id, post_id, note, created_at, updated_at)
+SELECT user_id, post_id, note, starred_at, starred_at
+FROM post_stars
+WHERE note IS NOT NULL;
+
+ALTER TABLE post_stars DROP COLUMN note;
+
+-- +goose Down
+ALTER TABLE post_stars ADD COLUMN note TEXT;
+
+UPDATE post_stars
+SET note = post_notes.note
+FROM post_notes
+WHERE post_notes.user_id = post_stars.user_id AND post_notes.post_id = post_stars.post_id;
+
+DROP TABLE post_notes;
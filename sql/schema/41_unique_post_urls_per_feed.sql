@@ -0,0 +1,14 @@
+-- +goose Up
+-- the same article in two feeds is stored once for each, so browse can
+-- fold them together and say which other feeds carried it
+ALTER TABLE posts DROP CONSTRAINT posts_url_key;
+ALTER TABLE posts ADD CONSTRAINT posts_feed_id_url_key UNIQUE (feed_id, url);
+
+-- +goose Down
+DELETE FROM posts
+USING posts AS first
+WHERE posts.url = first.url
+    AND (posts.created_at, posts.id) > (first.created_at, first.id);
+
+ALTER TABLE posts DROP CONSTRAINT posts_feed_id_url_key;
+ALTER TABLE posts ADD CONSTRAINT posts_url_key UNIQUE (url);
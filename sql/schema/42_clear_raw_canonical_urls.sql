@@ -0,0 +1,7 @@
+-- +goose Up
+-- migration 10 filled canonical_url with the raw URL, which then stood in
+-- for the canonical form; left NULL, it is worked out when posts are read
+UPDATE posts SET canonical_url = NULL WHERE canonical_url = url;
+
+-- +goose Down
+UPDATE posts SET canonical_url = url WHERE canonical_url IS NULL;
@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE feeds
+    ADD COLUMN author TEXT,
+    ADD COLUMN image_url TEXT;
+
+ALTER TABLE posts
+    ADD COLUMN author TEXT,
+    ADD COLUMN image_url TEXT,
+    ADD COLUMN duration_seconds INTEGER,
+    ADD COLUMN episode INTEGER,
+    ADD COLUMN season INTEGER;
+
+-- +goose Down
+ALTER TABLE posts
+    DROP COLUMN season,
+    DROP COLUMN episode,
+    DROP COLUMN duration_seconds,
+    DROP COLUMN image_url,
+    DROP COLUMN author;
+
+ALTER TABLE feeds
+    DROP COLUMN image_url,
+    DROP COLUMN author;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN user_agent TEXT;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN user_agent;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- register always refused taken names, so duplicate users can only come
+-- from a race; they own posts and follows, so rather than guess which is
+-- which this stops and names them
+-- +goose StatementBegin
+DO $$
+DECLARE
+    dupes TEXT;
+BEGIN
+    SELECT string_agg(DISTINCT name, ', ') INTO dupes
+    FROM users
+    WHERE name IN (SELECT name FROM users GROUP BY name HAVING COUNT(*) > 1);
+
+    IF dupes IS NOT NULL THEN
+        RAISE EXCEPTION 'several users share the names %; rename all but one of each before migrating', dupes;
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+-- addfeed never checked feed names, so all but the oldest feed of a name
+-- get the host of their URL added, as import names feeds
+WITH dupes AS (
+    SELECT id, name,
+        COALESCE(substring(url from '://([^/?#]+)'), url) AS host,
+        ROW_NUMBER() OVER (PARTITION BY name ORDER BY created_at, id) AS n
+    FROM feeds
+)
+UPDATE feeds
+SET name = dupes.name || ' (' || dupes.host || ')' || CASE WHEN dupes.n > 2 THEN ' ' || (dupes.n - 1) ELSE '' END
+FROM dupes
+WHERE feeds.id = dupes.id AND dupes.n > 1;
+
+ALTER TABLE users ADD CONSTRAINT users_name_key UNIQUE (name);
+ALTER TABLE feeds ADD CONSTRAINT feeds_name_key UNIQUE (name);
+
+-- +goose Down
+ALTER TABLE feeds DROP CONSTRAINT feeds_name_key;
+ALTER TABLE users DROP CONSTRAINT users_name_key;
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// handlerStar saves a post to the reading list, optionally with a note.
+// The note is the same one the note command keeps, so starring a post
+// again with --note replaces it.
+func handlerStar(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("star", flag.ContinueOnError)
+	note := fs.String("note", "", "a note to keep with the post")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("star command requires a post ID or a number from the last listing")
+	}
+
+	user, post, err := postForUser(ctx, s, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if err := starPost(ctx, s, user.ID, post.ID, strings.TrimSpace(*note)); err != nil {
+		return err
+	}
+
+	infof("Starred %s\n", post.Title)
+	return nil
+}
+
+// starPost stars a post and, unless note is empty, keeps note as the
+// user's note on it.
+func starPost(ctx context.Context, s *state, userID, postID uuid.UUID, note string) error {
+	return s.withTx(ctx, func(tx *state) error {
+		if err := tx.db.StarPost(ctx, database.StarPostParams{
+			UserID: userID,
+			PostID: postID,
+		}); err != nil {
+			return fmt.Errorf("failed to star post: %v", err)
+		}
+		if note == "" {
+			return nil
+		}
+		if err := tx.db.SetPostNote(ctx, database.SetPostNoteParams{
+			UserID: userID,
+			PostID: postID,
+			Note:   note,
+		}); err != nil {
+			return fmt.Errorf("failed to save note: %v", err)
+		}
+		return nil
+	})
+}
+
+func handlerUnstar(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return usageErrorf("unstar command requires a post ID or a number from the last listing")
+	}
+
+	user, post, err := postForUser(ctx, s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.db.UnstarPost(ctx, database.UnstarPostParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unstar post: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("%s is not starred", post.Title)
+	}
+
+	infof("Unstarred %s\n", post.Title)
+	return nil
+}
+
+// handlerStarred lists the reading list, oldest star first.
+func handlerStarred(ctx context.Context, s *state, cmd command) error {
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	posts, err := s.db.GetStarredPosts(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get starred posts: %v", err)
+	}
+	if len(posts) == 0 {
+		fmt.Println("No starred posts")
+		return nil
+	}
+
+	stories := make([]story, 0, len(posts))
+	for _, post := range posts {
+		stories = append(stories, story{Row: database.GetPostsForUserRow{Post: post.Post, FeedName: post.FeedName, Note: post.Note}})
+	}
+
+	printStories(s, stories, userLocation(user), false)
+	return nil
+}
+
+// postForUser resolves a post reference for the current user.
+func postForUser(ctx context.Context, s *state, ref string) (database.User, database.Post, error) {
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return database.User{}, database.Post{}, err
+	}
+
+	postID, err := resolvePostRef(s, ref)
+	if err != nil {
+		return database.User{}, database.Post{}, err
+	}
+
+	post, err := s.db.GetPostById(ctx, postID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return database.User{}, database.Post{}, notFoundErrorf("post %s does not exist", postID)
+		}
+		return database.User{}, database.Post{}, fmt.Errorf("failed to get post: %v", err)
+	}
+
+	return user, post, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const statsActiveFeeds = 3
+
+func handlerStats(ctx context.Context, s *state, cmd command) error {
+
+	feedCount, err := s.db.CountFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count feeds: %v", err)
+	}
+
+	postCount, err := s.db.CountPosts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count posts: %v", err)
+	}
+
+	fmt.Printf("Feeds: %d\n", feedCount)
+	fmt.Printf("Posts: %d\n", postCount)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	monthStart := today.AddDate(0, 0, -29)
+	weekStart := today.AddDate(0, 0, -6)
+
+	perDay, err := s.db.GetPostsPerDay(ctx, monthStart)
+	if err != nil {
+		return fmt.Errorf("failed to get posts per day: %v", err)
+	}
+
+	var weekTotal, monthTotal int64
+	counts := make(map[string]int64, len(perDay))
+	for _, day := range perDay {
+		counts[day.Day.Format(time.DateOnly)] = day.PostCount
+		monthTotal += day.PostCount
+		if !day.Day.Before(weekStart) {
+			weekTotal += day.PostCount
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("Posts per day (last 7 days):")
+	for day := weekStart; !day.After(today); day = day.AddDate(0, 0, 1) {
+		fmt.Printf("  %s  %d\n", day.Format("Mon Jan 02"), counts[day.Format(time.DateOnly)])
+	}
+	fmt.Printf("Last 7 days: %d posts (%.1f/day)\n", weekTotal, float64(weekTotal)/7)
+	fmt.Printf("Last 30 days: %d posts (%.1f/day)\n", monthTotal, float64(monthTotal)/30)
+
+	activity, err := s.db.GetFeedActivity(ctx, monthStart)
+	if err != nil {
+		return fmt.Errorf("failed to get feed activity: %v", err)
+	}
+
+	if len(activity) > 0 {
+		n := min(statsActiveFeeds, len(activity))
+
+		fmt.Println()
+		fmt.Println("Most active feeds (last 30 days):")
+		for _, feed := range activity[:n] {
+			fmt.Printf("  %s: %d\n", feed.Name, feed.PostCount)
+		}
+
+		fmt.Println("Least active feeds (last 30 days):")
+		for i := len(activity) - 1; i >= len(activity)-n; i-- {
+			fmt.Printf("  %s: %d\n", activity[i].Name, activity[i].PostCount)
+		}
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	unread, err := s.db.GetUnreadCountsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get unread counts: %v", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Unread posts for %s:\n", user.Name)
+	for _, feed := range unread {
+		fmt.Printf("  %s: %d\n", feed.Name, feed.UnreadCount)
+	}
+
+	return nil
+}
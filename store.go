@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// Store is the database as handlers see it: the generated queries plus
+// the few things that need the connection itself. Handlers that use only
+// a little of it name that part with a smaller interface, like
+// trendingStore, so tests can hand them a fake in place of Postgres.
+type Store interface {
+	database.Querier
+	pinger
+	txRunner
+	sessionLocker
+}
+
+type pinger interface {
+	// Ping checks that the database can be reached.
+	Ping(ctx context.Context) error
+}
+
+type txRunner interface {
+	// InTx runs fn with a Store whose queries go through a single
+	// transaction, committing when fn succeeds and rolling back otherwise.
+	// On that Store, InTx runs fn in the same transaction rather than
+	// starting another.
+	InTx(ctx context.Context, fn func(tx Store) error) error
+}
+
+type sessionLocker interface {
+	// TryLock takes the advisory lock key without waiting, reporting
+	// whether it was free. The lock belongs to a database session, so it
+	// is held on a connection set aside until unlock is called.
+	TryLock(ctx context.Context, key int64) (unlock func(context.Context) error, locked bool, err error)
+}
+
+// pgStore is the Store backed by Postgres.
+type pgStore struct {
+	*database.Queries
+	conn *sql.DB
+	// inTx is set on the store InTx hands to fn
+	inTx bool
+}
+
+func newPGStore(conn *sql.DB) *pgStore {
+	return &pgStore{Queries: database.New(tracedDB{conn}), conn: conn}
+}
+
+func (p *pgStore) Ping(ctx context.Context) error {
+	return p.conn.PingContext(ctx)
+}
+
+func (p *pgStore) InTx(ctx context.Context, fn func(tx Store) error) error {
+	if p.inTx {
+		return fn(p)
+	}
+
+	sqlTx, err := p.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer sqlTx.Rollback()
+
+	if err := fn(&pgStore{Queries: database.New(tracedDB{sqlTx}), conn: p.conn, inTx: true}); err != nil {
+		return err
+	}
+
+	if err := sqlTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (p *pgStore) TryLock(ctx context.Context, key int64) (func(context.Context) error, bool, error) {
+	conn, err := p.conn.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get connection: %v", err)
+	}
+
+	queries := database.New(conn)
+	locked, err := queries.TryAdvisoryLock(ctx, key)
+	if err != nil || !locked {
+		conn.Close()
+		return nil, false, err
+	}
+
+	unlock := func(ctx context.Context) error {
+		defer conn.Close()
+		_, err := queries.AdvisoryUnlock(ctx, key)
+		return err
+	}
+	return unlock, true, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/config"
+	"github.com/necodeus/gator/internal/database"
+)
+
+// fakeStore keeps in memory the little of the database the tested
+// handlers use. Anything else reaches the nil Store it embeds and panics,
+// so a handler that starts needing more of the database fails here first.
+type fakeStore struct {
+	Store
+
+	users    []database.User
+	feeds    []database.Feed
+	follows  []database.FeedFollow
+	postURLs map[uuid.UUID][]string
+	trending []database.GetTrendingPostsRow
+
+	// asked is the last GetTrendingPosts call
+	asked database.GetTrendingPostsParams
+	// txs counts InTx calls
+	txs int
+}
+
+func (f *fakeStore) InTx(ctx context.Context, fn func(tx Store) error) error {
+	f.txs++
+	return fn(f)
+}
+
+func (f *fakeStore) GetUsersByName(ctx context.Context, name string) ([]database.User, error) {
+	var found []database.User
+	for _, user := range f.users {
+		if user.Name == name {
+			found = append(found, user)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeStore) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	user := database.User{ID: arg.ID, CreatedAt: arg.CreatedAt, UpdatedAt: arg.UpdatedAt, Name: arg.Name}
+	f.users = append(f.users, user)
+	return user, nil
+}
+
+func (f *fakeStore) CountAdmins(ctx context.Context) (int64, error) {
+	var n int64
+	for _, user := range f.users {
+		if user.IsAdmin {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeStore) SetUserAdmin(ctx context.Context, arg database.SetUserAdminParams) error {
+	for i := range f.users {
+		if f.users[i].ID == arg.ID {
+			f.users[i].IsAdmin = arg.IsAdmin
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) FollowSharedFeeds(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var n int64
+	for _, feed := range f.feeds {
+		if feed.Shared {
+			f.follows = append(f.follows, database.FeedFollow{ID: uuid.New(), UserID: userID, FeedID: feed.ID})
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeStore) GetFeedByUrl(ctx context.Context, url string) (database.Feed, error) {
+	for _, feed := range f.feeds {
+		if feed.Url == url {
+			return feed, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (f *fakeStore) GetFeedsByName(ctx context.Context, name string) ([]database.Feed, error) {
+	var found []database.Feed
+	for _, feed := range f.feeds {
+		if feed.Name == name {
+			found = append(found, feed)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeStore) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	feed := database.Feed{ID: arg.ID, UserID: arg.UserID, Name: arg.Name, Url: arg.Url, Tier: tierNormal}
+	f.feeds = append(f.feeds, feed)
+	return feed, nil
+}
+
+func (f *fakeStore) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.FeedFollow, error) {
+	follow := database.FeedFollow{ID: arg.ID, UserID: arg.UserID, FeedID: arg.FeedID}
+	f.follows = append(f.follows, follow)
+	return follow, nil
+}
+
+func (f *fakeStore) GetFeedHeaders(ctx context.Context, feedID uuid.UUID) ([]database.FeedHeader, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) GetExistingPostUrls(ctx context.Context, arg database.GetExistingPostUrlsParams) ([]string, error) {
+	var found []string
+	for _, url := range f.postURLs[arg.FeedID] {
+		if slices.Contains(arg.Urls, url) {
+			found = append(found, url)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeStore) GetTrendingPosts(ctx context.Context, arg database.GetTrendingPostsParams) ([]database.GetTrendingPostsRow, error) {
+	f.asked = arg
+	return f.trending, nil
+}
+
+// testState is a state on db for the user ann, keeping its files under a
+// temporary directory.
+func testState(t *testing.T, db Store) *state {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	return &state{
+		db:           db,
+		Config:       &config.Config{CurrentUserName: "ann", DataDir: t.TempDir()},
+		dbTimeout:    time.Second,
+		fetchTimeout: time.Second,
+	}
+}
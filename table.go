@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"os"
+	"strings"
+	"time"
+)
+
+// tableFlags are the --format and --columns flags listing commands share
+// for printing CSV or TSV instead of their usual text.
+type tableFlags struct {
+	format  *string
+	columns *string
+}
+
+func addTableFlags(fs *flag.FlagSet) tableFlags {
+	return tableFlags{
+		format:  fs.String("format", "text", "output format: text, csv or tsv"),
+		columns: fs.String("columns", "", "comma-separated columns for csv and tsv output"),
+	}
+}
+
+// tabular reports whether csv or tsv output was asked for, failing on a
+// format it doesn't know.
+func (f tableFlags) tabular() (bool, error) {
+	switch *f.format {
+	case "text":
+		if *f.columns != "" {
+			return false, usageErrorf("--columns needs --format csv or tsv")
+		}
+		return false, nil
+	case "csv", "tsv":
+		return true, nil
+	default:
+		return false, usageErrorf("unknown format %s, expected text, csv or tsv", *f.format)
+	}
+}
+
+// tableColumn is one field a listing of T can print in a table.
+type tableColumn[T any] struct {
+	name  string
+	value func(T) string
+}
+
+// selectColumns picks the columns named in --columns, or defaults when it
+// is empty.
+func selectColumns[T any](f tableFlags, available []tableColumn[T], defaults []string) ([]tableColumn[T], error) {
+	names := defaults
+	if *f.columns != "" {
+		names = strings.Split(*f.columns, ",")
+	}
+
+	selected := make([]tableColumn[T], 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, column := range available {
+			if column.name == name {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			known := make([]string, 0, len(available))
+			for _, column := range available {
+				known = append(known, column.name)
+			}
+			return nil, usageErrorf("unknown column %s, expected some of %s", name, strings.Join(known, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// writeTable prints a header and one line per row, quoted as CSV needs.
+// TSV is the same with tabs between the fields.
+func writeTable[T any](f tableFlags, columns []tableColumn[T], rows []T) error {
+	w := csv.NewWriter(os.Stdout)
+	if *f.format == "tsv" {
+		w.Comma = '\t'
+	}
+
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.name
+	}
+	if err := w.Write(record); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = column.value(row)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// tableTime formats a time for a table cell, empty when it is unknown.
+func tableTime(t time.Time, valid bool) string {
+	if !valid {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+// userLocation returns the timezone user wants dates shown in, falling
+// back to the machine's local zone when none is set or it's invalid.
+func userLocation(user database.User) *time.Location {
+	if !user.Timezone.Valid || user.Timezone.String == "" {
+		return time.Local
+	}
+
+	loc, err := time.LoadLocation(user.Timezone.String)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// handlerTimezone shows or sets the current user's display timezone.
+// "local" clears it so the machine's zone is used again.
+func handlerTimezone(ctx context.Context, s *state, cmd command) error {
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if len(cmd.Args) == 0 {
+		if user.Timezone.Valid {
+			fmt.Printf("Timezone for %s: %s\n", user.Name, user.Timezone.String)
+		} else {
+			fmt.Printf("Timezone for %s: local (%s)\n", user.Name, time.Local)
+		}
+		return nil
+	}
+
+	zone := cmd.Args[0]
+	timezone := sql.NullString{}
+	if zone != "local" {
+		if _, err := time.LoadLocation(zone); err != nil {
+			return usageErrorf("unknown timezone %s, expected an IANA name like Europe/Warsaw", zone)
+		}
+		timezone = sql.NullString{String: zone, Valid: true}
+	}
+
+	if err := s.db.SetUserTimezone(ctx, database.SetUserTimezoneParams{
+		ID:       user.ID,
+		Timezone: timezone,
+	}); err != nil {
+		return fmt.Errorf("failed to set timezone: %v", err)
+	}
+
+	infof("Timezone for %s set to %s\n", user.Name, zone)
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"net/http/httptrace"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/necodeus/gator/internal/config"
+	"github.com/necodeus/gator/internal/database"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer is a no-op until setupTracing installs a provider, so spans cost
+// next to nothing when tracing is off.
+var tracer = otel.Tracer("github.com/necodeus/gator")
+
+// setupTracing exports spans over OTLP/HTTP when otlp_endpoint or the
+// standard OTEL_EXPORTER_OTLP_*ENDPOINT variables say where to. The
+// returned shutdown flushes whatever is still buffered.
+func setupTracing(cfg *config.Config) (shutdown func(context.Context) error, err error) {
+	shutdown = func(context.Context) error { return nil }
+	if cfg.OTLPEndpoint == "" && os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return shutdown, nil
+	}
+
+	var opts []otlptracehttp.Option
+	if cfg.OTLPEndpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return shutdown, fmt.Errorf("failed to set up the OTLP exporter: %v", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName("gator"),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return shutdown, fmt.Errorf("failed to describe the tracing resource: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// withClientTrace makes the DNS lookups, dials and TLS handshakes of any
+// request sent with the returned context child spans of ctx's span.
+func withClientTrace(ctx context.Context) context.Context {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx
+	}
+
+	var (
+		mu    sync.Mutex
+		dns   trace.Span
+		tlsHS trace.Span
+		dials = map[string]trace.Span{}
+	)
+	ct := &httptrace.ClientTrace{
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			_, dns = tracer.Start(ctx, "dns", trace.WithAttributes(semconv.ServerAddress(info.Host)))
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			if dns != nil {
+				endSpan(dns, info.Err)
+				dns = nil
+			}
+		},
+		ConnectStart: func(network, addr string) {
+			mu.Lock()
+			defer mu.Unlock()
+			_, dials[network+" "+addr] = tracer.Start(ctx, "connect",
+				trace.WithAttributes(attribute.String("network.peer.address", addr)))
+		},
+		ConnectDone: func(network, addr string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if span, ok := dials[network+" "+addr]; ok {
+				endSpan(span, err)
+				delete(dials, network+" "+addr)
+			}
+		},
+		TLSHandshakeStart: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			_, tlsHS = tracer.Start(ctx, "tls")
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if tlsHS != nil {
+				tlsHS.SetAttributes(attribute.String("tls.protocol.version", tls.VersionName(state.Version)))
+				endSpan(tlsHS, err)
+				tlsHS = nil
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("http.connection.reused", info.Reused))
+		},
+		GotFirstResponseByte: func() {
+			trace.SpanFromContext(ctx).AddEvent("first byte")
+		},
+	}
+	return httptrace.WithClientTrace(ctx, ct)
+}
+
+// tracedDB wraps the connection the generated queries run on with a span
+// per query, named after the query's "-- name:" line.
+type tracedDB struct {
+	next database.DBTX
+}
+
+func (t tracedDB) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		// a query outside any traced operation isn't worth a root span
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return tracer.Start(ctx, "db "+queryName(query), trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL))
+}
+
+func (t tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := t.start(ctx, query)
+	res, err := t.next.ExecContext(ctx, query, args...)
+	endSpan(span, err)
+	return res, err
+}
+
+func (t tracedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := t.start(ctx, query)
+	stmt, err := t.next.PrepareContext(ctx, query)
+	endSpan(span, err)
+	return stmt, err
+}
+
+func (t tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := t.start(ctx, query)
+	rows, err := t.next.QueryContext(ctx, query, args...)
+	endSpan(span, err)
+	return rows, err
+}
+
+func (t tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := t.start(ctx, query)
+	row := t.next.QueryRowContext(ctx, query, args...)
+	endSpan(span, row.Err())
+	return row
+}
+
+// queryName pulls GetFeeds out of sqlc's "-- name: GetFeeds :many" header.
+func queryName(query string) string {
+	line, _, _ := strings.Cut(query, "\n")
+	if name, ok := strings.CutPrefix(line, "-- name: "); ok {
+		if name, _, ok := strings.Cut(name, " "); ok {
+			return name
+		}
+	}
+	return "query"
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+const defaultTrendingLimit = 20
+
+// trendingStore is what trending needs of the database beyond the current
+// user.
+type trendingStore interface {
+	GetTrendingPosts(ctx context.Context, arg database.GetTrendingPostsParams) ([]database.GetTrendingPostsRow, error)
+}
+
+// handlerTrending ranks recent posts from every feed by how many users
+// starred or read them, newer posts counting for more, to show what the
+// instance as a whole found interesting.
+func handlerTrending(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet("trending", flag.ContinueOnError)
+	since := fs.String("since", "7d", "only rank posts published within this long, e.g. 2d or 36h")
+	limit := fs.Int("limit", defaultTrendingLimit, "maximum number of posts to show")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("trending takes no arguments")
+	}
+	if *limit <= 0 {
+		return usageErrorf("limit must be a positive number")
+	}
+	age, err := parseAge(*since)
+	if err != nil {
+		return usageError(err)
+	}
+
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	posts, err := trendingPosts(ctx, s.db, age, *limit)
+	if err != nil {
+		return err
+	}
+	if len(posts) == 0 {
+		fmt.Printf("No posts read or starred in the last %s\n", *since)
+		return nil
+	}
+
+	// as printStories does, with each post's counts under it
+	loc := userLocation(user)
+	ids := make([]uuid.UUID, 0, len(posts))
+	for i, row := range posts {
+		printPost(i+1, row.Post, row.FeedName, loc, false)
+		fmt.Printf("  %s, %s\n", plural(row.StarredBy, "star"), plural(row.ReadBy, "read"))
+		ids = append(ids, row.Post.ID)
+	}
+	if err := saveListing(s, ids); err != nil {
+		fmt.Printf("Error saving listing: %v\n", err)
+	}
+	return nil
+}
+
+// trendingPosts returns up to limit of the posts published within age,
+// most starred and read first.
+func trendingPosts(ctx context.Context, db trendingStore, age time.Duration, limit int) ([]database.GetTrendingPostsRow, error) {
+	posts, err := db.GetTrendingPosts(ctx, database.GetTrendingPostsParams{
+		Since:      time.Now().Add(-age),
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending posts: %v", err)
+	}
+	return posts, nil
+}
+
+// plural formats a count with its noun, e.g. "1 star" or "3 stars".
+func plural(n int64, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+func TestHandlerTrending(t *testing.T) {
+	first := database.Post{ID: uuid.New(), Title: "First", Url: "https://example.com/1"}
+	second := database.Post{ID: uuid.New(), Title: "Second", Url: "https://example.com/2"}
+	db := &fakeStore{
+		users: []database.User{{ID: uuid.New(), Name: "ann"}},
+		trending: []database.GetTrendingPostsRow{
+			{Post: first, FeedName: "Example", StarredBy: 2, ReadBy: 5},
+			{Post: second, FeedName: "Example", ReadBy: 1},
+		},
+	}
+	s := testState(t, db)
+
+	before := time.Now()
+	if err := handlerTrending(context.Background(), s, command{Name: "trending", Args: []string{"--since", "2d", "--limit", "5"}}); err != nil {
+		t.Fatalf("trending: %v", err)
+	}
+	after := time.Now()
+
+	if db.asked.MaxResults != 5 {
+		t.Errorf("asked for %d posts, want 5", db.asked.MaxResults)
+	}
+	if since := db.asked.Since; since.Before(before.Add(-48*time.Hour)) || since.After(after.Add(-48*time.Hour)) {
+		t.Errorf("asked for posts since %v, want 48h before %v", since, before)
+	}
+
+	listing, err := os.ReadFile(filepath.Join(s.Config.DataDir, "last_listing"))
+	if err != nil {
+		t.Fatalf("reading listing: %v", err)
+	}
+	if got, want := strings.Fields(string(listing)), []string{first.ID.String(), second.ID.String()}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("listing is %v, want %v", got, want)
+	}
+}
+
+func TestHandlerTrendingUsage(t *testing.T) {
+	// the flags are checked before the database is touched, so a Store
+	// with nothing behind it is enough
+	s := testState(t, &fakeStore{})
+
+	for _, args := range [][]string{{"--limit", "0"}, {"--since", "soon"}, {"extra"}} {
+		err := handlerTrending(context.Background(), s, command{Name: "trending", Args: args})
+		var exitErr *exitCodeError
+		if !errors.As(err, &exitErr) || exitErr.code != exitUsage {
+			t.Errorf("trending %v: got %v, want a usage error", args, err)
+		}
+	}
+}
+
+func TestHandlerTrendingUnknownUser(t *testing.T) {
+	s := testState(t, &fakeStore{})
+
+	err := handlerTrending(context.Background(), s, command{Name: "trending"})
+	var exitErr *exitCodeError
+	if !errors.As(err, &exitErr) || exitErr.code != exitNotFound {
+		t.Errorf("got %v, want a not found error", err)
+	}
+}
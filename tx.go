@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// withTx runs fn with a copy of s whose queries go through a single
+// transaction, committing when fn succeeds and rolling back otherwise.
+func (s *state) withTx(ctx context.Context, fn func(tx *state) error) error {
+	return s.db.InTx(ctx, func(db Store) error {
+		txState := *s
+		txState.db = db
+		return fn(&txState)
+	})
+}
+
+// uniqueViolation returns the name of the unique constraint err violated,
+// or "" when err isn't a unique violation.
+func uniqueViolation(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return pqErr.Constraint
+	}
+	return ""
+}
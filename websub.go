@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/necodeus/gator/internal/database"
+)
+
+const (
+	// webSubLeaseSeconds is the lease asked of hubs; they may grant less.
+	webSubLeaseSeconds = 10 * 24 * 60 * 60
+	// webSubRenewMargin is how long before a lease runs out it is renewed.
+	webSubRenewMargin = 24 * time.Hour
+	// webSubRetryAfter is how long a request the hub never verified is
+	// left before asking again.
+	webSubRetryAfter = time.Hour
+	// webSubCheckInterval is how often serve looks for feeds to subscribe.
+	webSubCheckInterval = 10 * time.Minute
+	// maxWebSubBody caps a pushed update.
+	maxWebSubBody = 10 << 20
+)
+
+// webSubLinks returns the hub a feed announces and the topic URL to
+// subscribe to it under, which is its rel="self" link when it has one.
+func webSubLinks(rss *RSSFeed, feedURL string) (hub, topic string) {
+	topic = feedURL
+	for _, link := range rss.Channel.AtomLinks {
+		switch strings.ToLower(link.Rel) {
+		case "hub":
+			if hub == "" {
+				hub = link.Href
+			}
+		case "self":
+			if link.Href != "" {
+				topic = link.Href
+			}
+		}
+	}
+	if hub == "" {
+		return "", ""
+	}
+	return hub, topic
+}
+
+// saveWebSubLinks records the hub a fetched feed announces, so serve can
+// subscribe to it.
+func saveWebSubLinks(ctx context.Context, s *state, feed database.Feed, rss *RSSFeed) error {
+	hub, topic := webSubLinks(rss, feed.Url)
+	if hub == feed.WebsubHub.String && topic == feed.WebsubTopic.String {
+		return nil
+	}
+
+	return s.db.UpdateFeedWebSub(ctx, database.UpdateFeedWebSubParams{
+		ID:          feed.ID,
+		WebsubHub:   sql.NullString{String: hub, Valid: hub != ""},
+		WebsubTopic: sql.NullString{String: topic, Valid: topic != ""},
+	})
+}
+
+// runWebSubSubscriber keeps gator subscribed to the hub of every feed
+// that has one until ctx is cancelled, renewing leases before they end.
+// Hubs call back on publicURL, which must reach this server.
+func runWebSubSubscriber(ctx context.Context, s *state, publicURL string) {
+	ticker := time.NewTicker(webSubCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := subscribeToHubs(ctx, s, publicURL); err != nil {
+			fmt.Printf("Error subscribing to WebSub hubs: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func subscribeToHubs(ctx context.Context, s *state, publicURL string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	now := time.Now().UTC()
+	feeds, err := s.db.GetWebSubCandidates(dbCtx, database.GetWebSubCandidatesParams{
+		RenewBefore: now.Add(webSubRenewMargin),
+		RetryBefore: now.Add(-webSubRetryAfter),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get feeds: %v", err)
+	}
+
+	for _, feed := range feeds {
+		// renewals keep their secret so pushes in flight still verify
+		secret := feed.Secret.String
+		if !feed.Secret.Valid {
+			if secret, err = newWebSubSecret(); err != nil {
+				return err
+			}
+		}
+
+		if err := s.db.UpsertWebSubSubscription(dbCtx, database.UpsertWebSubSubscriptionParams{
+			FeedID:      feed.ID,
+			Hub:         feed.WebsubHub.String,
+			Topic:       feed.WebsubTopic.String,
+			Secret:      secret,
+			RequestedAt: now,
+		}); err != nil {
+			return fmt.Errorf("failed to save subscription: %v", err)
+		}
+
+		callback := strings.TrimRight(publicURL, "/") + "/websub/" + feed.ID.String()
+		if err := requestWebSubSubscription(ctx, s, feed.WebsubHub.String, feed.WebsubTopic.String, callback, secret); err != nil {
+			fmt.Printf("Error subscribing to %s: %v\n", feed.Name, err)
+			continue
+		}
+		infof("Requested WebSub subscription for %s from %s\n", feed.Name, feed.WebsubHub.String)
+	}
+
+	return nil
+}
+
+func newWebSubSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// requestWebSubSubscription asks hub to start (or renew) pushing topic to
+// callback. The hub confirms later by calling the callback with GET.
+func requestWebSubSubscription(ctx context.Context, s *state, hub, topic, callback, secret string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.fetchTimeout)
+	defer cancel()
+
+	form := url.Values{
+		"hub.mode":          {"subscribe"},
+		"hub.topic":         {topic},
+		"hub.callback":      {callback},
+		"hub.secret":        {secret},
+		"hub.lease_seconds": {strconv.Itoa(webSubLeaseSeconds)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", s.userAgent(nil))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("hub answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// registerWebSubHandlers adds the callback hubs verify subscriptions on
+// (GET) and push updates to (POST).
+func registerWebSubHandlers(mux *http.ServeMux, s *state) {
+	mux.HandleFunc("GET /websub/{id}", func(w http.ResponseWriter, r *http.Request) {
+		verifyWebSub(w, r, s)
+	})
+	mux.HandleFunc("POST /websub/{id}", func(w http.ResponseWriter, r *http.Request) {
+		receiveWebSub(w, r, s)
+	})
+}
+
+func verifyWebSub(w http.ResponseWriter, r *http.Request, s *state) {
+	feedID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.dbTimeout)
+	defer cancel()
+
+	query := r.URL.Query()
+	sub, err := s.db.GetWebSubSubscription(ctx, feedID)
+	if err != nil && err != sql.ErrNoRows {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	wanted := err == nil
+
+	switch query.Get("hub.mode") {
+	case "subscribe":
+		if !wanted || query.Get("hub.topic") != sub.Topic {
+			http.NotFound(w, r)
+			return
+		}
+		lease, err := strconv.Atoi(query.Get("hub.lease_seconds"))
+		if err != nil || lease <= 0 {
+			lease = webSubLeaseSeconds
+		}
+		if err := s.db.SetWebSubLease(ctx, database.SetWebSubLeaseParams{
+			FeedID:         feedID,
+			LeaseExpiresAt: sql.NullTime{Time: time.Now().UTC().Add(time.Duration(lease) * time.Second), Valid: true},
+		}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		infof("WebSub subscription for %s verified for %s\n", sub.Topic, time.Duration(lease)*time.Second)
+
+	case "unsubscribe":
+		// only confirm unsubscribing from something no longer wanted
+		if wanted {
+			http.NotFound(w, r)
+			return
+		}
+
+	case "denied":
+		if wanted {
+			if err := s.db.DeleteWebSubSubscription(ctx, feedID); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		infof("WebSub hub denied subscription to %s: %s\n", query.Get("hub.topic"), query.Get("hub.reason"))
+		w.WriteHeader(http.StatusOK)
+		return
+
+	default:
+		http.Error(w, "unknown hub.mode", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	io.WriteString(w, query.Get("hub.challenge"))
+}
+
+// receiveWebSub stores the posts a hub pushes. Hubs that only ping, or
+// push a format the parser can't read, trigger an immediate fetch instead.
+func receiveWebSub(w http.ResponseWriter, r *http.Request, s *state) {
+	feedID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.dbTimeout)
+	defer cancel()
+
+	sub, err := s.db.GetWebSubSubscription(ctx, feedID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebSubBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the spec asks for invalid pushes to be acknowledged but ignored
+	if !validWebSubSignature(sub.Secret, r.Header.Get("X-Hub-Signature"), body) {
+		fmt.Printf("Ignoring WebSub push for %s with a bad signature\n", sub.Topic)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	feed, err := s.db.GetFeedById(ctx, feedID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if rss, err := parseFeed(bytes.NewReader(body), s.maxItems); err == nil && len(rss.Channel.Item) > 0 {
+		newPosts := savePosts(ctx, s, feed, rss.Channel.Item)
+		infof("WebSub push for %s: %d new posts\n", feed.Name, newPosts)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// the fetch outlives the request, so it mustn't end with it
+	fetchCtx := context.WithoutCancel(r.Context())
+	go func() {
+		if err := s.hosts.Wait(fetchCtx, feed.Url); err != nil {
+			return
+		}
+		newPosts, err := aggregateFeed(fetchCtx, s, feed, aggOptions{})
+		if err != nil {
+			fmt.Printf("Error fetching %s after WebSub push: %v\n", feed.Name, err)
+			return
+		}
+		infof("WebSub ping for %s: %d new posts\n", feed.Name, newPosts)
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// validWebSubSignature checks an X-Hub-Signature header, "<algo>=<hex>",
+// against an HMAC of body keyed with the subscription's secret.
+func validWebSubSignature(secret, header string, body []byte) bool {
+	algo, signature, ok := strings.Cut(header, "=")
+	if !ok {
+		return false
+	}
+
+	var newHash func() hash.Hash
+	switch strings.ToLower(algo) {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return false
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/necodeus/gator/internal/database"
+)
+
+func handlerWhatsNew(ctx context.Context, s *state, cmd command) error {
+	user, err := currentUser(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	loc := userLocation(user)
+
+	// a user who has never browsed gets the last day
+	posts, err := s.db.GetPostsForUserSince(ctx, database.GetPostsForUserSinceParams{
+		UserID: user.ID,
+		Since:  user.LastSeenAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get posts: %v", err)
+	}
+
+	if user.LastSeenAt.Valid {
+		fmt.Printf("%d new posts since %s\n", len(posts), user.LastSeenAt.Time.In(loc).Format("Mon Jan 2 2006 15:04 MST"))
+	} else {
+		fmt.Printf("%d new posts in the last day\n", len(posts))
+	}
+
+	rows := make([]database.GetPostsForUserRow, 0, len(posts))
+	for _, post := range posts {
+		rows = append(rows, database.GetPostsForUserRow(post))
+	}
+
+	printStories(s, collapseDuplicates(rows), loc, false)
+
+	return markSeen(ctx, s, user)
+}
+
+// markSeen records that user has just looked at their posts, so the next
+// whatsnew starts from here.
+func markSeen(ctx context.Context, s *state, user database.User) error {
+	if err := s.db.MarkUserSeen(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to update last seen time: %v", err)
+	}
+	return nil
+}
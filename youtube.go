@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const youtubeFeedBase = "https://www.youtube.com/feeds/videos.xml"
+
+var youtubeHosts = map[string]bool{
+	"youtube.com":       true,
+	"www.youtube.com":   true,
+	"m.youtube.com":     true,
+	"music.youtube.com": true,
+}
+
+// Where a channel page gives away its ID, most reliable first.
+var youtubeChannelIDPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`<link rel="canonical" href="https://www\.youtube\.com/channel/(UC[\w-]{22})"`),
+	regexp.MustCompile(`"externalId":"(UC[\w-]{22})"`),
+	regexp.MustCompile(`<meta itemprop="(?:identifier|channelId)" content="(UC[\w-]{22})"`),
+}
+
+var youtubeChannelID = regexp.MustCompile(`^UC[\w-]{22}$`)
+
+// youtubeFeedURL turns a YouTube channel, user, handle or playlist URL
+// into the URL of its feed. Handles and custom /c/ URLs don't contain the
+// channel ID, so their page is fetched to find it. ok is false for URLs
+// that aren't YouTube pages with a feed, which are left to the caller.
+func youtubeFeedURL(ctx context.Context, s *state, raw string) (feedURL string, ok bool, err error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || !youtubeHosts[strings.ToLower(u.Hostname())] {
+		return "", false, nil
+	}
+
+	// a playlist wins over the video it was opened on
+	if list := u.Query().Get("list"); list != "" {
+		return youtubeFeedBase + "?playlist_id=" + url.QueryEscape(list), true, nil
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case len(segments) >= 2 && segments[0] == "channel" && youtubeChannelID.MatchString(segments[1]):
+		return youtubeFeedBase + "?channel_id=" + segments[1], true, nil
+	case len(segments) >= 2 && segments[0] == "user":
+		return youtubeFeedBase + "?user=" + url.QueryEscape(segments[1]), true, nil
+	case strings.HasPrefix(segments[0], "@"):
+		return youtubeFeedURLFromPage(ctx, s, "https://www.youtube.com/"+segments[0])
+	case len(segments) >= 2 && segments[0] == "c":
+		return youtubeFeedURLFromPage(ctx, s, "https://www.youtube.com/c/"+segments[1])
+	}
+
+	return "", false, nil
+}
+
+// youtubeFeedURLFromPage fetches a channel page to find the channel ID
+// its feed is keyed on.
+func youtubeFeedURLFromPage(ctx context.Context, s *state, pageURL string) (string, bool, error) {
+	page, _, err := fetchForArchive(ctx, s, pageURL)
+	if err != nil {
+		return "", true, fmt.Errorf("failed to load %s: %v", pageURL, err)
+	}
+
+	for _, pattern := range youtubeChannelIDPatterns {
+		if match := pattern.FindSubmatch(page); match != nil {
+			return youtubeFeedBase + "?channel_id=" + string(match[1]), true, nil
+		}
+	}
+
+	return "", true, fmt.Errorf("no channel ID found on %s", pageURL)
+}
+
+func youtubeWatchURL(videoID string) string {
+	return "https://www.youtube.com/watch?v=" + url.QueryEscape(videoID)
+}
+
+// youtubeThumbnailURL is the image YouTube serves for every video, for
+// entries that come without a media:thumbnail.
+func youtubeThumbnailURL(videoID string) string {
+	return "https://i.ytimg.com/vi/" + url.PathEscape(videoID) + "/hqdefault.jpg"
+}